@@ -5,15 +5,26 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/accruals"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/fxrates"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/statements"
 	"github.com/rudineirk/pismo-challenge/pkg/domains/transactions"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/webhooks"
 	"github.com/rudineirk/pismo-challenge/pkg/infra/config"
 	"github.com/rudineirk/pismo-challenge/pkg/infra/database"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/encryption"
 	"github.com/rudineirk/pismo-challenge/pkg/infra/httprouter"
 	"github.com/rudineirk/pismo-challenge/pkg/infra/httprouter/healthcheck"
 	"github.com/rudineirk/pismo-challenge/pkg/infra/logger"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/outbox"
 	"github.com/rudineirk/pismo-challenge/pkg/infra/signalhandler"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/tracing"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 func main() {
@@ -24,6 +35,11 @@ func main() {
 		logger.Fatal().Err(err).Msg("Failed to load env config")
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TracingExporter)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to setup tracing")
+	}
+
 	sqlDB, bunDB, err := database.NewDatabase(cfg.DatabaseURL)
 	if err != nil {
 		logger.Fatal().Err(err).Msg("Failed to connect to database")
@@ -35,25 +51,134 @@ func main() {
 		logger.Fatal().Err(err).Msg("Failed to run database migrations")
 	}
 
+	keyring, err := encryption.LoadKeyring(
+		cfg.EncryptionKeysFile,
+		cfg.EncryptionActiveKeyID,
+		cfg.EncryptionKeys,
+		cfg.EncryptionIndexKey,
+	)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to load encryption keys")
+	}
+
 	sighandler := signalhandler.NewSignalHandler(logger)
 	router := httprouter.NewRouter(logger, cfg.IsProduction)
 	httpserver := httprouter.NewServer(cfg.HTTPPort, router)
 
 	healthcheck.SetupHealthCheck(router, bunDB)
 
+	outboxStore := outbox.NewStore(bunDB)
+	outboxPublisher, err := outbox.NewPublisher(cfg.OutboxPublisher, cfg.OutboxFilePath, cfg.OutboxHTTPURL)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to setup outbox publisher")
+	}
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	workersWG := sync.WaitGroup{}
+
 	go sighandler.Listen(func(ctx context.Context) {
+		stopWorkers()
+
 		if err := httpserver.Shutdown(ctx); err != nil {
 			logger.Err(err).Msg("Server shutdown error")
 		}
 	})
 
-	accountsRepo := accounts.NewRepository(bunDB)
-	accountsSvc := accounts.NewService(accountsRepo)
-	accounts.SetupHTTPRoutes(router, accountsSvc)
+	idempotency := httprouter.Idempotency(bunDB)
+
+	opTypesRepo := operationtypes.NewRepository(bunDB)
+	opTypesSvc := operationtypes.NewService(opTypesRepo)
+	if err = opTypesSvc.LoadOperationTypes(context.Background()); err != nil {
+		logger.Fatal().Err(err).Msg("Failed to load operation types")
+	}
+
+	operationtypes.SetupHTTPRoutes(router, opTypesSvc, idempotency)
+
+	accountsRepo := accounts.NewRepository(bunDB, keyring)
+	accountsSvc := accounts.NewTracingService(accounts.NewService(
+		accountsRepo,
+		outboxStore,
+		money.New(cfg.AccountDefaultCreditLimit),
+	))
+	accounts.SetupHTTPRoutes(router, accountsSvc, idempotency)
+
+	fxRatesRepo := fxrates.NewRepository(bunDB)
+	fxRatesSvc := fxrates.NewService(fxRatesRepo)
+	fxrates.SetupHTTPRoutes(router, fxRatesSvc, idempotency)
 
 	transactionsRepo := transactions.NewRepository(bunDB)
-	transactionsSvc := transactions.NewService(transactionsRepo, accountsSvc)
-	transactions.SetupHTTPRoutes(router, transactionsSvc)
+	transactionsSvc := transactions.NewTracingService(transactions.NewService(
+		transactionsRepo,
+		accountsSvc,
+		opTypesSvc,
+		fxRatesSvc,
+		outboxStore,
+	))
+	transactions.SetupHTTPRoutes(router, transactionsSvc, idempotency)
+
+	webhooksRepo := webhooks.NewRepository(bunDB)
+	webhooksSvc := webhooks.NewService(
+		webhooksRepo,
+		accountsSvc,
+		&http.Client{Timeout: cfg.WebhookTimeout, Transport: otelhttp.NewTransport(http.DefaultTransport)},
+		webhooks.RetryPolicy{
+			MaxAttempts: cfg.WebhookMaxAttempts,
+			BaseBackoff: cfg.WebhookBaseBackoff,
+			MaxBackoff:  cfg.WebhookMaxBackoff,
+		},
+	)
+	webhooks.SetupHTTPRoutes(router, webhooksSvc, idempotency)
+
+	statementsRepo := statements.NewRepository(bunDB)
+	statementsSvc := statements.NewService(statementsRepo, accountsSvc)
+	statements.SetupHTTPRoutes(router, statementsSvc, idempotency)
+
+	accrualsRepo := accruals.NewRepository(bunDB)
+	accrualsSvc := accruals.NewService(
+		accrualsRepo,
+		accountsSvc,
+		statementsSvc,
+		transactionsSvc,
+		accruals.Policy{
+			MonthlyInterestRate: cfg.AccrualMonthlyInterestRate,
+			LateFee:             money.New(cfg.AccrualLateFee),
+			PenaltyRate:         cfg.AccrualPenaltyRate,
+		},
+	)
+	accruals.SetupHTTPRoutes(router, accrualsSvc, idempotency)
+
+	publishers := []outbox.Publisher{webhooks.NewEventPublisher(webhooksSvc)}
+	if outboxPublisher != nil {
+		publishers = append(publishers, outboxPublisher)
+	}
+
+	relay := outbox.NewRelay(outboxStore, outbox.NewMultiPublisher(publishers...), logger, cfg.OutboxPollInterval)
+	dispatcher := webhooks.NewDispatcher(webhooksSvc, logger, cfg.WebhookPollInterval)
+	statementsJob := statements.NewJob(statementsSvc, logger, cfg.StatementsJobInterval)
+	accrualJob := accruals.NewJob(accrualsSvc, logger, cfg.AccrualJobInterval, cfg.AccrualDryRun)
+	opTypesJob := operationtypes.NewRefreshJob(opTypesSvc, logger, cfg.OperationTypesRefresh)
+
+	workersWG.Add(5)
+	go func() {
+		defer workersWG.Done()
+		relay.Run(workersCtx)
+	}()
+	go func() {
+		defer workersWG.Done()
+		dispatcher.Run(workersCtx)
+	}()
+	go func() {
+		defer workersWG.Done()
+		statementsJob.Run(workersCtx)
+	}()
+	go func() {
+		defer workersWG.Done()
+		accrualJob.Run(workersCtx)
+	}()
+	go func() {
+		defer workersWG.Done()
+		opTypesJob.Run(workersCtx)
+	}()
 
 	logger.Info().Msg(fmt.Sprintf("Starting server on http://localhost%s", httpserver.Addr))
 
@@ -61,5 +186,17 @@ func main() {
 		logger.Fatal().Err(err).Msg("Server forced to shutdown")
 	}
 
+	workersWG.Wait()
+
+	if outboxPublisher != nil {
+		if err := outboxPublisher.Close(); err != nil {
+			logger.Err(err).Msg("Failed to close outbox publisher")
+		}
+	}
+
+	if err := shutdownTracing(context.Background()); err != nil {
+		logger.Err(err).Msg("Failed to flush the tracing spans")
+	}
+
 	logger.Info().Msg("Server stopped")
 }
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/config"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/database"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/encryption"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/logger"
+)
+
+// Re-encrypts the accounts document numbers with the active encryption key, it must be run after
+// rotating the keys and after deploying the document numbers encryption, to encrypt the old rows.
+func main() {
+	batchSize := flag.Int("batch-size", 500, "accounts updated in each database transaction")
+	all := flag.Bool("all", false, "rewrite all the accounts, required after changing the blind index key")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	logger := logger.NewLogger(cfg.LogFormat, cfg.LogLevel)
+
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to load env config")
+	}
+
+	sqlDB, bunDB, err := database.NewDatabase(cfg.DatabaseURL)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+
+	defer sqlDB.Close()
+
+	keyring, err := encryption.LoadKeyring(
+		cfg.EncryptionKeysFile,
+		cfg.EncryptionActiveKeyID,
+		cfg.EncryptionKeys,
+		cfg.EncryptionIndexKey,
+	)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to load encryption keys")
+	}
+
+	accountsRepo := accounts.NewRepository(bunDB, keyring)
+
+	count, err := accountsRepo.ReencryptDocumentNumbers(context.Background(), *batchSize, *all)
+	if err != nil {
+		logger.Fatal().Err(err).Int("accounts", count).Msg("Failed to re-encrypt the document numbers")
+	}
+
+	logger.Info().
+		Int("accounts", count).
+		Str("key_id", keyring.ActiveKeyID()).
+		Msg("Document numbers re-encrypted")
+}
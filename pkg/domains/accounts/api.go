@@ -1,85 +1,343 @@
 package accounts
 
 import (
-	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/httprouter"
 	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
 )
 
 type httpHandler struct {
 	service Service
 }
 
-func SetupHTTPRoutes(router *gin.Engine, service Service) {
+func SetupHTTPRoutes(router *gin.Engine, service Service, middlewares ...gin.HandlerFunc) {
 	handler := httpHandler{
 		service: service,
 	}
 
-	routeGroup := router.Group("/accounts")
+	routeGroup := router.Group("/accounts", middlewares...)
 	routeGroup.POST("", handler.CreateAccount)
+	routeGroup.GET("", handler.SearchAccounts)
 	routeGroup.GET("/:account_id", handler.GetAccountByID)
+	routeGroup.PATCH("/:account_id", handler.UpdateAccount)
+	routeGroup.GET("/:account_id/balance", handler.GetAccountBalance)
+	routeGroup.PUT("/:account_id/credit-limit", handler.UpdateAvailableCreditLimit)
+	routeGroup.POST("/:account_id/block", handler.ChangeStatus(BlockedStatus))
+	routeGroup.POST("/:account_id/unblock", handler.ChangeStatus(ActiveStatus))
+	routeGroup.POST("/:account_id/suspend", handler.ChangeStatus(SuspendedStatus))
+	routeGroup.POST("/:account_id/close", handler.ChangeStatus(ClosedStatus))
+	routeGroup.GET("/:account_id/status-transitions", handler.ListStatusTransitions)
 }
 
 func (handler *httpHandler) CreateAccount(ctx *gin.Context) {
 	req := CreateAccountRequest{}
-	if err := ctx.BindJSON(&req); err != nil {
+	if err := httprouter.BindJSON(ctx, &req); err != nil {
 		return
 	}
 
 	account, err := handler.service.CreateAccount(ctx, &req)
 
 	if err != nil {
-		isBadRequest := errors.Is(err, ErrInvalidDocumentNumber(nil)) ||
-			errors.Is(err, errorlib.ErrInvalidPayload(nil))
-
-		if isBadRequest {
-			ctx.JSON(http.StatusBadRequest, err)
-		} else if errors.Is(err, errorlib.ErrDuplicated(nil)) {
-			ctx.JSON(http.StatusConflict, err)
-		} else {
-			_ = ctx.AbortWithError(http.StatusInternalServerError, err)
-		}
-
+		httprouter.AbortWithError(ctx, err)
 		return
 	}
 
 	ctx.JSON(http.StatusCreated, NewAPIResponseFromEntity(account))
 }
 
+func (handler *httpHandler) SearchAccounts(ctx *gin.Context) {
+	req := SearchAccountsRequest{}
+	if err := httprouter.BindQuery(ctx, &req); err != nil {
+		return
+	}
+
+	page, err := handler.service.SearchAccounts(ctx, &req)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, NewAccountsPageAPIResponseFromEntity(page))
+}
+
 func (handler *httpHandler) GetAccountByID(ctx *gin.Context) {
 	accountIDRaw := ctx.Param("account_id")
 
 	accountID, err := strconv.ParseInt(accountIDRaw, 10, 64)
 	if err != nil {
-		ctx.Status(http.StatusNotFound)
+		httprouter.AbortWithError(ctx, errorlib.ErrNotFound(err))
 		return
 	}
 
 	account, err := handler.service.GetAccountByID(ctx, accountID)
 	if err != nil {
-		if errors.Is(err, errorlib.ErrNotFound(nil)) {
-			ctx.Status(http.StatusNotFound)
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, NewAPIResponseFromEntity(account))
+}
+
+func (handler *httpHandler) GetAccountBalance(ctx *gin.Context) {
+	accountIDRaw := ctx.Param("account_id")
+
+	accountID, err := strconv.ParseInt(accountIDRaw, 10, 64)
+	if err != nil {
+		httprouter.AbortWithError(ctx, errorlib.ErrNotFound(err))
+		return
+	}
+
+	balance, err := handler.service.GetAccountBalance(ctx, accountID)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, NewBalanceAPIResponseFromEntity(balance))
+}
+
+func (handler *httpHandler) UpdateAvailableCreditLimit(ctx *gin.Context) {
+	accountIDRaw := ctx.Param("account_id")
+
+	accountID, err := strconv.ParseInt(accountIDRaw, 10, 64)
+	if err != nil {
+		httprouter.AbortWithError(ctx, errorlib.ErrNotFound(err))
+		return
+	}
+
+	req := UpdateCreditLimitRequest{}
+	if err := httprouter.BindJSON(ctx, &req); err != nil {
+		return
+	}
+
+	req.AccountID = accountID
+
+	account, err := handler.service.UpdateAvailableCreditLimit(ctx, &req)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, NewAPIResponseFromEntity(account))
+}
+
+func (handler *httpHandler) UpdateAccount(ctx *gin.Context) {
+	accountID, err := strconv.ParseInt(ctx.Param("account_id"), 10, 64)
+	if err != nil {
+		httprouter.AbortWithError(ctx, errorlib.ErrNotFound(err))
+		return
+	}
+
+	req := UpdateAccountRequest{}
+	if err := httprouter.BindJSON(ctx, &req); err != nil {
+		return
+	}
+
+	req.AccountID = accountID
+
+	account, err := handler.service.UpdateAccount(ctx, &req)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, NewAPIResponseFromEntity(account))
+}
+
+func (handler *httpHandler) ChangeStatus(status Status) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountID, err := strconv.ParseInt(ctx.Param("account_id"), 10, 64)
+		if err != nil {
+			httprouter.AbortWithError(ctx, errorlib.ErrNotFound(err))
 			return
-		} else {
-			_ = ctx.AbortWithError(http.StatusInternalServerError, err)
+		}
+
+		req := ChangeStatusRequest{}
+		if err := httprouter.BindJSON(ctx, &req); err != nil {
 			return
 		}
+
+		req.AccountID = accountID
+		req.Status = status
+
+		account, err := handler.service.ChangeStatus(ctx, &req)
+		if err != nil {
+			httprouter.AbortWithError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, NewAPIResponseFromEntity(account))
 	}
+}
 
-	ctx.JSON(http.StatusOK, NewAPIResponseFromEntity(account))
+func (handler *httpHandler) ListStatusTransitions(ctx *gin.Context) {
+	accountID, err := strconv.ParseInt(ctx.Param("account_id"), 10, 64)
+	if err != nil {
+		httprouter.AbortWithError(ctx, errorlib.ErrNotFound(err))
+		return
+	}
+
+	transitions, err := handler.service.ListStatusTransitions(ctx, accountID)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	resp := make([]*StatusTransitionAPIResponse, 0, len(transitions))
+	for _, transition := range transitions {
+		resp = append(resp, NewStatusTransitionAPIResponseFromEntity(transition))
+	}
+
+	ctx.JSON(http.StatusOK, resp)
 }
 
 type AccountAPIResponse struct {
-	AccountID      int64  `json:"account_id"`
-	DocumentNumber string `json:"document_number"`
+	AccountID            int64        `json:"account_id"`
+	DocumentNumber       string       `json:"document_number"`
+	DocumentType         DocumentType `json:"document_type"`
+	HolderName           string       `json:"holder_name"`
+	Email                string       `json:"email"`
+	Phone                string       `json:"phone"`
+	BirthDate            string       `json:"birth_date,omitempty"`
+	IncorporationDate    string       `json:"incorporation_date,omitempty"`
+	AvailableCreditLimit money.Money  `json:"available_credit_limit"`
+	Currency             string       `json:"currency"`
+	ClosingDay           int          `json:"closing_day"`
+	DueDay               int          `json:"due_day"`
+	Status               Status       `json:"status"`
+	CreatedAt            time.Time    `json:"created_at"`
+	UpdatedAt            time.Time    `json:"updated_at"`
 }
 
 func NewAPIResponseFromEntity(account *Account) *AccountAPIResponse {
 	return &AccountAPIResponse{
-		AccountID:      account.ID,
-		DocumentNumber: account.DocumentNumber,
+		AccountID:            account.ID,
+		DocumentNumber:       account.DocumentNumber,
+		DocumentType:         account.DocumentType,
+		HolderName:           account.HolderName,
+		Email:                account.Email,
+		Phone:                account.Phone,
+		BirthDate:            formatDate(account.BirthDate),
+		IncorporationDate:    formatDate(account.IncorporationDate),
+		AvailableCreditLimit: account.AvailableCreditLimit,
+		Currency:             account.Currency,
+		ClosingDay:           account.ClosingDay,
+		DueDay:               account.DueDay,
+		Status:               account.Status,
+		CreatedAt:            account.CreatedAt,
+		UpdatedAt:            account.UpdatedAt,
+	}
+}
+
+// AccountCreatedEventPayload is the payload of the AccountCreated event, it's stored in the outbox and
+// sent to the webhooks, so it doesn't have the document number and the holder's personal data.
+type AccountCreatedEventPayload struct {
+	AccountID            int64        `json:"account_id"`
+	DocumentType         DocumentType `json:"document_type"`
+	AvailableCreditLimit money.Money  `json:"available_credit_limit"`
+	Currency             string       `json:"currency"`
+	ClosingDay           int          `json:"closing_day"`
+	DueDay               int          `json:"due_day"`
+	Status               Status       `json:"status"`
+	CreatedAt            time.Time    `json:"created_at"`
+}
+
+func NewAccountCreatedEventPayload(account *Account) *AccountCreatedEventPayload {
+	return &AccountCreatedEventPayload{
+		AccountID:            account.ID,
+		DocumentType:         account.DocumentType,
+		AvailableCreditLimit: account.AvailableCreditLimit,
+		Currency:             account.Currency,
+		ClosingDay:           account.ClosingDay,
+		DueDay:               account.DueDay,
+		Status:               account.Status,
+		CreatedAt:            account.CreatedAt,
 	}
 }
+
+func formatDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+
+	return date.Format(dateLayout)
+}
+
+type AccountsPageAPIResponse struct {
+	Items      []*AccountAPIResponse `json:"items"`
+	NextCursor *string               `json:"next_cursor"`
+}
+
+func NewAccountsPageAPIResponseFromEntity(page *AccountsPage) *AccountsPageAPIResponse {
+	resp := &AccountsPageAPIResponse{
+		Items: make([]*AccountAPIResponse, 0, len(page.Items)),
+	}
+
+	for _, account := range page.Items {
+		resp.Items = append(resp.Items, NewAPIResponseFromEntity(account))
+	}
+
+	if page.NextCursor != "" {
+		resp.NextCursor = &page.NextCursor
+	}
+
+	return resp
+}
+
+type StatusTransitionAPIResponse struct {
+	AccountID  int64     `json:"account_id"`
+	FromStatus Status    `json:"from_status"`
+	ToStatus   Status    `json:"to_status"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func NewStatusTransitionAPIResponseFromEntity(transition *StatusTransition) *StatusTransitionAPIResponse {
+	return &StatusTransitionAPIResponse{
+		AccountID:  transition.AccountID,
+		FromStatus: transition.FromStatus,
+		ToStatus:   transition.ToStatus,
+		Reason:     transition.Reason,
+		CreatedAt:  transition.CreatedAt,
+	}
+}
+
+type BalanceAPIResponse struct {
+	AccountID        int64                           `json:"account_id"`
+	Balance          money.Money                     `json:"balance"`
+	TotalPurchases   money.Money                     `json:"total_purchases"`
+	TotalWithdrawals money.Money                     `json:"total_withdrawals"`
+	TotalPayments    money.Money                     `json:"total_payments"`
+	OperationTypes   []*OperationTypeBalanceResponse `json:"operation_types"`
+}
+
+type OperationTypeBalanceResponse struct {
+	OperationTypeID operationtypes.Type `json:"operation_type_id"`
+	Total           money.Money         `json:"total"`
+}
+
+func NewBalanceAPIResponseFromEntity(balance *Balance) *BalanceAPIResponse {
+	resp := &BalanceAPIResponse{
+		AccountID:        balance.AccountID,
+		Balance:          balance.Balance,
+		TotalPurchases:   balance.TotalPurchases,
+		TotalWithdrawals: balance.TotalWithdrawals,
+		TotalPayments:    balance.TotalPayments,
+		OperationTypes:   make([]*OperationTypeBalanceResponse, 0, len(balance.OperationTypes)),
+	}
+
+	for _, opBalance := range balance.OperationTypes {
+		resp.OperationTypes = append(resp.OperationTypes, &OperationTypeBalanceResponse{
+			OperationTypeID: opBalance.OperationTypeID,
+			Total:           opBalance.Total,
+		})
+	}
+
+	return resp
+}
@@ -1,10 +1,95 @@
 package accounts
 
-import "time"
+import (
+	"slices"
+	"time"
+
+	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+)
+
+type DocumentType string
+
+const (
+	CPFDocumentType  DocumentType = "cpf"
+	CNPJDocumentType DocumentType = "cnpj"
+)
+
+type Status string
+
+const (
+	ActiveStatus    Status = "active"
+	BlockedStatus   Status = "blocked"
+	SuspendedStatus Status = "suspended"
+	ClosedStatus    Status = "closed"
+)
+
+// statusTransitions has the allowed transitions of each status, closed accounts can't be reopened.
+var statusTransitions = map[Status][]Status{ //nolint:gochecknoglobals // state machine
+	ActiveStatus:    {BlockedStatus, SuspendedStatus, ClosedStatus},
+	BlockedStatus:   {ActiveStatus, ClosedStatus},
+	SuspendedStatus: {ActiveStatus, ClosedStatus},
+	ClosedStatus:    {},
+}
+
+func (status Status) CanTransitionTo(next Status) bool {
+	return slices.Contains(statusTransitions[status], next)
+}
 
 type Account struct {
-	ID             int64
+	ID                   int64
+	DocumentNumber       string
+	DocumentType         DocumentType
+	HolderName           string
+	Email                string
+	Phone                string
+	BirthDate            *time.Time
+	IncorporationDate    *time.Time
+	AvailableCreditLimit money.Money
+	Currency             string
+	ClosingDay           int
+	DueDay               int
+	Status               Status
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+}
+
+type AccountsCursor struct {
+	ID int64 `json:"i"`
+}
+
+type AccountsFilter struct {
 	DocumentNumber string
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
+	Email          string
+	Status         Status
+	After          *AccountsCursor
+	Limit          int
+}
+
+type AccountsPage struct {
+	Items      []*Account
+	NextCursor string
+}
+
+type StatusTransition struct {
+	ID         int64
+	AccountID  int64
+	FromStatus Status
+	ToStatus   Status
+	Reason     string
+	CreatedAt  time.Time
+}
+
+type Balance struct {
+	AccountID        int64
+	Balance          money.Money
+	TotalPurchases   money.Money
+	TotalWithdrawals money.Money
+	TotalPayments    money.Money
+	OperationTypes   []*OperationTypeBalance
+}
+
+type OperationTypeBalance struct {
+	OperationTypeID operationtypes.Type
+	Total           money.Money
 }
@@ -13,6 +13,7 @@ import (
 	reflect "reflect"
 
 	accounts "github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
+	money "github.com/rudineirk/pismo-challenge/pkg/utils/money"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -39,6 +40,20 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddAvailableCreditLimit mocks base method.
+func (m *MockRepository) AddAvailableCreditLimit(ctx context.Context, id int64, amount money.Money) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAvailableCreditLimit", ctx, id, amount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAvailableCreditLimit indicates an expected call of AddAvailableCreditLimit.
+func (mr *MockRepositoryMockRecorder) AddAvailableCreditLimit(ctx, id, amount any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAvailableCreditLimit", reflect.TypeOf((*MockRepository)(nil).AddAvailableCreditLimit), ctx, id, amount)
+}
+
 // CreateAccount mocks base method.
 func (m *MockRepository) CreateAccount(arg0 context.Context, arg1 *accounts.Account) error {
 	m.ctrl.T.Helper()
@@ -53,6 +68,35 @@ func (mr *MockRepositoryMockRecorder) CreateAccount(arg0, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockRepository)(nil).CreateAccount), arg0, arg1)
 }
 
+// CreateStatusTransition mocks base method.
+func (m *MockRepository) CreateStatusTransition(arg0 context.Context, arg1 *accounts.StatusTransition) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateStatusTransition", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateStatusTransition indicates an expected call of CreateStatusTransition.
+func (mr *MockRepositoryMockRecorder) CreateStatusTransition(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStatusTransition", reflect.TypeOf((*MockRepository)(nil).CreateStatusTransition), arg0, arg1)
+}
+
+// GetAccountBalance mocks base method.
+func (m *MockRepository) GetAccountBalance(arg0 context.Context, arg1 int64) (*accounts.Balance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountBalance", arg0, arg1)
+	ret0, _ := ret[0].(*accounts.Balance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountBalance indicates an expected call of GetAccountBalance.
+func (mr *MockRepositoryMockRecorder) GetAccountBalance(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountBalance", reflect.TypeOf((*MockRepository)(nil).GetAccountBalance), arg0, arg1)
+}
+
 // GetAccountByID mocks base method.
 func (m *MockRepository) GetAccountByID(arg0 context.Context, arg1 int64) (*accounts.Account, error) {
 	m.ctrl.T.Helper()
@@ -67,3 +111,133 @@ func (mr *MockRepositoryMockRecorder) GetAccountByID(arg0, arg1 any) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByID", reflect.TypeOf((*MockRepository)(nil).GetAccountByID), arg0, arg1)
 }
+
+// ListAccounts mocks base method.
+func (m *MockRepository) ListAccounts(ctx context.Context, afterID int64, limit int) ([]*accounts.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccounts", ctx, afterID, limit)
+	ret0, _ := ret[0].([]*accounts.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccounts indicates an expected call of ListAccounts.
+func (mr *MockRepositoryMockRecorder) ListAccounts(ctx, afterID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockRepository)(nil).ListAccounts), ctx, afterID, limit)
+}
+
+// ListStatusTransitions mocks base method.
+func (m *MockRepository) ListStatusTransitions(ctx context.Context, accountID int64) ([]*accounts.StatusTransition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStatusTransitions", ctx, accountID)
+	ret0, _ := ret[0].([]*accounts.StatusTransition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStatusTransitions indicates an expected call of ListStatusTransitions.
+func (mr *MockRepositoryMockRecorder) ListStatusTransitions(ctx, accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStatusTransitions", reflect.TypeOf((*MockRepository)(nil).ListStatusTransitions), ctx, accountID)
+}
+
+// LockAccount mocks base method.
+func (m *MockRepository) LockAccount(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockAccount indicates an expected call of LockAccount.
+func (mr *MockRepositoryMockRecorder) LockAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockAccount", reflect.TypeOf((*MockRepository)(nil).LockAccount), arg0, arg1)
+}
+
+// ReencryptDocumentNumbers mocks base method.
+func (m *MockRepository) ReencryptDocumentNumbers(ctx context.Context, batchSize int, all bool) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReencryptDocumentNumbers", ctx, batchSize, all)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReencryptDocumentNumbers indicates an expected call of ReencryptDocumentNumbers.
+func (mr *MockRepositoryMockRecorder) ReencryptDocumentNumbers(ctx, batchSize, all any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReencryptDocumentNumbers", reflect.TypeOf((*MockRepository)(nil).ReencryptDocumentNumbers), ctx, batchSize, all)
+}
+
+// RunInTx mocks base method.
+func (m *MockRepository) RunInTx(arg0 context.Context, arg1 func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunInTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunInTx indicates an expected call of RunInTx.
+func (mr *MockRepositoryMockRecorder) RunInTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunInTx", reflect.TypeOf((*MockRepository)(nil).RunInTx), arg0, arg1)
+}
+
+// SearchAccounts mocks base method.
+func (m *MockRepository) SearchAccounts(arg0 context.Context, arg1 *accounts.AccountsFilter) ([]*accounts.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]*accounts.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchAccounts indicates an expected call of SearchAccounts.
+func (mr *MockRepositoryMockRecorder) SearchAccounts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchAccounts", reflect.TypeOf((*MockRepository)(nil).SearchAccounts), arg0, arg1)
+}
+
+// UpdateAccountHolder mocks base method.
+func (m *MockRepository) UpdateAccountHolder(arg0 context.Context, arg1 *accounts.Account) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccountHolder", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAccountHolder indicates an expected call of UpdateAccountHolder.
+func (mr *MockRepositoryMockRecorder) UpdateAccountHolder(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountHolder", reflect.TypeOf((*MockRepository)(nil).UpdateAccountHolder), arg0, arg1)
+}
+
+// UpdateAccountStatus mocks base method.
+func (m *MockRepository) UpdateAccountStatus(ctx context.Context, account *accounts.Account, from accounts.Status) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccountStatus", ctx, account, from)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAccountStatus indicates an expected call of UpdateAccountStatus.
+func (mr *MockRepositoryMockRecorder) UpdateAccountStatus(ctx, account, from any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountStatus", reflect.TypeOf((*MockRepository)(nil).UpdateAccountStatus), ctx, account, from)
+}
+
+// UpdateAvailableCreditLimit mocks base method.
+func (m *MockRepository) UpdateAvailableCreditLimit(arg0 context.Context, arg1 *accounts.Account) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAvailableCreditLimit", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAvailableCreditLimit indicates an expected call of UpdateAvailableCreditLimit.
+func (mr *MockRepositoryMockRecorder) UpdateAvailableCreditLimit(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAvailableCreditLimit", reflect.TypeOf((*MockRepository)(nil).UpdateAvailableCreditLimit), arg0, arg1)
+}
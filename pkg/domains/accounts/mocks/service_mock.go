@@ -13,6 +13,7 @@ import (
 	reflect "reflect"
 
 	accounts "github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
+	money "github.com/rudineirk/pismo-challenge/pkg/utils/money"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -39,6 +40,35 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// AddAvailableCreditLimit mocks base method.
+func (m *MockService) AddAvailableCreditLimit(ctx context.Context, id int64, amount money.Money) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAvailableCreditLimit", ctx, id, amount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAvailableCreditLimit indicates an expected call of AddAvailableCreditLimit.
+func (mr *MockServiceMockRecorder) AddAvailableCreditLimit(ctx, id, amount any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAvailableCreditLimit", reflect.TypeOf((*MockService)(nil).AddAvailableCreditLimit), ctx, id, amount)
+}
+
+// ChangeStatus mocks base method.
+func (m *MockService) ChangeStatus(arg0 context.Context, arg1 *accounts.ChangeStatusRequest) (*accounts.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeStatus", arg0, arg1)
+	ret0, _ := ret[0].(*accounts.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeStatus indicates an expected call of ChangeStatus.
+func (mr *MockServiceMockRecorder) ChangeStatus(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeStatus", reflect.TypeOf((*MockService)(nil).ChangeStatus), arg0, arg1)
+}
+
 // CreateAccount mocks base method.
 func (m *MockService) CreateAccount(arg0 context.Context, arg1 *accounts.CreateAccountRequest) (*accounts.Account, error) {
 	m.ctrl.T.Helper()
@@ -54,6 +84,21 @@ func (mr *MockServiceMockRecorder) CreateAccount(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockService)(nil).CreateAccount), arg0, arg1)
 }
 
+// GetAccountBalance mocks base method.
+func (m *MockService) GetAccountBalance(arg0 context.Context, arg1 int64) (*accounts.Balance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountBalance", arg0, arg1)
+	ret0, _ := ret[0].(*accounts.Balance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountBalance indicates an expected call of GetAccountBalance.
+func (mr *MockServiceMockRecorder) GetAccountBalance(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountBalance", reflect.TypeOf((*MockService)(nil).GetAccountBalance), arg0, arg1)
+}
+
 // GetAccountByID mocks base method.
 func (m *MockService) GetAccountByID(arg0 context.Context, arg1 int64) (*accounts.Account, error) {
 	m.ctrl.T.Helper()
@@ -68,3 +113,78 @@ func (mr *MockServiceMockRecorder) GetAccountByID(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByID", reflect.TypeOf((*MockService)(nil).GetAccountByID), arg0, arg1)
 }
+
+// ListAccounts mocks base method.
+func (m *MockService) ListAccounts(ctx context.Context, afterID int64, limit int) ([]*accounts.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccounts", ctx, afterID, limit)
+	ret0, _ := ret[0].([]*accounts.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccounts indicates an expected call of ListAccounts.
+func (mr *MockServiceMockRecorder) ListAccounts(ctx, afterID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockService)(nil).ListAccounts), ctx, afterID, limit)
+}
+
+// ListStatusTransitions mocks base method.
+func (m *MockService) ListStatusTransitions(ctx context.Context, accountID int64) ([]*accounts.StatusTransition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStatusTransitions", ctx, accountID)
+	ret0, _ := ret[0].([]*accounts.StatusTransition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStatusTransitions indicates an expected call of ListStatusTransitions.
+func (mr *MockServiceMockRecorder) ListStatusTransitions(ctx, accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStatusTransitions", reflect.TypeOf((*MockService)(nil).ListStatusTransitions), ctx, accountID)
+}
+
+// SearchAccounts mocks base method.
+func (m *MockService) SearchAccounts(arg0 context.Context, arg1 *accounts.SearchAccountsRequest) (*accounts.AccountsPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchAccounts", arg0, arg1)
+	ret0, _ := ret[0].(*accounts.AccountsPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchAccounts indicates an expected call of SearchAccounts.
+func (mr *MockServiceMockRecorder) SearchAccounts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchAccounts", reflect.TypeOf((*MockService)(nil).SearchAccounts), arg0, arg1)
+}
+
+// UpdateAccount mocks base method.
+func (m *MockService) UpdateAccount(arg0 context.Context, arg1 *accounts.UpdateAccountRequest) (*accounts.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccount", arg0, arg1)
+	ret0, _ := ret[0].(*accounts.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAccount indicates an expected call of UpdateAccount.
+func (mr *MockServiceMockRecorder) UpdateAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccount", reflect.TypeOf((*MockService)(nil).UpdateAccount), arg0, arg1)
+}
+
+// UpdateAvailableCreditLimit mocks base method.
+func (m *MockService) UpdateAvailableCreditLimit(arg0 context.Context, arg1 *accounts.UpdateCreditLimitRequest) (*accounts.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAvailableCreditLimit", arg0, arg1)
+	ret0, _ := ret[0].(*accounts.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAvailableCreditLimit indicates an expected call of UpdateAvailableCreditLimit.
+func (mr *MockServiceMockRecorder) UpdateAvailableCreditLimit(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAvailableCreditLimit", reflect.TypeOf((*MockService)(nil).UpdateAvailableCreditLimit), arg0, arg1)
+}
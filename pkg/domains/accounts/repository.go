@@ -7,53 +7,180 @@ import (
 	"strings"
 	"time"
 
+	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/database"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/encryption"
 	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
 	"github.com/uptrace/bun"
 )
 
 type Repository interface {
+	RunInTx(context.Context, func(context.Context) error) error
+	LockAccount(context.Context, int64) error
 	CreateAccount(context.Context, *Account) error
 	GetAccountByID(context.Context, int64) (*Account, error)
+	ListAccounts(ctx context.Context, afterID int64, limit int) ([]*Account, error)
+	SearchAccounts(context.Context, *AccountsFilter) ([]*Account, error)
+	GetAccountBalance(context.Context, int64) (*Balance, error)
+	UpdateAvailableCreditLimit(context.Context, *Account) error
+	UpdateAccountHolder(context.Context, *Account) error
+	AddAvailableCreditLimit(ctx context.Context, id int64, amount money.Money) error
+	UpdateAccountStatus(ctx context.Context, account *Account, from Status) error
+	CreateStatusTransition(context.Context, *StatusTransition) error
+	ListStatusTransitions(ctx context.Context, accountID int64) ([]*StatusTransition, error)
+	ReencryptDocumentNumbers(ctx context.Context, batchSize int, all bool) (int, error)
 }
 
 type AccountModel struct {
-	bun.BaseModel  `bun:"table:accounts"`
-	ID             int64     `bun:"id,pk,autoincrement"`
-	DocumentNumber string    `bun:"document_number"`
-	CreatedAt      time.Time `bun:"created_at"`
-	UpdatedAt      time.Time `bun:"updated_at"`
+	bun.BaseModel        `bun:"table:accounts"`
+	ID                   int64        `bun:"id,pk,autoincrement"`
+	DocumentNumber       string       `bun:"document_number,nullzero"`
+	DocumentCiphertext   []byte       `bun:"document_number_ciphertext"`
+	DocumentKeyID        string       `bun:"document_number_key_id,nullzero"`
+	DocumentIndex        string       `bun:"document_number_index,nullzero"`
+	DocumentType         DocumentType `bun:"document_type"`
+	HolderName           string       `bun:"holder_name"`
+	Email                string       `bun:"email"`
+	Phone                string       `bun:"phone"`
+	BirthDate            *time.Time   `bun:"birth_date,type:date"`
+	IncorporationDate    *time.Time   `bun:"incorporation_date,type:date"`
+	AvailableCreditLimit money.Money  `bun:"available_credit_limit"`
+	Currency             string       `bun:"currency"`
+	ClosingDay           int          `bun:"closing_day"`
+	DueDay               int          `bun:"due_day"`
+	Status               Status       `bun:"status"`
+	CreatedAt            time.Time    `bun:"created_at"`
+	UpdatedAt            time.Time    `bun:"updated_at"`
 }
 
+// NewModelFromEntity doesn't copy the document number, it must be encrypted by the repository.
 func NewModelFromEntity(account *Account) *AccountModel {
 	return &AccountModel{
-		ID:             account.ID,
-		DocumentNumber: account.DocumentNumber,
-		CreatedAt:      account.CreatedAt,
-		UpdatedAt:      account.UpdatedAt,
+		ID:                   account.ID,
+		DocumentType:         account.DocumentType,
+		HolderName:           account.HolderName,
+		Email:                account.Email,
+		Phone:                account.Phone,
+		BirthDate:            account.BirthDate,
+		IncorporationDate:    account.IncorporationDate,
+		AvailableCreditLimit: account.AvailableCreditLimit,
+		Currency:             account.Currency,
+		ClosingDay:           account.ClosingDay,
+		DueDay:               account.DueDay,
+		Status:               account.Status,
+		CreatedAt:            account.CreatedAt,
+		UpdatedAt:            account.UpdatedAt,
 	}
 }
 
 func (model *AccountModel) ToEntity() *Account {
 	return &Account{
-		ID:             model.ID,
-		DocumentNumber: model.DocumentNumber,
-		CreatedAt:      model.CreatedAt,
-		UpdatedAt:      model.UpdatedAt,
+		ID:                   model.ID,
+		DocumentNumber:       model.DocumentNumber,
+		DocumentType:         model.DocumentType,
+		HolderName:           model.HolderName,
+		Email:                model.Email,
+		Phone:                model.Phone,
+		BirthDate:            model.BirthDate,
+		IncorporationDate:    model.IncorporationDate,
+		AvailableCreditLimit: model.AvailableCreditLimit,
+		Currency:             model.Currency,
+		ClosingDay:           model.ClosingDay,
+		DueDay:               model.DueDay,
+		Status:               model.Status,
+		CreatedAt:            model.CreatedAt,
+		UpdatedAt:            model.UpdatedAt,
 	}
 }
 
+type StatusTransitionModel struct {
+	bun.BaseModel `bun:"table:account_status_transitions"`
+	ID            int64     `bun:"id,pk,autoincrement"`
+	AccountID     int64     `bun:"account_id"`
+	FromStatus    Status    `bun:"from_status"`
+	ToStatus      Status    `bun:"to_status"`
+	Reason        string    `bun:"reason"`
+	CreatedAt     time.Time `bun:"created_at"`
+}
+
+func NewStatusTransitionModelFromEntity(transition *StatusTransition) *StatusTransitionModel {
+	return &StatusTransitionModel{
+		ID:         transition.ID,
+		AccountID:  transition.AccountID,
+		FromStatus: transition.FromStatus,
+		ToStatus:   transition.ToStatus,
+		Reason:     transition.Reason,
+		CreatedAt:  transition.CreatedAt,
+	}
+}
+
+func (model *StatusTransitionModel) ToEntity() *StatusTransition {
+	return &StatusTransition{
+		ID:         model.ID,
+		AccountID:  model.AccountID,
+		FromStatus: model.FromStatus,
+		ToStatus:   model.ToStatus,
+		Reason:     model.Reason,
+		CreatedAt:  model.CreatedAt,
+	}
+}
+
+type OperationTypeBalanceModel struct {
+	OperationTypeID operationtypes.Type     `bun:"operation_type_id"`
+	Category        operationtypes.Category `bun:"category"`
+	Total           money.Money             `bun:"total"`
+}
+
 type dbRepository struct {
-	bunDB *bun.DB
+	bunDB   *bun.DB
+	keyring *encryption.Keyring
 }
 
-func NewRepository(bunDB *bun.DB) Repository {
-	return &dbRepository{bunDB}
+func NewRepository(bunDB *bun.DB, keyring *encryption.Keyring) Repository {
+	return &dbRepository{bunDB, keyring}
+}
+
+func (repo *dbRepository) RunInTx(ctx context.Context, fn func(context.Context) error) error {
+	return database.RunInTx(ctx, repo.bunDB, fn)
+}
+
+func (repo *dbRepository) LockAccount(ctx context.Context, id int64) error {
+	var lockedID int64
+
+	err := database.Conn(ctx, repo.bunDB).NewSelect().
+		Model((*AccountModel)(nil)).
+		Column("id").
+		Where("id = ?", id).
+		For("UPDATE").
+		Scan(ctx, &lockedID)
+
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return errorlib.ErrNotFound(err)
+	}
+
+	return err
 }
 
 func (repo *dbRepository) CreateAccount(ctx context.Context, account *Account) error {
 	accountModel := NewModelFromEntity(account)
+	if err := repo.encryptDocumentNumber(accountModel, account.DocumentNumber); err != nil {
+		return err
+	}
+
+	// rows not re-encrypted yet still have the plaintext document number
+	exists, err := database.Conn(ctx, repo.bunDB).NewSelect().
+		Model((*AccountModel)(nil)).
+		Where("document_number = ?", account.DocumentNumber).
+		Exists(ctx)
+
+	if err != nil {
+		return err
+	} else if exists {
+		return errorlib.ErrDuplicated(nil)
+	}
 
-	_, err := repo.bunDB.NewInsert().
+	_, err = database.Conn(ctx, repo.bunDB).NewInsert().
 		Model(accountModel).
 		Exec(ctx)
 
@@ -71,7 +198,7 @@ func (repo *dbRepository) CreateAccount(ctx context.Context, account *Account) e
 func (repo *dbRepository) GetAccountByID(ctx context.Context, id int64) (*Account, error) {
 	accountModel := AccountModel{}
 
-	err := repo.bunDB.NewSelect().
+	err := database.Conn(ctx, repo.bunDB).NewSelect().
 		Model(&accountModel).
 		Where("id = ?", id).
 		Scan(ctx)
@@ -82,5 +209,345 @@ func (repo *dbRepository) GetAccountByID(ctx context.Context, id int64) (*Accoun
 		return nil, err
 	}
 
-	return accountModel.ToEntity(), nil
+	return repo.toEntity(&accountModel)
+}
+
+func (repo *dbRepository) ListAccounts(ctx context.Context, afterID int64, limit int) ([]*Account, error) {
+	accountModels := []*AccountModel{}
+
+	err := database.Conn(ctx, repo.bunDB).NewSelect().
+		Model(&accountModels).
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Scan(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.toEntities(accountModels)
+}
+
+func (repo *dbRepository) SearchAccounts(ctx context.Context, filter *AccountsFilter) ([]*Account, error) {
+	accountModels := []*AccountModel{}
+
+	query := database.Conn(ctx, repo.bunDB).NewSelect().
+		Model(&accountModels).
+		Order("id").
+		Limit(filter.Limit)
+
+	if filter.DocumentNumber != "" {
+		query = query.WhereGroup(" AND ", func(query *bun.SelectQuery) *bun.SelectQuery {
+			return query.
+				Where("document_number_index = ?", repo.keyring.BlindIndex(filter.DocumentNumber)).
+				WhereOr("document_number = ?", filter.DocumentNumber)
+		})
+	}
+
+	if filter.Email != "" {
+		query = query.Where("email = ?", filter.Email)
+	}
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if filter.After != nil {
+		query = query.Where("id > ?", filter.After.ID)
+	}
+
+	if err := query.Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return repo.toEntities(accountModels)
+}
+
+// GetAccountBalance sums the transactions by operation type, and the totals by the category of each type.
+func (repo *dbRepository) GetAccountBalance(ctx context.Context, accountID int64) (*Balance, error) {
+	operationTypeModels := []*OperationTypeBalanceModel{}
+
+	err := database.Conn(ctx, repo.bunDB).NewSelect().
+		TableExpr("transactions AS t").
+		Join("JOIN operation_types AS ot ON ot.id = t.operation_type_id").
+		ColumnExpr("t.operation_type_id").
+		ColumnExpr("ot.category").
+		ColumnExpr("SUM(t.amount) AS total").
+		Where("t.account_id = ?", accountID).
+		GroupExpr("t.operation_type_id, ot.category").
+		OrderExpr("t.operation_type_id").
+		Scan(ctx, &operationTypeModels)
+
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &Balance{
+		AccountID:      accountID,
+		OperationTypes: make([]*OperationTypeBalance, 0, len(operationTypeModels)),
+	}
+
+	for _, model := range operationTypeModels {
+		balance.Balance = balance.Balance.Add(model.Total)
+
+		switch model.Category {
+		case operationtypes.PurchaseCategory:
+			balance.TotalPurchases = balance.TotalPurchases.Add(model.Total)
+		case operationtypes.WithdrawalCategory:
+			balance.TotalWithdrawals = balance.TotalWithdrawals.Add(model.Total)
+		case operationtypes.PaymentCategory:
+			balance.TotalPayments = balance.TotalPayments.Add(model.Total)
+		case operationtypes.OtherCategory:
+		}
+
+		balance.OperationTypes = append(balance.OperationTypes, &OperationTypeBalance{
+			OperationTypeID: model.OperationTypeID,
+			Total:           model.Total,
+		})
+	}
+
+	return balance, nil
+}
+
+func (repo *dbRepository) UpdateAvailableCreditLimit(ctx context.Context, account *Account) error {
+	result, err := database.Conn(ctx, repo.bunDB).NewUpdate().
+		Model((*AccountModel)(nil)).
+		Set("available_credit_limit = ?", account.AvailableCreditLimit).
+		Set("updated_at = ?", account.UpdatedAt).
+		Where("id = ?", account.ID).
+		Exec(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	return checkRowsAffected(result)
+}
+
+func (repo *dbRepository) UpdateAccountHolder(ctx context.Context, account *Account) error {
+	result, err := database.Conn(ctx, repo.bunDB).NewUpdate().
+		Model((*AccountModel)(nil)).
+		Set("holder_name = ?", account.HolderName).
+		Set("email = ?", account.Email).
+		Set("phone = ?", account.Phone).
+		Set("birth_date = ?", account.BirthDate).
+		Set("incorporation_date = ?", account.IncorporationDate).
+		Set("updated_at = ?", account.UpdatedAt).
+		Where("id = ?", account.ID).
+		Exec(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	return checkRowsAffected(result)
+}
+
+func (repo *dbRepository) AddAvailableCreditLimit(ctx context.Context, id int64, amount money.Money) error {
+	result, err := database.Conn(ctx, repo.bunDB).NewUpdate().
+		Model((*AccountModel)(nil)).
+		Set("available_credit_limit = available_credit_limit + ?", amount).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	return checkRowsAffected(result)
+}
+
+// UpdateAccountStatus only updates the account if its status is still the given one,
+// returning a not found error otherwise.
+func (repo *dbRepository) UpdateAccountStatus(ctx context.Context, account *Account, from Status) error {
+	result, err := database.Conn(ctx, repo.bunDB).NewUpdate().
+		Model((*AccountModel)(nil)).
+		Set("status = ?", account.Status).
+		Set("updated_at = ?", account.UpdatedAt).
+		Where("id = ?", account.ID).
+		Where("status = ?", from).
+		Exec(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	return checkRowsAffected(result)
+}
+
+func (repo *dbRepository) CreateStatusTransition(ctx context.Context, transition *StatusTransition) error {
+	transitionModel := NewStatusTransitionModelFromEntity(transition)
+
+	_, err := database.Conn(ctx, repo.bunDB).NewInsert().
+		Model(transitionModel).
+		Exec(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	transition.ID = transitionModel.ID
+
+	return nil
+}
+
+func (repo *dbRepository) ListStatusTransitions(ctx context.Context, accountID int64) ([]*StatusTransition, error) {
+	transitionModels := []*StatusTransitionModel{}
+
+	err := database.Conn(ctx, repo.bunDB).NewSelect().
+		Model(&transitionModels).
+		Where("account_id = ?", accountID).
+		Order("id").
+		Scan(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	transitions := make([]*StatusTransition, 0, len(transitionModels))
+	for _, model := range transitionModels {
+		transitions = append(transitions, model.ToEntity())
+	}
+
+	return transitions, nil
+}
+
+// ReencryptDocumentNumbers encrypts the plaintext document numbers and the ones using an old key
+// with the active key, in batches. When all is set every row is rewritten, which is required after
+// changing the blind index key.
+func (repo *dbRepository) ReencryptDocumentNumbers(ctx context.Context, batchSize int, all bool) (int, error) {
+	total := 0
+	afterID := int64(0)
+
+	for {
+		accountModels := []*AccountModel{}
+
+		err := repo.RunInTx(ctx, func(ctx context.Context) error {
+			query := database.Conn(ctx, repo.bunDB).NewSelect().
+				Model(&accountModels).
+				Where("id > ?", afterID).
+				Order("id").
+				Limit(batchSize).
+				For("UPDATE")
+
+			if !all {
+				query = query.WhereGroup(" AND ", func(query *bun.SelectQuery) *bun.SelectQuery {
+					return query.
+						Where("document_number IS NOT NULL").
+						WhereOr("document_number_key_id <> ?", repo.keyring.ActiveKeyID())
+				})
+			}
+
+			if err := query.Scan(ctx); err != nil {
+				return err
+			}
+
+			for _, model := range accountModels {
+				if err := repo.reencryptAccount(ctx, model); err != nil {
+					return err
+				}
+
+				afterID = model.ID
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return total, err
+		}
+
+		total += len(accountModels)
+
+		if len(accountModels) < batchSize {
+			return total, nil
+		}
+	}
+}
+
+func (repo *dbRepository) reencryptAccount(ctx context.Context, model *AccountModel) error {
+	documentNumber, err := repo.decryptDocumentNumber(model)
+	if err != nil {
+		return err
+	}
+
+	if err := repo.encryptDocumentNumber(model, documentNumber); err != nil {
+		return err
+	}
+
+	_, err = database.Conn(ctx, repo.bunDB).NewUpdate().
+		Model(model).
+		Column("document_number", "document_number_ciphertext", "document_number_key_id", "document_number_index").
+		WherePK().
+		Exec(ctx)
+
+	return err
+}
+
+func (repo *dbRepository) encryptDocumentNumber(model *AccountModel, documentNumber string) error {
+	keyID, ciphertext, err := repo.keyring.Encrypt([]byte(documentNumber))
+	if err != nil {
+		return err
+	}
+
+	model.DocumentNumber = ""
+	model.DocumentCiphertext = ciphertext
+	model.DocumentKeyID = keyID
+	model.DocumentIndex = repo.keyring.BlindIndex(documentNumber)
+
+	return nil
+}
+
+func (repo *dbRepository) decryptDocumentNumber(model *AccountModel) (string, error) {
+	if model.DocumentCiphertext == nil {
+		return model.DocumentNumber, nil
+	}
+
+	documentNumber, err := repo.keyring.Decrypt(model.DocumentKeyID, model.DocumentCiphertext)
+	if err != nil {
+		return "", err
+	}
+
+	return string(documentNumber), nil
+}
+
+func (repo *dbRepository) toEntity(model *AccountModel) (*Account, error) {
+	account := model.ToEntity()
+
+	documentNumber, err := repo.decryptDocumentNumber(model)
+	if err != nil {
+		return nil, err
+	}
+
+	account.DocumentNumber = documentNumber
+
+	return account, nil
+}
+
+func (repo *dbRepository) toEntities(models []*AccountModel) ([]*Account, error) {
+	accounts := make([]*Account, 0, len(models))
+
+	for _, model := range models {
+		account, err := repo.toEntity(model)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
+
+func checkRowsAffected(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	} else if rows == 0 {
+		return errorlib.ErrNotFound(nil)
+	}
+
+	return nil
 }
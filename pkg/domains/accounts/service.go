@@ -3,61 +3,213 @@ package accounts
 import (
 	"bytes"
 	"context"
+	"errors"
+	"net/http"
+	"strings"
 	"time"
 	"unicode"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/paemuri/brdoc/v2"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/metrics"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/outbox"
 	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/pagination"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/validation"
+)
+
+const (
+	AccountCreatedEvent       = "AccountCreated"
+	AccountStatusChangedEvent = "AccountStatusChanged"
+
+	DefaultClosingDay = 25
+	DefaultDueDay     = 5
+
+	dateLayout = "2006-01-02"
 )
 
 var ErrInvalidDocumentNumber = errorlib.NewError( //nolint:gochecknoglobals // error maker
 	"invalid_document_number",
 	"invalid document number",
+	http.StatusBadRequest,
+)
+var ErrInvalidCreditLimit = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"invalid_credit_limit",
+	"invalid credit limit",
+	http.StatusBadRequest,
+)
+var ErrInvalidHolderDate = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"invalid_holder_date",
+	"birth_date is only allowed for CPF and incorporation_date for CNPJ, and they can't be in the future",
+	http.StatusBadRequest,
+)
+var ErrImmutableDocumentNumber = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"immutable_document_number",
+	"document_number can't be changed",
+	http.StatusBadRequest,
+)
+var ErrInvalidCursor = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"invalid_cursor",
+	"invalid cursor",
+	http.StatusBadRequest,
+)
+var ErrInvalidStatusTransition = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"invalid_status_transition",
+	"invalid account status transition",
+	http.StatusConflict,
 )
 
 type Service interface {
 	CreateAccount(context.Context, *CreateAccountRequest) (*Account, error)
 	GetAccountByID(context.Context, int64) (*Account, error)
+	ListAccounts(ctx context.Context, afterID int64, limit int) ([]*Account, error)
+	SearchAccounts(context.Context, *SearchAccountsRequest) (*AccountsPage, error)
+	GetAccountBalance(context.Context, int64) (*Balance, error)
+	UpdateAvailableCreditLimit(context.Context, *UpdateCreditLimitRequest) (*Account, error)
+	UpdateAccount(context.Context, *UpdateAccountRequest) (*Account, error)
+	AddAvailableCreditLimit(ctx context.Context, id int64, amount money.Money) error
+	ChangeStatus(context.Context, *ChangeStatusRequest) (*Account, error)
+	ListStatusTransitions(ctx context.Context, accountID int64) ([]*StatusTransition, error)
 }
 
 type CreateAccountRequest struct {
-	DocumentNumber string `json:"document_number" validate:"required"`
+	DocumentNumber       string       `json:"document_number"        validate:"required"`
+	AvailableCreditLimit *money.Money `json:"available_credit_limit" validate:"omitempty,gte=0"`
+	Currency             string       `json:"currency"               validate:"omitempty,iso4217"`
+	ClosingDay           int          `json:"closing_day"            validate:"omitempty,min=1,max=28"`
+	DueDay               int          `json:"due_day"                validate:"omitempty,min=1,max=28"`
+	HolderName           string       `json:"holder_name"            validate:"max=255"`
+	Email                string       `json:"email"                  validate:"omitempty,email,max=255"`
+	Phone                string       `json:"phone"                  validate:"omitempty,e164"`
+	BirthDate            string       `json:"birth_date"             validate:"omitempty,datetime=2006-01-02"`
+	IncorporationDate    string       `json:"incorporation_date"     validate:"omitempty,datetime=2006-01-02"`
+}
+
+// UpdateAccountRequest only changes the fields present in the payload. The document number
+// is only read to reject requests trying to change it.
+type UpdateAccountRequest struct {
+	AccountID         int64   `json:"-"                  validate:"required"`
+	DocumentNumber    *string `json:"document_number"`
+	HolderName        *string `json:"holder_name"        validate:"omitempty,min=1,max=255"`
+	Email             *string `json:"email"              validate:"omitempty,email,max=255"`
+	Phone             *string `json:"phone"              validate:"omitempty,e164"`
+	BirthDate         *string `json:"birth_date"         validate:"omitempty,datetime=2006-01-02"`
+	IncorporationDate *string `json:"incorporation_date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type UpdateCreditLimitRequest struct {
+	AccountID            int64        `json:"-"                      validate:"required"`
+	AvailableCreditLimit *money.Money `json:"available_credit_limit" validate:"required,gte=0"`
+}
+
+type SearchAccountsRequest struct {
+	DocumentNumber string `form:"document_number"`
+	Email          string `form:"email"           validate:"omitempty,email"`
+	Status         Status `form:"status"          validate:"omitempty,oneof=active blocked suspended closed"`
+	Cursor         string `form:"cursor"`
+	Limit          int    `form:"limit"           validate:"omitempty,min=1,max=100"`
+}
+
+type ChangeStatusRequest struct {
+	AccountID int64  `json:"-"      validate:"required"`
+	Status    Status `json:"-"      validate:"required,oneof=active blocked suspended closed"`
+	Reason    string `json:"reason" validate:"required,max=255"`
 }
 
 type accountsService struct {
-	repo     Repository
-	validate *validator.Validate
+	repo               Repository
+	events             outbox.Store
+	defaultCreditLimit money.Money
+	validate           *validator.Validate
 }
 
-func NewService(repo Repository) Service {
+// NewService creates the accounts service, the default credit limit is used by the accounts
+// created without an available_credit_limit.
+func NewService(repo Repository, events outbox.Store, defaultCreditLimit money.Money) Service {
+	validate := validation.New()
+
 	return &accountsService{
-		repo:     repo,
-		validate: validator.New(validator.WithRequiredStructEnabled()),
+		repo:               repo,
+		events:             events,
+		defaultCreditLimit: defaultCreditLimit,
+		validate:           validate,
 	}
 }
 
 func (svc *accountsService) CreateAccount(ctx context.Context, req *CreateAccountRequest) (*Account, error) {
+	req.Currency = money.NormalizeCurrency(req.Currency)
+	if req.Currency == "" {
+		req.Currency = money.DefaultCurrency
+	}
+
+	if req.AvailableCreditLimit == nil {
+		req.AvailableCreditLimit = &svc.defaultCreditLimit
+	}
+
 	if err := svc.validate.Struct(req); err != nil {
 		return nil, errorlib.ErrInvalidPayload(err)
 	}
 
 	documentNumber := svc.cleanDocumentNumber(req.DocumentNumber)
-	if err := svc.validateDocument(documentNumber); err != nil {
+
+	documentType, err := svc.validateDocument(documentNumber)
+	if err != nil {
 		return nil, err
+	} else if !req.AvailableCreditLimit.HasCentsPrecision() {
+		return nil, ErrInvalidCreditLimit(nil)
 	}
 
 	account := &Account{
-		DocumentNumber: documentNumber,
-		CreatedAt:      time.Now(),
+		DocumentNumber:       documentNumber,
+		DocumentType:         documentType,
+		HolderName:           strings.TrimSpace(req.HolderName),
+		Email:                strings.ToLower(req.Email),
+		Phone:                req.Phone,
+		AvailableCreditLimit: *req.AvailableCreditLimit,
+		Currency:             req.Currency,
+		ClosingDay:           req.ClosingDay,
+		DueDay:               req.DueDay,
+		Status:               ActiveStatus,
+		CreatedAt:            time.Now(),
+	}
+
+	if account.ClosingDay == 0 {
+		account.ClosingDay = DefaultClosingDay
+	}
+
+	if account.DueDay == 0 {
+		account.DueDay = DefaultDueDay
+	}
+
+	account.BirthDate, _ = parseDate(req.BirthDate)
+	account.IncorporationDate, _ = parseDate(req.IncorporationDate)
+
+	if err := svc.validateHolderDates(account); err != nil {
+		return nil, err
 	}
 
 	account.UpdatedAt = account.CreatedAt
 
-	if err := svc.repo.CreateAccount(ctx, account); err != nil {
+	err = svc.repo.RunInTx(ctx, func(ctx context.Context) error {
+		if err := svc.repo.CreateAccount(ctx, account); err != nil {
+			return err
+		}
+
+		event, err := outbox.NewEvent(AccountCreatedEvent, account.ID, NewAccountCreatedEventPayload(account))
+		if err != nil {
+			return err
+		}
+
+		return svc.events.AddEvent(ctx, event)
+	})
+
+	if err != nil {
 		return nil, err
 	}
 
+	metrics.AccountsCreated.Inc()
+
 	return account, nil
 }
 
@@ -65,6 +217,231 @@ func (svc *accountsService) GetAccountByID(ctx context.Context, id int64) (*Acco
 	return svc.repo.GetAccountByID(ctx, id)
 }
 
+// ListAccounts iterates over all the accounts ordered by id, used by the background jobs.
+func (svc *accountsService) ListAccounts(ctx context.Context, afterID int64, limit int) ([]*Account, error) {
+	return svc.repo.ListAccounts(ctx, afterID, limit)
+}
+
+// SearchAccounts finds the accounts matching all the given filters, ordered by id. The document
+// number can be sent formatted or only with its digits.
+func (svc *accountsService) SearchAccounts(ctx context.Context, req *SearchAccountsRequest) (*AccountsPage, error) {
+	if err := svc.validate.Struct(req); err != nil {
+		return nil, errorlib.ErrInvalidPayload(err)
+	}
+
+	filter := &AccountsFilter{
+		DocumentNumber: svc.cleanDocumentNumber(req.DocumentNumber),
+		Email:          strings.ToLower(req.Email),
+		Status:         req.Status,
+	}
+
+	if req.DocumentNumber != "" && filter.DocumentNumber == "" {
+		return nil, ErrInvalidDocumentNumber(nil)
+	}
+
+	if req.Cursor != "" {
+		filter.After = &AccountsCursor{}
+		if err := pagination.DecodeCursor(req.Cursor, filter.After); err != nil {
+			return nil, ErrInvalidCursor(err)
+		}
+	}
+
+	limit := pagination.NormalizeLimit(req.Limit)
+	filter.Limit = limit + 1
+
+	accounts, err := svc.repo.SearchAccounts(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &AccountsPage{Items: accounts}
+	if len(accounts) > limit {
+		page.Items = accounts[:limit]
+
+		page.NextCursor, err = pagination.EncodeCursor(AccountsCursor{ID: page.Items[limit-1].ID})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+func (svc *accountsService) GetAccountBalance(ctx context.Context, id int64) (*Balance, error) {
+	if _, err := svc.repo.GetAccountByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return svc.repo.GetAccountBalance(ctx, id)
+}
+
+func (svc *accountsService) UpdateAvailableCreditLimit(
+	ctx context.Context,
+	req *UpdateCreditLimitRequest,
+) (*Account, error) {
+	if err := svc.validate.Struct(req); err != nil {
+		return nil, errorlib.ErrInvalidPayload(err)
+	} else if !req.AvailableCreditLimit.HasCentsPrecision() {
+		return nil, ErrInvalidCreditLimit(nil)
+	}
+
+	account, err := svc.repo.GetAccountByID(ctx, req.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	account.AvailableCreditLimit = *req.AvailableCreditLimit
+	account.UpdatedAt = time.Now()
+
+	if err := svc.repo.UpdateAvailableCreditLimit(ctx, account); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (svc *accountsService) UpdateAccount(ctx context.Context, req *UpdateAccountRequest) (*Account, error) {
+	if req.HolderName != nil {
+		*req.HolderName = strings.TrimSpace(*req.HolderName)
+	}
+
+	if err := svc.validate.Struct(req); err != nil {
+		return nil, errorlib.ErrInvalidPayload(err)
+	}
+
+	var account *Account
+
+	// the row lock serializes the concurrent updates, so each one merges the changes of the previous
+	err := svc.repo.RunInTx(ctx, func(ctx context.Context) error {
+		if err := svc.repo.LockAccount(ctx, req.AccountID); err != nil {
+			return err
+		}
+
+		var err error
+		if account, err = svc.repo.GetAccountByID(ctx, req.AccountID); err != nil {
+			return err
+		}
+
+		if err = svc.mergeAccountChanges(account, req); err != nil {
+			return err
+		}
+
+		account.UpdatedAt = time.Now()
+
+		return svc.repo.UpdateAccountHolder(ctx, account)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (svc *accountsService) mergeAccountChanges(account *Account, req *UpdateAccountRequest) error {
+	if req.DocumentNumber != nil && svc.cleanDocumentNumber(*req.DocumentNumber) != account.DocumentNumber {
+		return ErrImmutableDocumentNumber(nil)
+	}
+
+	if req.HolderName != nil {
+		account.HolderName = *req.HolderName
+	}
+
+	if req.Email != nil {
+		account.Email = strings.ToLower(*req.Email)
+	}
+
+	if req.Phone != nil {
+		account.Phone = *req.Phone
+	}
+
+	if req.BirthDate != nil {
+		account.BirthDate, _ = parseDate(*req.BirthDate)
+	}
+
+	if req.IncorporationDate != nil {
+		account.IncorporationDate, _ = parseDate(*req.IncorporationDate)
+	}
+
+	return svc.validateHolderDates(account)
+}
+
+func (svc *accountsService) AddAvailableCreditLimit(ctx context.Context, id int64, amount money.Money) error {
+	return svc.repo.AddAvailableCreditLimit(ctx, id, amount)
+}
+
+// ChangeStatus moves the account to the requested status, if the transition is allowed,
+// recording the transition with its reason.
+func (svc *accountsService) ChangeStatus(ctx context.Context, req *ChangeStatusRequest) (*Account, error) {
+	req.Reason = strings.TrimSpace(req.Reason)
+
+	if err := svc.validate.Struct(req); err != nil {
+		return nil, errorlib.ErrInvalidPayload(err)
+	}
+
+	var account *Account
+
+	err := svc.repo.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		if account, err = svc.repo.GetAccountByID(ctx, req.AccountID); err != nil {
+			return err
+		}
+
+		from := account.Status
+		if !from.CanTransitionTo(req.Status) {
+			return ErrInvalidStatusTransition(nil)
+		}
+
+		account.Status = req.Status
+		account.UpdatedAt = time.Now()
+
+		err = svc.repo.UpdateAccountStatus(ctx, account, from)
+		if errors.Is(err, errorlib.ErrNotFound(nil)) {
+			// the status was changed by a concurrent request
+			return ErrInvalidStatusTransition(err)
+		} else if err != nil {
+			return err
+		}
+
+		transition := &StatusTransition{
+			AccountID:  account.ID,
+			FromStatus: from,
+			ToStatus:   account.Status,
+			Reason:     req.Reason,
+			CreatedAt:  account.UpdatedAt,
+		}
+
+		if err := svc.repo.CreateStatusTransition(ctx, transition); err != nil {
+			return err
+		}
+
+		event, err := outbox.NewEvent(
+			AccountStatusChangedEvent,
+			account.ID,
+			NewStatusTransitionAPIResponseFromEntity(transition),
+		)
+		if err != nil {
+			return err
+		}
+
+		return svc.events.AddEvent(ctx, event)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (svc *accountsService) ListStatusTransitions(ctx context.Context, accountID int64) ([]*StatusTransition, error) {
+	if _, err := svc.repo.GetAccountByID(ctx, accountID); err != nil {
+		return nil, err
+	}
+
+	return svc.repo.ListStatusTransitions(ctx, accountID)
+}
+
 func (svc *accountsService) cleanDocumentNumber(documentNumber string) string {
 	buf := bytes.NewBufferString("")
 
@@ -77,10 +454,41 @@ func (svc *accountsService) cleanDocumentNumber(documentNumber string) string {
 	return buf.String()
 }
 
-func (svc *accountsService) validateDocument(documentNumber string) error {
-	if brdoc.IsCPF(documentNumber) || brdoc.IsCNPJ(documentNumber) {
-		return nil
+func (svc *accountsService) validateDocument(documentNumber string) (DocumentType, error) {
+	if brdoc.IsCPF(documentNumber) {
+		return CPFDocumentType, nil
+	} else if brdoc.IsCNPJ(documentNumber) {
+		return CNPJDocumentType, nil
+	}
+
+	return "", ErrInvalidDocumentNumber(nil)
+}
+
+// validateHolderDates checks that people only have a birth date and companies an incorporation date.
+func (svc *accountsService) validateHolderDates(account *Account) error {
+	now := time.Now()
+
+	switch {
+	case account.BirthDate != nil && (account.DocumentType != CPFDocumentType || account.BirthDate.After(now)):
+		return ErrInvalidHolderDate(nil)
+	case account.IncorporationDate != nil &&
+		(account.DocumentType != CNPJDocumentType || account.IncorporationDate.After(now)):
+		return ErrInvalidHolderDate(nil)
+	}
+
+	return nil
+}
+
+// parseDate returns nil for empty dates, the layout is already checked by the validator.
+func parseDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil //nolint:nilnil // empty date
+	}
+
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return nil, err
 	}
 
-	return ErrInvalidDocumentNumber(nil)
+	return &date, nil
 }
@@ -2,33 +2,46 @@ package accounts_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
 	mocks "github.com/rudineirk/pismo-challenge/pkg/domains/accounts/mocks"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/outbox"
+	outboxMocks "github.com/rudineirk/pismo-challenge/pkg/infra/outbox/mocks"
 	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/validation"
 	assert "github.com/stretchr/testify/require"
 
 	"go.uber.org/mock/gomock"
 )
 
+var defaultCreditLimit = money.MustParse("500") //nolint:gochecknoglobals // test config
+
 func TestCreateAccount(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	repo := mocks.NewMockRepository(mockCtrl)
-	svc := accounts.NewService(repo)
+	events := outboxMocks.NewMockStore(mockCtrl)
+	svc := accounts.NewService(repo, events, defaultCreditLimit)
 
 	for _, data := range [][]string{
-		{"23383829006", "233.838.290-06"},
-		{"23383829006", "23383829006"},
-		{"05677940000133", "05.677.940/0001-33"},
-		{"05677940000133", "05677940000133"},
-		{"05677940000133", "05.677.940.000133"},
+		{"23383829006", "233.838.290-06", "cpf"},
+		{"23383829006", "23383829006", "cpf"},
+		{"05677940000133", "05.677.940/0001-33", "cnpj"},
+		{"05677940000133", "05677940000133", "cnpj"},
+		{"05677940000133", "05.677.940.000133", "cnpj"},
 	} {
 		documentNumber := data[0]
 		input := data[1]
+		documentType := accounts.DocumentType(data[2])
+
+		expectRunInTx(repo)
 
 		repo.EXPECT().
 			CreateAccount(gomock.Any(), gomock.Any()).
@@ -37,11 +50,21 @@ func TestCreateAccount(t *testing.T) {
 			}).
 			Return(nil)
 
+		var event *outbox.Event
+		events.EXPECT().
+			AddEvent(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, savedEvent *outbox.Event) {
+				event = savedEvent
+			}).
+			Return(nil)
+
 		t.Run("should create a new account", func(t *testing.T) {
 			ctx := context.TODO()
 			now := time.Now()
+			creditLimit := money.MustParse("1500.5")
 			req := &accounts.CreateAccountRequest{
-				DocumentNumber: input,
+				DocumentNumber:       input,
+				AvailableCreditLimit: &creditLimit,
 			}
 
 			account, err := svc.CreateAccount(ctx, req)
@@ -49,24 +72,103 @@ func TestCreateAccount(t *testing.T) {
 
 			assert.Equal(t, int64(1), account.ID)
 			assert.Equal(t, documentNumber, account.DocumentNumber)
+			assert.Equal(t, documentType, account.DocumentType)
+			assert.Equal(t, creditLimit, account.AvailableCreditLimit)
+			assert.Equal(t, money.DefaultCurrency, account.Currency)
+			assert.Equal(t, accounts.DefaultClosingDay, account.ClosingDay)
+			assert.Equal(t, accounts.DefaultDueDay, account.DueDay)
+			assert.Equal(t, accounts.ActiveStatus, account.Status)
 			assert.WithinDuration(t, now, account.CreatedAt, 5*time.Millisecond)
 			assert.WithinDuration(t, now, account.UpdatedAt, 5*time.Millisecond)
+
+			assert.Equal(t, accounts.AccountCreatedEvent, event.Type)
+			assert.Equal(t, int64(1), event.AccountID)
+			assert.JSONEq(t, fmt.Sprintf(
+				`{"account_id":1,"document_type":%q,"available_credit_limit":1500.5,"currency":"BRL",`+
+					`"closing_day":25,"due_day":5,"status":"active","created_at":%q}`,
+				documentType,
+				account.CreatedAt.Format(time.RFC3339Nano),
+			), string(event.Payload))
+			assert.NotContains(t, string(event.Payload), documentNumber)
 		})
 	}
 }
 
+func TestCreateAccountCurrency(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	events := outboxMocks.NewMockStore(mockCtrl)
+	svc := accounts.NewService(repo, events, defaultCreditLimit)
+
+	t.Run("should create an account with the given currency", func(t *testing.T) {
+		ctx := context.TODO()
+		creditLimit := money.NewFromInt(100)
+
+		expectRunInTx(repo)
+		repo.EXPECT().CreateAccount(ctx, gomock.Any()).
+			Return(nil)
+		events.EXPECT().AddEvent(ctx, gomock.Any()).
+			Return(nil)
+
+		account, err := svc.CreateAccount(ctx, &accounts.CreateAccountRequest{
+			DocumentNumber:       "23383829006",
+			AvailableCreditLimit: &creditLimit,
+			Currency:             " usd",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "USD", account.Currency)
+	})
+
+	t.Run("should use the default credit limit if it's not present", func(t *testing.T) {
+		ctx := context.TODO()
+
+		expectRunInTx(repo)
+		repo.EXPECT().CreateAccount(ctx, gomock.Any()).
+			Return(nil)
+		events.EXPECT().AddEvent(ctx, gomock.Any()).
+			Return(nil)
+
+		account, err := svc.CreateAccount(ctx, &accounts.CreateAccountRequest{
+			DocumentNumber: "23383829006",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, defaultCreditLimit, account.AvailableCreditLimit)
+	})
+
+	t.Run("should return error if currency is invalid", func(t *testing.T) {
+		ctx := context.TODO()
+		creditLimit := money.NewFromInt(100)
+
+		_, err := svc.CreateAccount(ctx, &accounts.CreateAccountRequest{
+			DocumentNumber:       "23383829006",
+			AvailableCreditLimit: &creditLimit,
+			Currency:             "XYZ",
+		})
+		assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+		assert.Equal(t, []validation.FieldError{{
+			Field:   "currency",
+			Rule:    "iso4217",
+			Message: "currency must be an ISO 4217 currency code, like BRL",
+		}}, validation.Fields(err))
+	})
+}
+
 func TestCreateAccountInvalid(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	repo := mocks.NewMockRepository(mockCtrl)
-	svc := accounts.NewService(repo)
+	svc := accounts.NewService(repo, outboxMocks.NewMockStore(mockCtrl), defaultCreditLimit)
 
 	for _, documentNumber := range []string{"23383829007", "05677940000134", "abc123", "2338382900"} {
 		t.Run("should return error if document is invalid", func(t *testing.T) {
 			ctx := context.TODO()
+			creditLimit := money.NewFromInt(100)
 			req := &accounts.CreateAccountRequest{
-				DocumentNumber: documentNumber,
+				DocumentNumber:       documentNumber,
+				AvailableCreditLimit: &creditLimit,
 			}
 
 			_, err := svc.CreateAccount(ctx, req)
@@ -87,6 +189,31 @@ func TestCreateAccountInvalid(t *testing.T) {
 
 		repo.EXPECT().CreateAccount(nil, nil).Times(0)
 	})
+
+	for _, creditLimit := range []money.Money{money.MustParse("-1"), money.MustParse("-0.01")} {
+		t.Run("should return error if credit limit is negative", func(t *testing.T) {
+			ctx := context.TODO()
+			req := &accounts.CreateAccountRequest{
+				DocumentNumber:       "23383829006",
+				AvailableCreditLimit: &creditLimit,
+			}
+
+			_, err := svc.CreateAccount(ctx, req)
+			assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+		})
+	}
+
+	t.Run("should return error if credit limit has more than 2 decimal places", func(t *testing.T) {
+		ctx := context.TODO()
+		creditLimit := money.MustParse("10.001")
+		req := &accounts.CreateAccountRequest{
+			DocumentNumber:       "23383829006",
+			AvailableCreditLimit: &creditLimit,
+		}
+
+		_, err := svc.CreateAccount(ctx, req)
+		assert.ErrorIs(t, err, accounts.ErrInvalidCreditLimit(nil))
+	})
 }
 
 func TestGetAccountByID(t *testing.T) {
@@ -94,7 +221,7 @@ func TestGetAccountByID(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	repo := mocks.NewMockRepository(mockCtrl)
-	svc := accounts.NewService(repo)
+	svc := accounts.NewService(repo, outboxMocks.NewMockStore(mockCtrl), defaultCreditLimit)
 
 	ctx := context.TODO()
 	now := time.Now()
@@ -117,3 +244,567 @@ func TestGetAccountByID(t *testing.T) {
 	assert.Equal(t, now, result.CreatedAt)
 	assert.Equal(t, now, result.UpdatedAt)
 }
+
+func TestSearchAccounts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	svc := accounts.NewService(repo, outboxMocks.NewMockStore(mockCtrl), defaultCreditLimit)
+
+	for _, documentNumber := range []string{"233.838.290-06", "23383829006"} {
+		t.Run("should search by the clean document number", func(t *testing.T) {
+			ctx := context.TODO()
+
+			repo.EXPECT().
+				SearchAccounts(ctx, &accounts.AccountsFilter{
+					DocumentNumber: "23383829006",
+					Status:         accounts.ActiveStatus,
+					Limit:          51,
+				}).
+				Return([]*accounts.Account{{ID: 1, DocumentNumber: "23383829006"}}, nil)
+
+			page, err := svc.SearchAccounts(ctx, &accounts.SearchAccountsRequest{
+				DocumentNumber: documentNumber,
+				Status:         accounts.ActiveStatus,
+			})
+			assert.NoError(t, err)
+
+			assert.Len(t, page.Items, 1)
+			assert.Equal(t, int64(1), page.Items[0].ID)
+			assert.Empty(t, page.NextCursor)
+		})
+	}
+
+	t.Run("should return the next page cursor", func(t *testing.T) {
+		ctx := context.TODO()
+
+		repo.EXPECT().
+			SearchAccounts(ctx, &accounts.AccountsFilter{Email: "maria@example.com", Limit: 3}).
+			Return([]*accounts.Account{{ID: 1}, {ID: 4}, {ID: 7}}, nil)
+
+		page, err := svc.SearchAccounts(ctx, &accounts.SearchAccountsRequest{Email: "Maria@Example.com", Limit: 2})
+		assert.NoError(t, err)
+		assert.Len(t, page.Items, 2)
+		assert.NotEmpty(t, page.NextCursor)
+
+		repo.EXPECT().
+			SearchAccounts(ctx, &accounts.AccountsFilter{
+				Email: "maria@example.com",
+				After: &accounts.AccountsCursor{ID: 4},
+				Limit: 3,
+			}).
+			Return([]*accounts.Account{{ID: 7}}, nil)
+
+		page, err = svc.SearchAccounts(ctx, &accounts.SearchAccountsRequest{
+			Email:  "maria@example.com",
+			Cursor: page.NextCursor,
+			Limit:  2,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, page.Items, 1)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("should return error if filters are invalid", func(t *testing.T) {
+		ctx := context.TODO()
+
+		_, err := svc.SearchAccounts(ctx, &accounts.SearchAccountsRequest{DocumentNumber: "abc"})
+		assert.ErrorIs(t, err, accounts.ErrInvalidDocumentNumber(nil))
+
+		_, err = svc.SearchAccounts(ctx, &accounts.SearchAccountsRequest{Status: accounts.Status("deleted")})
+		assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+
+		_, err = svc.SearchAccounts(ctx, &accounts.SearchAccountsRequest{Limit: 101})
+		assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+
+		_, err = svc.SearchAccounts(ctx, &accounts.SearchAccountsRequest{Cursor: "not a cursor!"})
+		assert.ErrorIs(t, err, accounts.ErrInvalidCursor(nil))
+	})
+}
+
+func TestGetAccountBalance(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	svc := accounts.NewService(repo, outboxMocks.NewMockStore(mockCtrl), defaultCreditLimit)
+
+	t.Run("should return the account balance", func(t *testing.T) {
+		ctx := context.TODO()
+		balance := &accounts.Balance{
+			AccountID:        1,
+			Balance:          money.MustParse("-15.5"),
+			TotalPurchases:   money.MustParse("-20"),
+			TotalWithdrawals: money.MustParse("-5.5"),
+			TotalPayments:    money.MustParse("10"),
+			OperationTypes: []*accounts.OperationTypeBalance{
+				{OperationTypeID: operationtypes.CashPurchaseType, Total: money.MustParse("-20")},
+				{OperationTypeID: operationtypes.WithdrawType, Total: money.MustParse("-5.5")},
+				{OperationTypeID: operationtypes.PaymentType, Total: money.MustParse("10")},
+			},
+		}
+
+		repo.EXPECT().GetAccountByID(ctx, int64(1)).
+			Return(&accounts.Account{ID: 1}, nil)
+		repo.EXPECT().GetAccountBalance(ctx, int64(1)).
+			Return(balance, nil)
+
+		result, err := svc.GetAccountBalance(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, balance, result)
+	})
+
+	t.Run("should return error if account is not found", func(t *testing.T) {
+		ctx := context.TODO()
+
+		repo.EXPECT().GetAccountByID(ctx, int64(2)).
+			Return(nil, errorlib.ErrNotFound(nil))
+
+		_, err := svc.GetAccountBalance(ctx, 2)
+		assert.ErrorIs(t, err, errorlib.ErrNotFound(nil))
+	})
+}
+
+func TestUpdateAvailableCreditLimit(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	svc := accounts.NewService(repo, outboxMocks.NewMockStore(mockCtrl), defaultCreditLimit)
+
+	t.Run("should update the available credit limit", func(t *testing.T) {
+		ctx := context.TODO()
+		now := time.Now()
+		creditLimit := money.MustParse("2500.75")
+
+		repo.EXPECT().GetAccountByID(ctx, int64(1)).
+			Return(&accounts.Account{ID: 1, AvailableCreditLimit: money.MustParse("100")}, nil)
+		repo.EXPECT().UpdateAvailableCreditLimit(ctx, gomock.Any()).
+			Return(nil)
+
+		account, err := svc.UpdateAvailableCreditLimit(ctx, &accounts.UpdateCreditLimitRequest{
+			AccountID:            1,
+			AvailableCreditLimit: &creditLimit,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, creditLimit, account.AvailableCreditLimit)
+		assert.WithinDuration(t, now, account.UpdatedAt, 5*time.Millisecond)
+	})
+
+	t.Run("should return error if credit limit is invalid", func(t *testing.T) {
+		ctx := context.TODO()
+		negativeLimit := money.NewFromInt(-10)
+		invalidLimit := money.MustParse("1.001")
+
+		_, err := svc.UpdateAvailableCreditLimit(ctx, &accounts.UpdateCreditLimitRequest{AccountID: 1})
+		assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+
+		_, err = svc.UpdateAvailableCreditLimit(ctx, &accounts.UpdateCreditLimitRequest{
+			AccountID:            1,
+			AvailableCreditLimit: &negativeLimit,
+		})
+		assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+
+		_, err = svc.UpdateAvailableCreditLimit(ctx, &accounts.UpdateCreditLimitRequest{
+			AccountID:            1,
+			AvailableCreditLimit: &invalidLimit,
+		})
+		assert.ErrorIs(t, err, accounts.ErrInvalidCreditLimit(nil))
+	})
+
+	t.Run("should return error if account is not found", func(t *testing.T) {
+		ctx := context.TODO()
+		creditLimit := money.NewFromInt(10)
+
+		repo.EXPECT().GetAccountByID(ctx, int64(2)).
+			Return(nil, errorlib.ErrNotFound(nil))
+
+		_, err := svc.UpdateAvailableCreditLimit(ctx, &accounts.UpdateCreditLimitRequest{
+			AccountID:            2,
+			AvailableCreditLimit: &creditLimit,
+		})
+		assert.ErrorIs(t, err, errorlib.ErrNotFound(nil))
+	})
+}
+
+func TestCreateAccountHolder(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	events := outboxMocks.NewMockStore(mockCtrl)
+	svc := accounts.NewService(repo, events, defaultCreditLimit)
+
+	t.Run("should save the holder data", func(t *testing.T) {
+		ctx := context.TODO()
+		creditLimit := money.NewFromInt(100)
+
+		expectRunInTx(repo)
+		repo.EXPECT().CreateAccount(ctx, gomock.Any()).Return(nil)
+		events.EXPECT().AddEvent(ctx, gomock.Any()).Return(nil)
+
+		account, err := svc.CreateAccount(ctx, &accounts.CreateAccountRequest{
+			DocumentNumber:       "23383829006",
+			AvailableCreditLimit: &creditLimit,
+			HolderName:           " Maria Silva ",
+			Email:                "Maria@Example.com",
+			Phone:                "+5511987654321",
+			BirthDate:            "1990-05-20",
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "Maria Silva", account.HolderName)
+		assert.Equal(t, "maria@example.com", account.Email)
+		assert.Equal(t, "+5511987654321", account.Phone)
+		assert.Equal(t, time.Date(1990, 5, 20, 0, 0, 0, 0, time.UTC), *account.BirthDate)
+		assert.Nil(t, account.IncorporationDate)
+	})
+
+	testCases := []struct {
+		name string
+		req  accounts.CreateAccountRequest
+		err  error
+	}{
+		{
+			name: "should return error if email is invalid",
+			req:  accounts.CreateAccountRequest{DocumentNumber: "23383829006", Email: "maria"},
+			err:  errorlib.ErrInvalidPayload(nil),
+		},
+		{
+			name: "should return error if phone is invalid",
+			req:  accounts.CreateAccountRequest{DocumentNumber: "23383829006", Phone: "11 98765-4321"},
+			err:  errorlib.ErrInvalidPayload(nil),
+		},
+		{
+			name: "should return error if date format is invalid",
+			req:  accounts.CreateAccountRequest{DocumentNumber: "23383829006", BirthDate: "20/05/1990"},
+			err:  errorlib.ErrInvalidPayload(nil),
+		},
+		{
+			name: "should return error if a CPF has an incorporation date",
+			req:  accounts.CreateAccountRequest{DocumentNumber: "23383829006", IncorporationDate: "2010-01-01"},
+			err:  accounts.ErrInvalidHolderDate(nil),
+		},
+		{
+			name: "should return error if a CNPJ has a birth date",
+			req:  accounts.CreateAccountRequest{DocumentNumber: "05677940000133", BirthDate: "1990-05-20"},
+			err:  accounts.ErrInvalidHolderDate(nil),
+		},
+		{
+			name: "should return error if the date is in the future",
+			req: accounts.CreateAccountRequest{
+				DocumentNumber: "23383829006",
+				BirthDate:      time.Now().AddDate(0, 0, 2).Format("2006-01-02"),
+			},
+			err: accounts.ErrInvalidHolderDate(nil),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			creditLimit := money.NewFromInt(100)
+			testCase.req.AvailableCreditLimit = &creditLimit
+
+			_, err := svc.CreateAccount(context.TODO(), &testCase.req)
+			assert.ErrorIs(t, err, testCase.err)
+		})
+	}
+}
+
+func TestUpdateAccount(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	svc := accounts.NewService(repo, outboxMocks.NewMockStore(mockCtrl), defaultCreditLimit)
+
+	newAccount := func() *accounts.Account {
+		return &accounts.Account{
+			ID:             1,
+			DocumentNumber: "23383829006",
+			DocumentType:   accounts.CPFDocumentType,
+			HolderName:     "Maria Silva",
+			Email:          "maria@example.com",
+		}
+	}
+
+	t.Run("should only update the fields present in the request", func(t *testing.T) {
+		ctx := context.TODO()
+		now := time.Now()
+		phone := "+5511987654321"
+		birthDate := "1990-05-20"
+
+		// the account is read after the lock, with the changes of the concurrent updates
+		expectRunInTx(repo)
+		gomock.InOrder(
+			repo.EXPECT().LockAccount(ctx, int64(1)).Return(nil),
+			repo.EXPECT().GetAccountByID(ctx, int64(1)).Return(newAccount(), nil),
+			repo.EXPECT().UpdateAccountHolder(ctx, gomock.Any()).Return(nil),
+		)
+
+		account, err := svc.UpdateAccount(ctx, &accounts.UpdateAccountRequest{
+			AccountID: 1,
+			Phone:     &phone,
+			BirthDate: &birthDate,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "Maria Silva", account.HolderName)
+		assert.Equal(t, "maria@example.com", account.Email)
+		assert.Equal(t, phone, account.Phone)
+		assert.Equal(t, time.Date(1990, 5, 20, 0, 0, 0, 0, time.UTC), *account.BirthDate)
+		assert.WithinDuration(t, now, account.UpdatedAt, 5*time.Millisecond)
+	})
+
+	t.Run("should accept the same document number", func(t *testing.T) {
+		ctx := context.TODO()
+		documentNumber := "233.838.290-06"
+
+		expectRunInTx(repo)
+		repo.EXPECT().LockAccount(ctx, int64(1)).Return(nil)
+		repo.EXPECT().GetAccountByID(ctx, int64(1)).Return(newAccount(), nil)
+		repo.EXPECT().UpdateAccountHolder(ctx, gomock.Any()).Return(nil)
+
+		_, err := svc.UpdateAccount(ctx, &accounts.UpdateAccountRequest{
+			AccountID:      1,
+			DocumentNumber: &documentNumber,
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return error if document number is changed", func(t *testing.T) {
+		ctx := context.TODO()
+		documentNumber := "05677940000133"
+
+		expectRunInTx(repo)
+		repo.EXPECT().LockAccount(ctx, int64(1)).Return(nil)
+		repo.EXPECT().GetAccountByID(ctx, int64(1)).Return(newAccount(), nil)
+
+		_, err := svc.UpdateAccount(ctx, &accounts.UpdateAccountRequest{
+			AccountID:      1,
+			DocumentNumber: &documentNumber,
+		})
+		assert.ErrorIs(t, err, accounts.ErrImmutableDocumentNumber(nil))
+	})
+
+	t.Run("should return error if payload is invalid", func(t *testing.T) {
+		ctx := context.TODO()
+		emptyName := " "
+		invalidEmail := "maria"
+
+		_, err := svc.UpdateAccount(ctx, &accounts.UpdateAccountRequest{AccountID: 1, HolderName: &emptyName})
+		assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+
+		_, err = svc.UpdateAccount(ctx, &accounts.UpdateAccountRequest{AccountID: 1, Email: &invalidEmail})
+		assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+	})
+
+	t.Run("should return error if a CPF receives an incorporation date", func(t *testing.T) {
+		ctx := context.TODO()
+		incorporationDate := "2010-01-01"
+
+		expectRunInTx(repo)
+		repo.EXPECT().LockAccount(ctx, int64(1)).Return(nil)
+		repo.EXPECT().GetAccountByID(ctx, int64(1)).Return(newAccount(), nil)
+
+		_, err := svc.UpdateAccount(ctx, &accounts.UpdateAccountRequest{
+			AccountID:         1,
+			IncorporationDate: &incorporationDate,
+		})
+		assert.ErrorIs(t, err, accounts.ErrInvalidHolderDate(nil))
+	})
+
+	t.Run("should return error if account is not found", func(t *testing.T) {
+		ctx := context.TODO()
+		name := "Maria"
+
+		expectRunInTx(repo)
+		repo.EXPECT().LockAccount(ctx, int64(2)).Return(errorlib.ErrNotFound(nil))
+
+		_, err := svc.UpdateAccount(ctx, &accounts.UpdateAccountRequest{AccountID: 2, HolderName: &name})
+		assert.ErrorIs(t, err, errorlib.ErrNotFound(nil))
+	})
+}
+
+func TestStatusCanTransitionTo(t *testing.T) {
+	testCases := []struct {
+		from     accounts.Status
+		to       accounts.Status
+		expected bool
+	}{
+		{accounts.ActiveStatus, accounts.BlockedStatus, true},
+		{accounts.ActiveStatus, accounts.SuspendedStatus, true},
+		{accounts.ActiveStatus, accounts.ClosedStatus, true},
+		{accounts.ActiveStatus, accounts.ActiveStatus, false},
+		{accounts.BlockedStatus, accounts.ActiveStatus, true},
+		{accounts.BlockedStatus, accounts.SuspendedStatus, false},
+		{accounts.SuspendedStatus, accounts.ActiveStatus, true},
+		{accounts.SuspendedStatus, accounts.ClosedStatus, true},
+		{accounts.ClosedStatus, accounts.ActiveStatus, false},
+		{accounts.ClosedStatus, accounts.BlockedStatus, false},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(fmt.Sprintf("%s to %s", testCase.from, testCase.to), func(t *testing.T) {
+			assert.Equal(t, testCase.expected, testCase.from.CanTransitionTo(testCase.to))
+		})
+	}
+}
+
+func TestChangeStatus(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	events := outboxMocks.NewMockStore(mockCtrl)
+	svc := accounts.NewService(repo, events, defaultCreditLimit)
+
+	t.Run("should change the status and record the transition", func(t *testing.T) {
+		ctx := context.TODO()
+		now := time.Now()
+
+		expectRunInTx(repo)
+		repo.EXPECT().GetAccountByID(ctx, int64(1)).
+			Return(&accounts.Account{ID: 1, Status: accounts.ActiveStatus}, nil)
+		repo.EXPECT().UpdateAccountStatus(ctx, gomock.Any(), accounts.ActiveStatus).
+			Return(nil)
+
+		var transition *accounts.StatusTransition
+		repo.EXPECT().
+			CreateStatusTransition(ctx, gomock.Any()).
+			Do(func(_ context.Context, savedTransition *accounts.StatusTransition) {
+				transition = savedTransition
+			}).
+			Return(nil)
+
+		var event *outbox.Event
+		events.EXPECT().
+			AddEvent(ctx, gomock.Any()).
+			Do(func(_ context.Context, savedEvent *outbox.Event) {
+				event = savedEvent
+			}).
+			Return(nil)
+
+		account, err := svc.ChangeStatus(ctx, &accounts.ChangeStatusRequest{
+			AccountID: 1,
+			Status:    accounts.BlockedStatus,
+			Reason:    " card lost ",
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, accounts.BlockedStatus, account.Status)
+		assert.WithinDuration(t, now, account.UpdatedAt, 5*time.Millisecond)
+
+		assert.Equal(t, int64(1), transition.AccountID)
+		assert.Equal(t, accounts.ActiveStatus, transition.FromStatus)
+		assert.Equal(t, accounts.BlockedStatus, transition.ToStatus)
+		assert.Equal(t, "card lost", transition.Reason)
+
+		assert.Equal(t, accounts.AccountStatusChangedEvent, event.Type)
+		assert.Equal(t, int64(1), event.AccountID)
+	})
+
+	t.Run("should return error if the transition is not allowed", func(t *testing.T) {
+		ctx := context.TODO()
+
+		expectRunInTx(repo)
+		repo.EXPECT().GetAccountByID(ctx, int64(1)).
+			Return(&accounts.Account{ID: 1, Status: accounts.ClosedStatus}, nil)
+
+		_, err := svc.ChangeStatus(ctx, &accounts.ChangeStatusRequest{
+			AccountID: 1,
+			Status:    accounts.ActiveStatus,
+			Reason:    "reopen",
+		})
+		assert.ErrorIs(t, err, accounts.ErrInvalidStatusTransition(nil))
+	})
+
+	t.Run("should return error if the status was changed concurrently", func(t *testing.T) {
+		ctx := context.TODO()
+
+		expectRunInTx(repo)
+		repo.EXPECT().GetAccountByID(ctx, int64(1)).
+			Return(&accounts.Account{ID: 1, Status: accounts.ActiveStatus}, nil)
+		repo.EXPECT().UpdateAccountStatus(ctx, gomock.Any(), accounts.ActiveStatus).
+			Return(errorlib.ErrNotFound(nil))
+
+		_, err := svc.ChangeStatus(ctx, &accounts.ChangeStatusRequest{
+			AccountID: 1,
+			Status:    accounts.ClosedStatus,
+			Reason:    "customer request",
+		})
+		assert.ErrorIs(t, err, accounts.ErrInvalidStatusTransition(nil))
+	})
+
+	t.Run("should return error if the payload is invalid", func(t *testing.T) {
+		ctx := context.TODO()
+
+		_, err := svc.ChangeStatus(ctx, &accounts.ChangeStatusRequest{
+			AccountID: 1,
+			Status:    accounts.BlockedStatus,
+			Reason:    "  ",
+		})
+		assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+
+		_, err = svc.ChangeStatus(ctx, &accounts.ChangeStatusRequest{
+			AccountID: 1,
+			Status:    accounts.Status("deleted"),
+			Reason:    "test",
+		})
+		assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+	})
+
+	t.Run("should return error if account is not found", func(t *testing.T) {
+		ctx := context.TODO()
+
+		expectRunInTx(repo)
+		repo.EXPECT().GetAccountByID(ctx, int64(2)).
+			Return(nil, errorlib.ErrNotFound(nil))
+
+		_, err := svc.ChangeStatus(ctx, &accounts.ChangeStatusRequest{
+			AccountID: 2,
+			Status:    accounts.BlockedStatus,
+			Reason:    "test",
+		})
+		assert.ErrorIs(t, err, errorlib.ErrNotFound(nil))
+	})
+}
+
+func TestCreateAccountEvent(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	events := outboxMocks.NewMockStore(mockCtrl)
+	svc := accounts.NewService(repo, events, defaultCreditLimit)
+
+	t.Run("should return error if the event is not saved", func(t *testing.T) {
+		ctx := context.TODO()
+		creditLimit := money.NewFromInt(100)
+		dbErr := errors.New("db error")
+
+		expectRunInTx(repo)
+		repo.EXPECT().CreateAccount(ctx, gomock.Any()).
+			Return(nil)
+		events.EXPECT().AddEvent(ctx, gomock.Any()).
+			Return(dbErr)
+
+		_, err := svc.CreateAccount(ctx, &accounts.CreateAccountRequest{
+			DocumentNumber:       "23383829006",
+			AvailableCreditLimit: &creditLimit,
+		})
+		assert.ErrorIs(t, err, dbErr)
+	})
+}
+
+func expectRunInTx(repo *mocks.MockRepository) {
+	repo.EXPECT().
+		RunInTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+}
@@ -0,0 +1,101 @@
+package accounts
+
+import (
+	"context"
+
+	"github.com/rudineirk/pismo-challenge/pkg/infra/tracing"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type tracingService struct {
+	next Service
+}
+
+// NewTracingService wraps the service, creating a span for each call.
+func NewTracingService(service Service) Service {
+	return &tracingService{next: service}
+}
+
+func (svc *tracingService) CreateAccount(ctx context.Context, req *CreateAccountRequest) (*Account, error) {
+	ctx, span := tracing.StartSpan(ctx, "accounts.CreateAccount")
+	account, err := svc.next.CreateAccount(ctx, req)
+	tracing.EndSpan(span, err)
+
+	return account, err
+}
+
+func (svc *tracingService) GetAccountByID(ctx context.Context, id int64) (*Account, error) {
+	ctx, span := tracing.StartSpan(ctx, "accounts.GetAccountByID", attribute.Int64("account.id", id))
+	account, err := svc.next.GetAccountByID(ctx, id)
+	tracing.EndSpan(span, err)
+
+	return account, err
+}
+
+func (svc *tracingService) ListAccounts(ctx context.Context, afterID int64, limit int) ([]*Account, error) {
+	ctx, span := tracing.StartSpan(ctx, "accounts.ListAccounts")
+	accounts, err := svc.next.ListAccounts(ctx, afterID, limit)
+	tracing.EndSpan(span, err)
+
+	return accounts, err
+}
+
+func (svc *tracingService) SearchAccounts(ctx context.Context, req *SearchAccountsRequest) (*AccountsPage, error) {
+	ctx, span := tracing.StartSpan(ctx, "accounts.SearchAccounts")
+	page, err := svc.next.SearchAccounts(ctx, req)
+	tracing.EndSpan(span, err)
+
+	return page, err
+}
+
+func (svc *tracingService) GetAccountBalance(ctx context.Context, id int64) (*Balance, error) {
+	ctx, span := tracing.StartSpan(ctx, "accounts.GetAccountBalance", attribute.Int64("account.id", id))
+	balance, err := svc.next.GetAccountBalance(ctx, id)
+	tracing.EndSpan(span, err)
+
+	return balance, err
+}
+
+func (svc *tracingService) UpdateAvailableCreditLimit(
+	ctx context.Context,
+	req *UpdateCreditLimitRequest,
+) (*Account, error) {
+	ctx, span := tracing.StartSpan(ctx, "accounts.UpdateAvailableCreditLimit")
+	account, err := svc.next.UpdateAvailableCreditLimit(ctx, req)
+	tracing.EndSpan(span, err)
+
+	return account, err
+}
+
+func (svc *tracingService) UpdateAccount(ctx context.Context, req *UpdateAccountRequest) (*Account, error) {
+	ctx, span := tracing.StartSpan(ctx, "accounts.UpdateAccount", attribute.Int64("account.id", req.AccountID))
+	account, err := svc.next.UpdateAccount(ctx, req)
+	tracing.EndSpan(span, err)
+
+	return account, err
+}
+
+func (svc *tracingService) AddAvailableCreditLimit(ctx context.Context, id int64, amount money.Money) error {
+	ctx, span := tracing.StartSpan(ctx, "accounts.AddAvailableCreditLimit", attribute.Int64("account.id", id))
+	err := svc.next.AddAvailableCreditLimit(ctx, id, amount)
+	tracing.EndSpan(span, err)
+
+	return err
+}
+
+func (svc *tracingService) ChangeStatus(ctx context.Context, req *ChangeStatusRequest) (*Account, error) {
+	ctx, span := tracing.StartSpan(ctx, "accounts.ChangeStatus", attribute.Int64("account.id", req.AccountID))
+	account, err := svc.next.ChangeStatus(ctx, req)
+	tracing.EndSpan(span, err)
+
+	return account, err
+}
+
+func (svc *tracingService) ListStatusTransitions(ctx context.Context, accountID int64) ([]*StatusTransition, error) {
+	ctx, span := tracing.StartSpan(ctx, "accounts.ListStatusTransitions", attribute.Int64("account.id", accountID))
+	transitions, err := svc.next.ListStatusTransitions(ctx, accountID)
+	tracing.EndSpan(span, err)
+
+	return transitions, err
+}
@@ -0,0 +1,82 @@
+package accruals
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/httprouter"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+)
+
+type httpHandler struct {
+	service Service
+}
+
+func SetupHTTPRoutes(router *gin.Engine, service Service, middlewares ...gin.HandlerFunc) {
+	handler := httpHandler{
+		service: service,
+	}
+
+	adminGroup := router.Group("/admin/accruals", middlewares...)
+	adminGroup.POST("", handler.Accrue)
+}
+
+func (handler *httpHandler) Accrue(ctx *gin.Context) {
+	req := AccrueRequest{}
+	if err := httprouter.BindJSON(ctx, &req); err != nil {
+		return
+	}
+
+	report, err := handler.service.Accrue(ctx, &req)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, NewReportAPIResponseFromEntity(report))
+}
+
+type AccrualAPIResponse struct {
+	AccountID      int64       `json:"account_id,omitempty"`
+	StatementID    int64       `json:"statement_id,omitempty"`
+	OverdueBalance money.Money `json:"overdue_balance"`
+	Interest       money.Money `json:"interest"`
+	LateFee        money.Money `json:"late_fee"`
+	Penalty        money.Money `json:"penalty"`
+	Total          money.Money `json:"total"`
+}
+
+func NewAccrualAPIResponseFromEntity(accrual *Accrual) *AccrualAPIResponse {
+	return &AccrualAPIResponse{
+		AccountID:      accrual.AccountID,
+		StatementID:    accrual.StatementID,
+		OverdueBalance: accrual.OverdueBalance,
+		Interest:       accrual.Interest,
+		LateFee:        accrual.LateFee,
+		Penalty:        accrual.Penalty,
+		Total:          accrual.Total(),
+	}
+}
+
+type ReportAPIResponse struct {
+	AccrualDate    string                `json:"accrual_date"`
+	DryRun         bool                  `json:"dry_run"`
+	AlreadyAccrued int                   `json:"already_accrued"`
+	Accruals       []*AccrualAPIResponse `json:"accruals"`
+	Totals         *AccrualAPIResponse   `json:"totals"`
+}
+
+func NewReportAPIResponseFromEntity(report *Report) *ReportAPIResponse {
+	accruals := make([]*AccrualAPIResponse, 0, len(report.Accruals))
+	for _, accrual := range report.Accruals {
+		accruals = append(accruals, NewAccrualAPIResponseFromEntity(accrual))
+	}
+
+	return &ReportAPIResponse{
+		AccrualDate:    report.AccrualDate.Format(dateLayout),
+		DryRun:         report.DryRun,
+		AlreadyAccrued: report.AlreadyAccrued,
+		Accruals:       accruals,
+		Totals:         NewAccrualAPIResponseFromEntity(report.Totals()),
+	}
+}
@@ -0,0 +1,93 @@
+package accruals
+
+import (
+	"time"
+
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	daysPerMonth       = 30
+	dailyRatePrecision = 10
+	rootPrecision      = 20
+	maxRootIterations  = 100
+)
+
+// Policy has the charges applied to the overdue balances.
+type Policy struct {
+	MonthlyInterestRate decimal.Decimal
+	LateFee             money.Money
+	PenaltyRate         decimal.Decimal
+}
+
+// DailyInterestRate is the daily rate equivalent to the monthly rate when compounded daily.
+func (policy Policy) DailyInterestRate() decimal.Decimal {
+	one := decimal.NewFromInt(1)
+	dailyRate := nthRoot(one.Add(policy.MonthlyInterestRate), daysPerMonth).Sub(one)
+
+	return dailyRate.Round(dailyRatePrecision)
+}
+
+// nthRoot calculates the root with the Newton's method, with more digits than the rate
+// precision, so the rounded rate is exact.
+func nthRoot(value decimal.Decimal, degree int64) decimal.Decimal {
+	root := decimal.NewFromInt(1)
+	exponent := decimal.NewFromInt(degree - 1)
+
+	for idx := 0; idx < maxRootIterations; idx++ {
+		power := root.Pow(exponent).Round(rootPrecision)
+		step := power.Mul(root).Sub(value).DivRound(power.Mul(decimal.NewFromInt(degree)), rootPrecision)
+
+		if step.IsZero() {
+			break
+		}
+
+		root = root.Sub(step)
+	}
+
+	return root
+}
+
+// Accrual has the charges posted to an account in a day, the interest is charged
+// every day, while the late fee and penalty only on the first day the statement is overdue.
+type Accrual struct {
+	ID             int64
+	AccountID      int64
+	StatementID    int64
+	AccrualDate    time.Time
+	OverdueBalance money.Money
+	Interest       money.Money
+	LateFee        money.Money
+	Penalty        money.Money
+	CreatedAt      time.Time
+}
+
+func (accrual *Accrual) Total() money.Money {
+	return accrual.Interest.Add(accrual.LateFee).Add(accrual.Penalty)
+}
+
+type Report struct {
+	AccrualDate    time.Time
+	DryRun         bool
+	AlreadyAccrued int
+	Accruals       []*Accrual
+}
+
+func (report *Report) Totals() *Accrual {
+	totals := &Accrual{AccrualDate: report.AccrualDate}
+
+	for _, accrual := range report.Accruals {
+		totals.OverdueBalance = totals.OverdueBalance.Add(accrual.OverdueBalance)
+		totals.Interest = totals.Interest.Add(accrual.Interest)
+		totals.LateFee = totals.LateFee.Add(accrual.LateFee)
+		totals.Penalty = totals.Penalty.Add(accrual.Penalty)
+	}
+
+	return totals
+}
+
+func truncateDate(date time.Time) time.Time {
+	date = date.UTC()
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,54 @@
+package accruals
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Job accrues the overdue accounts periodically until the context is canceled,
+// the accruals are idempotent per day, so it can run more than once a day.
+type Job struct {
+	service  Service
+	logger   *zerolog.Logger
+	interval time.Duration
+	dryRun   bool
+}
+
+func NewJob(service Service, logger *zerolog.Logger, interval time.Duration, dryRun bool) *Job {
+	return &Job{
+		service:  service,
+		logger:   logger,
+		interval: interval,
+		dryRun:   dryRun,
+	}
+}
+
+func (job *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+
+	for {
+		report, err := job.service.Accrue(ctx, &AccrueRequest{DryRun: job.dryRun})
+		if err != nil && ctx.Err() == nil {
+			job.logger.Err(err).Msg("Failed to accrue the overdue accounts")
+		} else if report != nil && (len(report.Accruals) > 0 || job.dryRun) {
+			totals := report.Totals()
+
+			job.logger.Info().
+				Bool("dry_run", report.DryRun).
+				Int("accounts", len(report.Accruals)).
+				Stringer("interest", totals.Interest).
+				Stringer("late_fee", totals.LateFee).
+				Stringer("penalty", totals.Penalty).
+				Msg("Overdue accounts accrued")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/domains/accruals/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source ./pkg/domains/accruals/repository.go -destination ./pkg/domains/accruals/mocks/repository_mock.go
+//
+// Package mock_accruals is a generated GoMock package.
+package mock_accruals
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	accruals "github.com/rudineirk/pismo-challenge/pkg/domains/accruals"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateAccrual mocks base method.
+func (m *MockRepository) CreateAccrual(arg0 context.Context, arg1 *accruals.Accrual) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccrual", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAccrual indicates an expected call of CreateAccrual.
+func (mr *MockRepositoryMockRecorder) CreateAccrual(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccrual", reflect.TypeOf((*MockRepository)(nil).CreateAccrual), arg0, arg1)
+}
+
+// GetAccrual mocks base method.
+func (m *MockRepository) GetAccrual(ctx context.Context, accountID int64, date time.Time) (*accruals.Accrual, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccrual", ctx, accountID, date)
+	ret0, _ := ret[0].(*accruals.Accrual)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccrual indicates an expected call of GetAccrual.
+func (mr *MockRepositoryMockRecorder) GetAccrual(ctx, accountID, date any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccrual", reflect.TypeOf((*MockRepository)(nil).GetAccrual), ctx, accountID, date)
+}
+
+// HasStatementAccrual mocks base method.
+func (m *MockRepository) HasStatementAccrual(ctx context.Context, statementID int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasStatementAccrual", ctx, statementID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasStatementAccrual indicates an expected call of HasStatementAccrual.
+func (mr *MockRepositoryMockRecorder) HasStatementAccrual(ctx, statementID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasStatementAccrual", reflect.TypeOf((*MockRepository)(nil).HasStatementAccrual), ctx, statementID)
+}
+
+// RunInTx mocks base method.
+func (m *MockRepository) RunInTx(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunInTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunInTx indicates an expected call of RunInTx.
+func (mr *MockRepositoryMockRecorder) RunInTx(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunInTx", reflect.TypeOf((*MockRepository)(nil).RunInTx), ctx, fn)
+}
@@ -0,0 +1,117 @@
+package accruals
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rudineirk/pismo-challenge/pkg/infra/database"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/uptrace/bun"
+)
+
+type Repository interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+	CreateAccrual(context.Context, *Accrual) error
+	GetAccrual(ctx context.Context, accountID int64, date time.Time) (*Accrual, error)
+	HasStatementAccrual(ctx context.Context, statementID int64) (bool, error)
+}
+
+type AccrualModel struct {
+	bun.BaseModel  `bun:"table:accruals"`
+	ID             int64       `bun:"id,pk,autoincrement"`
+	AccountID      int64       `bun:"account_id"`
+	StatementID    int64       `bun:"statement_id"`
+	AccrualDate    time.Time   `bun:"accrual_date,type:date"`
+	OverdueBalance money.Money `bun:"overdue_balance"`
+	Interest       money.Money `bun:"interest"`
+	LateFee        money.Money `bun:"late_fee"`
+	Penalty        money.Money `bun:"penalty"`
+	CreatedAt      time.Time   `bun:"created_at"`
+}
+
+func NewModelFromEntity(accrual *Accrual) *AccrualModel {
+	return &AccrualModel{
+		ID:             accrual.ID,
+		AccountID:      accrual.AccountID,
+		StatementID:    accrual.StatementID,
+		AccrualDate:    accrual.AccrualDate,
+		OverdueBalance: accrual.OverdueBalance,
+		Interest:       accrual.Interest,
+		LateFee:        accrual.LateFee,
+		Penalty:        accrual.Penalty,
+		CreatedAt:      accrual.CreatedAt,
+	}
+}
+
+func (model *AccrualModel) ToEntity() *Accrual {
+	return &Accrual{
+		ID:             model.ID,
+		AccountID:      model.AccountID,
+		StatementID:    model.StatementID,
+		AccrualDate:    model.AccrualDate.UTC(),
+		OverdueBalance: model.OverdueBalance,
+		Interest:       model.Interest,
+		LateFee:        model.LateFee,
+		Penalty:        model.Penalty,
+		CreatedAt:      model.CreatedAt,
+	}
+}
+
+type dbRepository struct {
+	bunDB *bun.DB
+}
+
+func NewRepository(bunDB *bun.DB) Repository {
+	return &dbRepository{bunDB}
+}
+
+func (repo *dbRepository) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.RunInTx(ctx, repo.bunDB, fn)
+}
+
+func (repo *dbRepository) CreateAccrual(ctx context.Context, accrual *Accrual) error {
+	accrualModel := NewModelFromEntity(accrual)
+
+	_, err := database.Conn(ctx, repo.bunDB).NewInsert().
+		Model(accrualModel).
+		Exec(ctx)
+
+	if err != nil && strings.Contains(err.Error(), "unique constraint") {
+		return errorlib.ErrDuplicated(err)
+	} else if err != nil {
+		return err
+	}
+
+	accrual.ID = accrualModel.ID
+
+	return nil
+}
+
+func (repo *dbRepository) GetAccrual(ctx context.Context, accountID int64, date time.Time) (*Accrual, error) {
+	accrualModel := AccrualModel{}
+
+	err := database.Conn(ctx, repo.bunDB).NewSelect().
+		Model(&accrualModel).
+		Where("account_id = ?", accountID).
+		Where("accrual_date = ?", date).
+		Scan(ctx)
+
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return nil, errorlib.ErrNotFound(err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return accrualModel.ToEntity(), nil
+}
+
+func (repo *dbRepository) HasStatementAccrual(ctx context.Context, statementID int64) (bool, error) {
+	return database.Conn(ctx, repo.bunDB).NewSelect().
+		Model((*AccrualModel)(nil)).
+		Where("statement_id = ?", statementID).
+		Exists(ctx)
+}
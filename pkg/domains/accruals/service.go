@@ -0,0 +1,219 @@
+package accruals
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/statements"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/transactions"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/validation"
+)
+
+const (
+	accountsBatchSize = 100
+	dateLayout        = "2006-01-02"
+)
+
+var ErrInvalidAccrualDate = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"invalid_accrual_date",
+	"accrual date can't be in the future",
+	http.StatusBadRequest,
+)
+
+type Service interface {
+	Accrue(context.Context, *AccrueRequest) (*Report, error)
+}
+
+type AccrueRequest struct {
+	Date   string `json:"date"    validate:"omitempty,datetime=2006-01-02"`
+	DryRun bool   `json:"dry_run"`
+}
+
+type accrualsService struct {
+	repo            Repository
+	accountsSvc     accounts.Service
+	statementsSvc   statements.Service
+	transactionsSvc transactions.Service
+	policy          Policy
+	validate        *validator.Validate
+}
+
+func NewService(
+	repo Repository,
+	accountsSvc accounts.Service,
+	statementsSvc statements.Service,
+	transactionsSvc transactions.Service,
+	policy Policy,
+) Service {
+	return &accrualsService{
+		repo:            repo,
+		accountsSvc:     accountsSvc,
+		statementsSvc:   statementsSvc,
+		transactionsSvc: transactionsSvc,
+		policy:          policy,
+		validate:        validation.New(),
+	}
+}
+
+// Accrue posts the interest and late charges of the overdue accounts for the given date (today by default).
+// Each account is accrued only once per day, in dry run mode the report is returned without posting the charges.
+// The charges are posted with the current date, so the accruals of past dates are billed in the open cycle
+// instead of changing the cycles already closed.
+func (svc *accrualsService) Accrue(ctx context.Context, req *AccrueRequest) (*Report, error) {
+	if err := svc.validate.Struct(req); err != nil {
+		return nil, errorlib.ErrInvalidPayload(err)
+	}
+
+	today := truncateDate(time.Now())
+	date := today
+
+	if req.Date != "" {
+		date, _ = time.Parse(dateLayout, req.Date)
+		if date.After(today) {
+			return nil, ErrInvalidAccrualDate(nil)
+		}
+	}
+
+	report := &Report{
+		AccrualDate: date,
+		DryRun:      req.DryRun,
+		Accruals:    []*Accrual{},
+	}
+
+	afterID := int64(0)
+
+	for {
+		accountsBatch, err := svc.accountsSvc.ListAccounts(ctx, afterID, accountsBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, account := range accountsBatch {
+			if err := svc.accrueAccount(ctx, report, account.ID); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(accountsBatch) < accountsBatchSize {
+			return report, nil
+		}
+
+		afterID = accountsBatch[len(accountsBatch)-1].ID
+	}
+}
+
+func (svc *accrualsService) accrueAccount(ctx context.Context, report *Report, accountID int64) error {
+	_, err := svc.repo.GetAccrual(ctx, accountID, report.AccrualDate)
+	if err == nil {
+		report.AlreadyAccrued++
+		return nil
+	} else if !errors.Is(err, errorlib.ErrNotFound(nil)) {
+		return err
+	}
+
+	accrual, err := svc.calculateAccrual(ctx, accountID, report.AccrualDate)
+	if err != nil || accrual == nil {
+		return err
+	}
+
+	if report.DryRun {
+		report.Accruals = append(report.Accruals, accrual)
+		return nil
+	}
+
+	err = svc.repo.RunInTx(ctx, func(ctx context.Context) error {
+		if err := svc.repo.CreateAccrual(ctx, accrual); err != nil {
+			return err
+		}
+
+		return svc.postCharges(ctx, accrual)
+	})
+
+	if errors.Is(err, errorlib.ErrDuplicated(nil)) {
+		// accrued by another job instance
+		report.AlreadyAccrued++
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	report.Accruals = append(report.Accruals, accrual)
+
+	return nil
+}
+
+// calculateAccrual returns nil if the account has nothing to be charged in the date.
+func (svc *accrualsService) calculateAccrual(ctx context.Context, accountID int64, date time.Time) (*Accrual, error) {
+	overdue, err := svc.statementsSvc.GetOverdueBalance(ctx, accountID, date)
+	if errors.Is(err, errorlib.ErrNotFound(nil)) {
+		return nil, nil //nolint:nilnil // no due statements
+	} else if err != nil {
+		return nil, err
+	} else if !overdue.Amount.IsPositive() {
+		return nil, nil //nolint:nilnil // nothing overdue
+	}
+
+	accrual := &Accrual{
+		AccountID:      accountID,
+		StatementID:    overdue.Statement.ID,
+		AccrualDate:    date,
+		OverdueBalance: overdue.Amount,
+		Interest:       overdue.Amount.Convert(svc.policy.DailyInterestRate()),
+		LateFee:        money.NewFromInt(0),
+		Penalty:        money.NewFromInt(0),
+		CreatedAt:      time.Now(),
+	}
+
+	if !overdue.IsMinimumPaid() {
+		charged, err := svc.repo.HasStatementAccrual(ctx, overdue.Statement.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !charged {
+			accrual.LateFee = svc.policy.LateFee
+			accrual.Penalty = overdue.Amount.Convert(svc.policy.PenaltyRate)
+		}
+	}
+
+	if accrual.Total().IsZero() {
+		return nil, nil //nolint:nilnil // nothing to charge
+	}
+
+	return accrual, nil
+}
+
+func (svc *accrualsService) postCharges(ctx context.Context, accrual *Accrual) error {
+	charges := []struct {
+		opType operationtypes.Type
+		amount money.Money
+	}{
+		{operationtypes.InterestType, accrual.Interest},
+		{operationtypes.LateFeeType, accrual.LateFee},
+		{operationtypes.PenaltyType, accrual.Penalty},
+	}
+
+	for _, charge := range charges {
+		if charge.amount.IsZero() {
+			continue
+		}
+
+		_, err := svc.transactionsSvc.CreateSystemTransaction(ctx, &transactions.CreateTransactionRequest{
+			AccountID:       accrual.AccountID,
+			OperationTypeID: charge.opType,
+			Amount:          charge.amount.Neg(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
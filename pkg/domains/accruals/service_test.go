@@ -0,0 +1,285 @@
+package accruals_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
+	accountMocks "github.com/rudineirk/pismo-challenge/pkg/domains/accounts/mocks"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/accruals"
+	mocks "github.com/rudineirk/pismo-challenge/pkg/domains/accruals/mocks"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/statements"
+	statementMocks "github.com/rudineirk/pismo-challenge/pkg/domains/statements/mocks"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/transactions"
+	transactionMocks "github.com/rudineirk/pismo-challenge/pkg/domains/transactions/mocks"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/shopspring/decimal"
+	assert "github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+)
+
+var policy = accruals.Policy{ //nolint:gochecknoglobals // test config
+	MonthlyInterestRate: decimal.RequireFromString("0.12"),
+	LateFee:             money.MustParse("10"),
+	PenaltyRate:         decimal.RequireFromString("0.02"),
+}
+
+type accrualMocks struct {
+	repo            *mocks.MockRepository
+	accountsSvc     *accountMocks.MockService
+	statementsSvc   *statementMocks.MockService
+	transactionsSvc *transactionMocks.MockService
+}
+
+func setupMocks(t *testing.T) (accruals.Service, *accrualMocks) {
+	mockCtrl := gomock.NewController(t)
+	m := &accrualMocks{
+		repo:            mocks.NewMockRepository(mockCtrl),
+		accountsSvc:     accountMocks.NewMockService(mockCtrl),
+		statementsSvc:   statementMocks.NewMockService(mockCtrl),
+		transactionsSvc: transactionMocks.NewMockService(mockCtrl),
+	}
+
+	svc := accruals.NewService(m.repo, m.accountsSvc, m.statementsSvc, m.transactionsSvc, policy)
+
+	m.repo.EXPECT().
+		RunInTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).
+		AnyTimes()
+
+	return svc, m
+}
+
+func today() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func TestDailyInterestRate(t *testing.T) {
+	rate := policy.DailyInterestRate()
+	assert.Equal(t, "0.0037847671", rate.String())
+
+	// compounding the daily rate for 30 days gives the monthly rate
+	monthly := decimal.NewFromInt(1).Add(rate).Pow(decimal.NewFromInt(30)).Sub(decimal.NewFromInt(1))
+	assert.Equal(t, "0.12", monthly.Round(6).String())
+
+	for monthlyRate, dailyRate := range map[string]string{
+		"0":    "0",
+		"0.02": "0.0006603055",
+		"0.5":  "0.0136072509",
+	} {
+		policy := accruals.Policy{MonthlyInterestRate: decimal.RequireFromString(monthlyRate)}
+		assert.Equal(t, dailyRate, policy.DailyInterestRate().String(), monthlyRate)
+	}
+}
+
+func TestAccrue(t *testing.T) {
+	account := &accounts.Account{ID: 1}
+	statement := &statements.Statement{ID: 7, MinimumPayment: money.MustParse("150")}
+
+	t.Run("should charge interest, late fee and penalty on the first overdue day", func(t *testing.T) {
+		svc, m := setupMocks(t)
+		ctx := context.TODO()
+
+		m.accountsSvc.EXPECT().ListAccounts(ctx, int64(0), gomock.Any()).
+			Return([]*accounts.Account{account}, nil)
+		m.repo.EXPECT().GetAccrual(ctx, account.ID, today()).
+			Return(nil, errorlib.ErrNotFound(nil))
+		m.statementsSvc.EXPECT().GetOverdueBalance(ctx, account.ID, today()).
+			Return(&statements.OverdueBalance{
+				Statement: statement,
+				Amount:    money.MustParse("1000"),
+				Paid:      money.MustParse("0"),
+			}, nil)
+		m.repo.EXPECT().HasStatementAccrual(ctx, statement.ID).Return(false, nil)
+		m.repo.EXPECT().CreateAccrual(ctx, gomock.Any()).Return(nil)
+
+		charges := map[operationtypes.Type]string{}
+		m.transactionsSvc.EXPECT().CreateSystemTransaction(ctx, gomock.Any()).
+			Do(func(_ context.Context, req *transactions.CreateTransactionRequest) {
+				charges[req.OperationTypeID] = req.Amount.String()
+			}).
+			Return(&transactions.Transaction{}, nil).
+			Times(3)
+
+		report, err := svc.Accrue(ctx, &accruals.AccrueRequest{})
+		assert.NoError(t, err)
+		assert.Len(t, report.Accruals, 1)
+		assert.Equal(t, today(), report.AccrualDate)
+
+		accrual := report.Accruals[0]
+		assert.Equal(t, statement.ID, accrual.StatementID)
+		assert.Equal(t, "3.78", accrual.Interest.String())
+		assert.Equal(t, "10.00", accrual.LateFee.String())
+		assert.Equal(t, "20.00", accrual.Penalty.String())
+
+		assert.Equal(t, map[operationtypes.Type]string{
+			operationtypes.InterestType: "-3.78",
+			operationtypes.LateFeeType:  "-10.00",
+			operationtypes.PenaltyType:  "-20.00",
+		}, charges)
+	})
+
+	t.Run("should only charge interest after the first overdue day", func(t *testing.T) {
+		svc, m := setupMocks(t)
+		ctx := context.TODO()
+
+		m.accountsSvc.EXPECT().ListAccounts(ctx, int64(0), gomock.Any()).
+			Return([]*accounts.Account{account}, nil)
+		m.repo.EXPECT().GetAccrual(ctx, account.ID, today()).
+			Return(nil, errorlib.ErrNotFound(nil))
+		m.statementsSvc.EXPECT().GetOverdueBalance(ctx, account.ID, today()).
+			Return(&statements.OverdueBalance{Statement: statement, Amount: money.MustParse("1033.78")}, nil)
+		m.repo.EXPECT().HasStatementAccrual(ctx, statement.ID).Return(true, nil)
+		m.repo.EXPECT().CreateAccrual(ctx, gomock.Any()).Return(nil)
+		m.transactionsSvc.EXPECT().CreateSystemTransaction(ctx, gomock.Any()).
+			Return(&transactions.Transaction{}, nil)
+
+		report, err := svc.Accrue(ctx, &accruals.AccrueRequest{})
+		assert.NoError(t, err)
+		assert.Len(t, report.Accruals, 1)
+		assert.Equal(t, "3.91", report.Accruals[0].Interest.String())
+		assert.True(t, report.Accruals[0].LateFee.IsZero())
+		assert.True(t, report.Accruals[0].Penalty.IsZero())
+	})
+
+	t.Run("should not charge fees if the minimum payment was paid", func(t *testing.T) {
+		svc, m := setupMocks(t)
+		ctx := context.TODO()
+
+		m.accountsSvc.EXPECT().ListAccounts(ctx, int64(0), gomock.Any()).
+			Return([]*accounts.Account{account}, nil)
+		m.repo.EXPECT().GetAccrual(ctx, account.ID, today()).
+			Return(nil, errorlib.ErrNotFound(nil))
+		m.statementsSvc.EXPECT().GetOverdueBalance(ctx, account.ID, today()).
+			Return(&statements.OverdueBalance{
+				Statement: statement,
+				Amount:    money.MustParse("850"),
+				Paid:      money.MustParse("150"),
+			}, nil)
+		m.repo.EXPECT().CreateAccrual(ctx, gomock.Any()).Return(nil)
+		m.transactionsSvc.EXPECT().CreateSystemTransaction(ctx, gomock.Any()).
+			Return(&transactions.Transaction{}, nil)
+
+		report, err := svc.Accrue(ctx, &accruals.AccrueRequest{})
+		assert.NoError(t, err)
+		assert.Len(t, report.Accruals, 1)
+		assert.True(t, report.Accruals[0].LateFee.IsZero())
+	})
+
+	t.Run("should skip the accounts already accrued or without overdue balance", func(t *testing.T) {
+		svc, m := setupMocks(t)
+		ctx := context.TODO()
+		date := today().AddDate(0, 0, -3)
+
+		m.accountsSvc.EXPECT().ListAccounts(ctx, int64(0), gomock.Any()).
+			Return([]*accounts.Account{{ID: 1}, {ID: 2}, {ID: 3}}, nil)
+		m.repo.EXPECT().GetAccrual(ctx, int64(1), date).
+			Return(&accruals.Accrual{ID: 1}, nil)
+		m.repo.EXPECT().GetAccrual(ctx, int64(2), date).
+			Return(nil, errorlib.ErrNotFound(nil))
+		m.repo.EXPECT().GetAccrual(ctx, int64(3), date).
+			Return(nil, errorlib.ErrNotFound(nil))
+		m.statementsSvc.EXPECT().GetOverdueBalance(ctx, int64(2), date).
+			Return(nil, errorlib.ErrNotFound(nil))
+		m.statementsSvc.EXPECT().GetOverdueBalance(ctx, int64(3), date).
+			Return(&statements.OverdueBalance{Statement: statement, Amount: money.NewFromInt(0)}, nil)
+
+		report, err := svc.Accrue(ctx, &accruals.AccrueRequest{Date: date.Format("2006-01-02")})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, report.AlreadyAccrued)
+		assert.Empty(t, report.Accruals)
+	})
+
+	t.Run("should report the charges without posting them in dry run mode", func(t *testing.T) {
+		svc, m := setupMocks(t)
+		ctx := context.TODO()
+
+		m.accountsSvc.EXPECT().ListAccounts(ctx, int64(0), gomock.Any()).
+			Return([]*accounts.Account{account}, nil)
+		m.repo.EXPECT().GetAccrual(ctx, account.ID, today()).
+			Return(nil, errorlib.ErrNotFound(nil))
+		m.statementsSvc.EXPECT().GetOverdueBalance(ctx, account.ID, today()).
+			Return(&statements.OverdueBalance{Statement: statement, Amount: money.MustParse("1000")}, nil)
+		m.repo.EXPECT().HasStatementAccrual(ctx, statement.ID).Return(false, nil)
+
+		report, err := svc.Accrue(ctx, &accruals.AccrueRequest{DryRun: true})
+		assert.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Len(t, report.Accruals, 1)
+		assert.Equal(t, "33.78", report.Totals().Total().String())
+	})
+
+	t.Run("should skip the account if another job accrued it", func(t *testing.T) {
+		svc, m := setupMocks(t)
+		ctx := context.TODO()
+
+		m.accountsSvc.EXPECT().ListAccounts(ctx, int64(0), gomock.Any()).
+			Return([]*accounts.Account{account}, nil)
+		m.repo.EXPECT().GetAccrual(ctx, account.ID, today()).
+			Return(nil, errorlib.ErrNotFound(nil))
+		m.statementsSvc.EXPECT().GetOverdueBalance(ctx, account.ID, today()).
+			Return(&statements.OverdueBalance{
+				Statement: statement,
+				Amount:    money.MustParse("100"),
+				Paid:      money.MustParse("150"),
+			}, nil)
+		m.repo.EXPECT().CreateAccrual(ctx, gomock.Any()).Return(errorlib.ErrDuplicated(nil))
+
+		report, err := svc.Accrue(ctx, &accruals.AccrueRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, report.AlreadyAccrued)
+		assert.Empty(t, report.Accruals)
+	})
+
+	t.Run("should return error if posting the charges fails", func(t *testing.T) {
+		svc, m := setupMocks(t)
+		ctx := context.TODO()
+		expectedErr := errors.New("connection refused")
+
+		m.accountsSvc.EXPECT().ListAccounts(ctx, int64(0), gomock.Any()).
+			Return([]*accounts.Account{account}, nil)
+		m.repo.EXPECT().GetAccrual(ctx, account.ID, today()).
+			Return(nil, errorlib.ErrNotFound(nil))
+		m.statementsSvc.EXPECT().GetOverdueBalance(ctx, account.ID, today()).
+			Return(&statements.OverdueBalance{
+				Statement: statement,
+				Amount:    money.MustParse("100"),
+				Paid:      money.MustParse("150"),
+			}, nil)
+		m.repo.EXPECT().CreateAccrual(ctx, gomock.Any()).Return(nil)
+		m.transactionsSvc.EXPECT().CreateSystemTransaction(ctx, gomock.Any()).
+			Return(nil, expectedErr)
+
+		_, err := svc.Accrue(ctx, &accruals.AccrueRequest{})
+		assert.ErrorIs(t, err, expectedErr)
+	})
+
+	for _, req := range []*accruals.AccrueRequest{
+		{Date: "10/12/2023"},
+		{Date: "2023-13-01"},
+	} {
+		t.Run("should return error if the date is invalid", func(t *testing.T) {
+			svc, _ := setupMocks(t)
+
+			_, err := svc.Accrue(context.TODO(), req)
+			assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+		})
+	}
+
+	t.Run("should return error if the date is in the future", func(t *testing.T) {
+		svc, _ := setupMocks(t)
+
+		_, err := svc.Accrue(context.TODO(), &accruals.AccrueRequest{
+			Date: today().AddDate(0, 0, 1).Format("2006-01-02"),
+		})
+		assert.ErrorIs(t, err, accruals.ErrInvalidAccrualDate(nil))
+	})
+}
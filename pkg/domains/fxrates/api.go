@@ -0,0 +1,75 @@
+package fxrates
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/httprouter"
+	"github.com/shopspring/decimal"
+)
+
+const ContentTypeCSV = "text/csv"
+
+type httpHandler struct {
+	service Service
+}
+
+func SetupHTTPRoutes(router *gin.Engine, service Service, middlewares ...gin.HandlerFunc) {
+	handler := httpHandler{
+		service: service,
+	}
+
+	adminGroup := router.Group("/admin/fx-rates", middlewares...)
+	adminGroup.POST("", handler.SaveRates)
+}
+
+func (handler *httpHandler) SaveRates(ctx *gin.Context) {
+	var (
+		rates []*Rate
+		err   error
+	)
+
+	if ctx.ContentType() == ContentTypeCSV {
+		rates, err = handler.service.ImportRatesCSV(ctx, ctx.Request.Body)
+	} else {
+		req := SaveRatesRequest{}
+		if err := httprouter.BindJSON(ctx, &req); err != nil {
+			return
+		}
+
+		rates, err = handler.service.SaveRates(ctx, &req)
+	}
+
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	resp := &RatesAPIResponse{Rates: make([]*RateAPIResponse, 0, len(rates))}
+	for _, rate := range rates {
+		resp.Rates = append(resp.Rates, NewAPIResponseFromEntity(rate))
+	}
+
+	ctx.JSON(http.StatusCreated, resp)
+}
+
+type RatesAPIResponse struct {
+	Rates []*RateAPIResponse `json:"rates"`
+}
+
+type RateAPIResponse struct {
+	FromCurrency string          `json:"from_currency"`
+	ToCurrency   string          `json:"to_currency"`
+	Rate         decimal.Decimal `json:"rate"`
+	EffectiveAt  time.Time       `json:"effective_at"`
+}
+
+func NewAPIResponseFromEntity(rate *Rate) *RateAPIResponse {
+	return &RateAPIResponse{
+		FromCurrency: rate.FromCurrency,
+		ToCurrency:   rate.ToCurrency,
+		Rate:         rate.Rate,
+		EffectiveAt:  rate.EffectiveAt,
+	}
+}
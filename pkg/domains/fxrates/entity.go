@@ -0,0 +1,15 @@
+package fxrates
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type Rate struct {
+	FromCurrency string
+	ToCurrency   string
+	Rate         decimal.Decimal
+	EffectiveAt  time.Time
+	CreatedAt    time.Time
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/domains/fxrates/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source ./pkg/domains/fxrates/repository.go -destination ./pkg/domains/fxrates/mocks/repository_mock.go
+//
+// Package mock_fxrates is a generated GoMock package.
+package mock_fxrates
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	fxrates "github.com/rudineirk/pismo-challenge/pkg/domains/fxrates"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetRate mocks base method.
+func (m *MockRepository) GetRate(ctx context.Context, fromCurrency, toCurrency string, at time.Time) (*fxrates.Rate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRate", ctx, fromCurrency, toCurrency, at)
+	ret0, _ := ret[0].(*fxrates.Rate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRate indicates an expected call of GetRate.
+func (mr *MockRepositoryMockRecorder) GetRate(ctx, fromCurrency, toCurrency, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRate", reflect.TypeOf((*MockRepository)(nil).GetRate), ctx, fromCurrency, toCurrency, at)
+}
+
+// SaveRates mocks base method.
+func (m *MockRepository) SaveRates(arg0 context.Context, arg1 []*fxrates.Rate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRates", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRates indicates an expected call of SaveRates.
+func (mr *MockRepositoryMockRecorder) SaveRates(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRates", reflect.TypeOf((*MockRepository)(nil).SaveRates), arg0, arg1)
+}
@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/domains/fxrates/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source ./pkg/domains/fxrates/service.go -destination ./pkg/domains/fxrates/mocks/service_mock.go
+//
+// Package mock_fxrates is a generated GoMock package.
+package mock_fxrates
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+	time "time"
+
+	fxrates "github.com/rudineirk/pismo-challenge/pkg/domains/fxrates"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// GetRate mocks base method.
+func (m *MockService) GetRate(ctx context.Context, fromCurrency, toCurrency string, at time.Time) (*fxrates.Rate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRate", ctx, fromCurrency, toCurrency, at)
+	ret0, _ := ret[0].(*fxrates.Rate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRate indicates an expected call of GetRate.
+func (mr *MockServiceMockRecorder) GetRate(ctx, fromCurrency, toCurrency, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRate", reflect.TypeOf((*MockService)(nil).GetRate), ctx, fromCurrency, toCurrency, at)
+}
+
+// ImportRatesCSV mocks base method.
+func (m *MockService) ImportRatesCSV(arg0 context.Context, arg1 io.Reader) ([]*fxrates.Rate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportRatesCSV", arg0, arg1)
+	ret0, _ := ret[0].([]*fxrates.Rate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportRatesCSV indicates an expected call of ImportRatesCSV.
+func (mr *MockServiceMockRecorder) ImportRatesCSV(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportRatesCSV", reflect.TypeOf((*MockService)(nil).ImportRatesCSV), arg0, arg1)
+}
+
+// SaveRates mocks base method.
+func (m *MockService) SaveRates(arg0 context.Context, arg1 *fxrates.SaveRatesRequest) ([]*fxrates.Rate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRates", arg0, arg1)
+	ret0, _ := ret[0].([]*fxrates.Rate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveRates indicates an expected call of SaveRates.
+func (mr *MockServiceMockRecorder) SaveRates(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRates", reflect.TypeOf((*MockService)(nil).SaveRates), arg0, arg1)
+}
@@ -0,0 +1,96 @@
+package fxrates
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/shopspring/decimal"
+	"github.com/uptrace/bun"
+)
+
+type Repository interface {
+	SaveRates(context.Context, []*Rate) error
+	GetRate(ctx context.Context, fromCurrency string, toCurrency string, at time.Time) (*Rate, error)
+}
+
+type RateModel struct {
+	bun.BaseModel `bun:"table:fx_rates"`
+	FromCurrency  string          `bun:"from_currency,pk"`
+	ToCurrency    string          `bun:"to_currency,pk"`
+	Rate          decimal.Decimal `bun:"rate"`
+	EffectiveAt   time.Time       `bun:"effective_at,pk"`
+	CreatedAt     time.Time       `bun:"created_at"`
+}
+
+func NewModelFromEntity(rate *Rate) *RateModel {
+	return &RateModel{
+		FromCurrency: rate.FromCurrency,
+		ToCurrency:   rate.ToCurrency,
+		Rate:         rate.Rate,
+		EffectiveAt:  rate.EffectiveAt,
+		CreatedAt:    rate.CreatedAt,
+	}
+}
+
+func (model *RateModel) ToEntity() *Rate {
+	return &Rate{
+		FromCurrency: model.FromCurrency,
+		ToCurrency:   model.ToCurrency,
+		Rate:         model.Rate,
+		EffectiveAt:  model.EffectiveAt,
+		CreatedAt:    model.CreatedAt,
+	}
+}
+
+type dbRepository struct {
+	bunDB *bun.DB
+}
+
+func NewRepository(bunDB *bun.DB) Repository {
+	return &dbRepository{bunDB}
+}
+
+func (repo *dbRepository) SaveRates(ctx context.Context, rates []*Rate) error {
+	rateModels := make([]*RateModel, 0, len(rates))
+	for _, rate := range rates {
+		rateModels = append(rateModels, NewModelFromEntity(rate))
+	}
+
+	_, err := repo.bunDB.NewInsert().
+		Model(&rateModels).
+		On("CONFLICT (from_currency, to_currency, effective_at) DO UPDATE").
+		Set("rate = EXCLUDED.rate").
+		Set("created_at = EXCLUDED.created_at").
+		Exec(ctx)
+
+	return err
+}
+
+func (repo *dbRepository) GetRate(
+	ctx context.Context,
+	fromCurrency string,
+	toCurrency string,
+	at time.Time,
+) (*Rate, error) {
+	rateModel := RateModel{}
+
+	err := repo.bunDB.NewSelect().
+		Model(&rateModel).
+		Where("from_currency = ?", fromCurrency).
+		Where("to_currency = ?", toCurrency).
+		Where("effective_at <= ?", at).
+		OrderExpr("effective_at DESC").
+		Limit(1).
+		Scan(ctx)
+
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return nil, errorlib.ErrNotFound(err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return rateModel.ToEntity(), nil
+}
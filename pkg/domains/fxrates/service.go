@@ -0,0 +1,199 @@
+package fxrates
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/validation"
+	"github.com/shopspring/decimal"
+)
+
+const maxRatesPerRequest = 1000
+
+var ErrRateNotFound = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"fx_rate_not_found",
+	"fx rate not found for the currencies",
+	http.StatusUnprocessableEntity,
+)
+var ErrInvalidRate = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"invalid_fx_rate",
+	"invalid fx rate",
+	http.StatusBadRequest,
+)
+var ErrInvalidCSV = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"invalid_csv",
+	"invalid csv file",
+	http.StatusBadRequest,
+)
+
+var errMissingCSVColumn = errors.New("missing csv column") //nolint:gochecknoglobals // constant error
+
+type Service interface {
+	SaveRates(context.Context, *SaveRatesRequest) ([]*Rate, error)
+	ImportRatesCSV(context.Context, io.Reader) ([]*Rate, error)
+	GetRate(ctx context.Context, fromCurrency string, toCurrency string, at time.Time) (*Rate, error)
+}
+
+type SaveRatesRequest struct {
+	Rates []*SaveRateRequest `json:"rates" validate:"required,min=1,max=1000,dive,required"`
+}
+
+type SaveRateRequest struct {
+	FromCurrency string          `json:"from_currency" validate:"required,iso4217"`
+	ToCurrency   string          `json:"to_currency"   validate:"required,iso4217,nefield=FromCurrency"`
+	Rate         decimal.Decimal `json:"rate"`
+	EffectiveAt  time.Time       `json:"effective_at"`
+}
+
+type fxRatesService struct {
+	repo     Repository
+	validate *validator.Validate
+}
+
+func NewService(repo Repository) Service {
+	return &fxRatesService{
+		repo:     repo,
+		validate: validation.New(),
+	}
+}
+
+func (svc *fxRatesService) SaveRates(ctx context.Context, req *SaveRatesRequest) ([]*Rate, error) {
+	for _, rateReq := range req.Rates {
+		if rateReq != nil {
+			rateReq.FromCurrency = money.NormalizeCurrency(rateReq.FromCurrency)
+			rateReq.ToCurrency = money.NormalizeCurrency(rateReq.ToCurrency)
+		}
+	}
+
+	if err := svc.validate.Struct(req); err != nil {
+		return nil, errorlib.ErrInvalidPayload(err)
+	}
+
+	now := time.Now()
+	rates := make([]*Rate, 0, len(req.Rates))
+
+	for _, rateReq := range req.Rates {
+		if !rateReq.Rate.IsPositive() {
+			return nil, ErrInvalidRate(nil)
+		}
+
+		rate := &Rate{
+			FromCurrency: rateReq.FromCurrency,
+			ToCurrency:   rateReq.ToCurrency,
+			Rate:         rateReq.Rate,
+			EffectiveAt:  rateReq.EffectiveAt,
+			CreatedAt:    now,
+		}
+
+		if rate.EffectiveAt.IsZero() {
+			rate.EffectiveAt = now
+		}
+
+		rates = append(rates, rate)
+	}
+
+	if err := svc.repo.SaveRates(ctx, rates); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+// ImportRatesCSV loads rates from a CSV with a from_currency,to_currency,rate[,effective_at] header.
+func (svc *fxRatesService) ImportRatesCSV(ctx context.Context, reader io.Reader) ([]*Rate, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, ErrInvalidCSV(err)
+	}
+
+	columns := map[string]int{}
+	for idx, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = idx
+	}
+
+	for _, name := range []string{"from_currency", "to_currency", "rate"} {
+		if _, ok := columns[name]; !ok {
+			return nil, ErrInvalidCSV(fmt.Errorf("%w: %s", errMissingCSVColumn, name))
+		}
+	}
+
+	req := &SaveRatesRequest{}
+
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, ErrInvalidCSV(err)
+		}
+
+		line, _ := csvReader.FieldPos(0)
+
+		rateReq, err := svc.parseCSVRecord(record, columns)
+		if err != nil {
+			return nil, ErrInvalidCSV(fmt.Errorf("line %d: %w", line, err))
+		}
+
+		req.Rates = append(req.Rates, rateReq)
+		if len(req.Rates) > maxRatesPerRequest {
+			break
+		}
+	}
+
+	return svc.SaveRates(ctx, req)
+}
+
+func (svc *fxRatesService) GetRate(
+	ctx context.Context,
+	fromCurrency string,
+	toCurrency string,
+	at time.Time,
+) (*Rate, error) {
+	fromCurrency = money.NormalizeCurrency(fromCurrency)
+	toCurrency = money.NormalizeCurrency(toCurrency)
+
+	if fromCurrency == toCurrency {
+		return &Rate{FromCurrency: fromCurrency, ToCurrency: toCurrency, Rate: decimal.NewFromInt(1), EffectiveAt: at}, nil
+	}
+
+	rate, err := svc.repo.GetRate(ctx, fromCurrency, toCurrency, at)
+	if err != nil && errors.Is(err, errorlib.ErrNotFound(nil)) {
+		return nil, ErrRateNotFound(err)
+	}
+
+	return rate, err
+}
+
+func (svc *fxRatesService) parseCSVRecord(record []string, columns map[string]int) (*SaveRateRequest, error) {
+	rate, err := decimal.NewFromString(strings.TrimSpace(record[columns["rate"]]))
+	if err != nil {
+		return nil, err
+	}
+
+	rateReq := &SaveRateRequest{
+		FromCurrency: record[columns["from_currency"]],
+		ToCurrency:   record[columns["to_currency"]],
+		Rate:         rate,
+	}
+
+	if idx, ok := columns["effective_at"]; ok && strings.TrimSpace(record[idx]) != "" {
+		rateReq.EffectiveAt, err = time.Parse(time.RFC3339, strings.TrimSpace(record[idx]))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rateReq, nil
+}
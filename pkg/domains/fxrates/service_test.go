@@ -0,0 +1,155 @@
+package fxrates_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rudineirk/pismo-challenge/pkg/domains/fxrates"
+	mocks "github.com/rudineirk/pismo-challenge/pkg/domains/fxrates/mocks"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/shopspring/decimal"
+	assert "github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestSaveRates(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	svc := fxrates.NewService(repo)
+
+	t.Run("should save the rates", func(t *testing.T) {
+		ctx := context.TODO()
+		now := time.Now()
+		effectiveAt := time.Date(2023, 11, 20, 0, 0, 0, 0, time.UTC)
+
+		repo.EXPECT().SaveRates(ctx, gomock.Len(2)).
+			Return(nil)
+
+		rates, err := svc.SaveRates(ctx, &fxrates.SaveRatesRequest{
+			Rates: []*fxrates.SaveRateRequest{
+				{FromCurrency: "usd", ToCurrency: "BRL", Rate: decimal.RequireFromString("4.9137")},
+				{FromCurrency: "EUR", ToCurrency: "BRL", Rate: decimal.RequireFromString("5.3"), EffectiveAt: effectiveAt},
+			},
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "USD", rates[0].FromCurrency)
+		assert.Equal(t, "BRL", rates[0].ToCurrency)
+		assert.Equal(t, "4.9137", rates[0].Rate.String())
+		assert.WithinDuration(t, now, rates[0].EffectiveAt, 5*time.Millisecond)
+		assert.Equal(t, effectiveAt, rates[1].EffectiveAt)
+	})
+
+	for _, rate := range []*fxrates.SaveRateRequest{
+		{FromCurrency: "USD", ToCurrency: "USD", Rate: decimal.NewFromInt(1)},
+		{FromCurrency: "US", ToCurrency: "BRL", Rate: decimal.NewFromInt(1)},
+		{FromCurrency: "USD", ToCurrency: "", Rate: decimal.NewFromInt(1)},
+		nil,
+	} {
+		t.Run("should return error if payload is invalid", func(t *testing.T) {
+			_, err := svc.SaveRates(context.TODO(), &fxrates.SaveRatesRequest{
+				Rates: []*fxrates.SaveRateRequest{rate},
+			})
+			assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+		})
+	}
+
+	t.Run("should return error if payload is empty", func(t *testing.T) {
+		_, err := svc.SaveRates(context.TODO(), &fxrates.SaveRatesRequest{})
+		assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+	})
+
+	for _, rate := range []string{"0", "-1.5"} {
+		t.Run("should return error if rate is not positive", func(t *testing.T) {
+			_, err := svc.SaveRates(context.TODO(), &fxrates.SaveRatesRequest{
+				Rates: []*fxrates.SaveRateRequest{
+					{FromCurrency: "USD", ToCurrency: "BRL", Rate: decimal.RequireFromString(rate)},
+				},
+			})
+			assert.ErrorIs(t, err, fxrates.ErrInvalidRate(nil))
+		})
+	}
+}
+
+func TestImportRatesCSV(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	svc := fxrates.NewService(repo)
+
+	t.Run("should import the rates from the csv", func(t *testing.T) {
+		ctx := context.TODO()
+
+		repo.EXPECT().SaveRates(ctx, gomock.Len(2)).
+			Return(nil)
+
+		rates, err := svc.ImportRatesCSV(ctx, strings.NewReader(
+			"from_currency,to_currency,rate,effective_at\n"+
+				"USD,BRL,4.9137,2023-11-20T00:00:00Z\n"+
+				"eur, brl, 5.3,\n",
+		))
+		assert.NoError(t, err)
+
+		assert.Equal(t, "USD", rates[0].FromCurrency)
+		assert.Equal(t, time.Date(2023, 11, 20, 0, 0, 0, 0, time.UTC), rates[0].EffectiveAt)
+		assert.Equal(t, "EUR", rates[1].FromCurrency)
+		assert.Equal(t, "BRL", rates[1].ToCurrency)
+		assert.Equal(t, "5.3", rates[1].Rate.String())
+	})
+
+	for _, content := range []string{
+		"",
+		"from_currency,rate\nUSD,4.9\n",
+		"from_currency,to_currency,rate\nUSD,BRL,abc\n",
+		"from_currency,to_currency,rate\nUSD,BRL\n",
+		"from_currency,to_currency,rate,effective_at\nUSD,BRL,4.9,yesterday\n",
+	} {
+		t.Run("should return error if csv is invalid", func(t *testing.T) {
+			_, err := svc.ImportRatesCSV(context.TODO(), strings.NewReader(content))
+			assert.ErrorIs(t, err, fxrates.ErrInvalidCSV(nil))
+		})
+	}
+}
+
+func TestGetRate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	svc := fxrates.NewService(repo)
+
+	t.Run("should return the rate effective at the given time", func(t *testing.T) {
+		ctx := context.TODO()
+		now := time.Now()
+		rate := &fxrates.Rate{FromCurrency: "USD", ToCurrency: "BRL", Rate: decimal.RequireFromString("4.9")}
+
+		repo.EXPECT().GetRate(ctx, "USD", "BRL", now).
+			Return(rate, nil)
+
+		result, err := svc.GetRate(ctx, "usd", "BRL", now)
+		assert.NoError(t, err)
+		assert.Equal(t, rate, result)
+	})
+
+	t.Run("should return one for the same currency", func(t *testing.T) {
+		result, err := svc.GetRate(context.TODO(), "BRL", "BRL", time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, "1", result.Rate.String())
+	})
+
+	t.Run("should return error if rate is not found", func(t *testing.T) {
+		ctx := context.TODO()
+
+		repo.EXPECT().GetRate(ctx, "EUR", "BRL", gomock.Any()).
+			Return(nil, errorlib.ErrNotFound(nil))
+
+		_, err := svc.GetRate(ctx, "EUR", "BRL", time.Now())
+		assert.ErrorIs(t, err, fxrates.ErrRateNotFound(nil))
+	})
+}
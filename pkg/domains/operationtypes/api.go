@@ -0,0 +1,101 @@
+package operationtypes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/httprouter"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+)
+
+type httpHandler struct {
+	service Service
+}
+
+func SetupHTTPRoutes(router *gin.Engine, service Service, middlewares ...gin.HandlerFunc) {
+	handler := httpHandler{
+		service: service,
+	}
+
+	router.GET("/operation-types", handler.ListOperationTypes)
+
+	adminGroup := router.Group("/admin/operation-types", middlewares...)
+	adminGroup.POST("", handler.CreateOperationType)
+	adminGroup.PUT("/:operation_type_id", handler.UpdateOperationType)
+}
+
+func (handler *httpHandler) ListOperationTypes(ctx *gin.Context) {
+	opTypes, err := handler.service.ListOperationTypes(ctx)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	resp := make([]*OperationTypeAPIResponse, 0, len(opTypes))
+	for _, opType := range opTypes {
+		resp = append(resp, NewAPIResponseFromEntity(opType))
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (handler *httpHandler) CreateOperationType(ctx *gin.Context) {
+	req := CreateOperationTypeRequest{}
+	if err := httprouter.BindJSON(ctx, &req); err != nil {
+		return
+	}
+
+	opType, err := handler.service.CreateOperationType(ctx, &req)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, NewAPIResponseFromEntity(opType))
+}
+
+func (handler *httpHandler) UpdateOperationType(ctx *gin.Context) {
+	opTypeIDRaw := ctx.Param("operation_type_id")
+
+	opTypeID, err := strconv.Atoi(opTypeIDRaw)
+	if err != nil {
+		httprouter.AbortWithError(ctx, errorlib.ErrNotFound(err))
+		return
+	}
+
+	req := UpdateOperationTypeRequest{}
+	if err := httprouter.BindJSON(ctx, &req); err != nil {
+		return
+	}
+
+	req.OperationTypeID = Type(opTypeID)
+
+	opType, err := handler.service.UpdateOperationType(ctx, &req)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, NewAPIResponseFromEntity(opType))
+}
+
+type OperationTypeAPIResponse struct {
+	OperationTypeID    Type     `json:"operation_type_id"`
+	Description        string   `json:"description"`
+	Sign               int      `json:"sign"`
+	AffectsCreditLimit bool     `json:"affects_credit_limit"`
+	Category           Category `json:"category"`
+	SystemGenerated    bool     `json:"system_generated"`
+}
+
+func NewAPIResponseFromEntity(opType *OperationType) *OperationTypeAPIResponse {
+	return &OperationTypeAPIResponse{
+		OperationTypeID:    opType.ID,
+		Description:        opType.Description,
+		Sign:               opType.Sign,
+		AffectsCreditLimit: opType.AffectsCreditLimit,
+		Category:           opType.Category,
+		SystemGenerated:    opType.SystemGenerated,
+	}
+}
@@ -0,0 +1,11 @@
+package operationtypes
+
+type OperationType struct {
+	ID                 Type
+	Description        string
+	Sign               int
+	AffectsCreditLimit bool
+	Category           Category
+	// SystemGenerated types are only posted by the system jobs, like interest and fees
+	SystemGenerated bool
+}
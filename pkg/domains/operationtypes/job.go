@@ -0,0 +1,42 @@
+package operationtypes
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// RefreshJob reloads the operation types periodically until the context is canceled,
+// so the changes made by other instances reach the in memory registry.
+type RefreshJob struct {
+	service  Service
+	logger   *zerolog.Logger
+	interval time.Duration
+}
+
+func NewRefreshJob(service Service, logger *zerolog.Logger, interval time.Duration) *RefreshJob {
+	return &RefreshJob{
+		service:  service,
+		logger:   logger,
+		interval: interval,
+	}
+}
+
+func (job *RefreshJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+
+	for {
+		// the operation types are already loaded at startup
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := job.service.LoadOperationTypes(ctx); err != nil && ctx.Err() == nil {
+			job.logger.Err(err).Msg("Failed to refresh the operation types")
+		}
+	}
+}
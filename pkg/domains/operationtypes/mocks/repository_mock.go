@@ -0,0 +1,98 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/domains/operationtypes/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source ./pkg/domains/operationtypes/repository.go -destination ./pkg/domains/operationtypes/mocks/repository_mock.go
+//
+// Package mock_operationtypes is a generated GoMock package.
+package mock_operationtypes
+
+import (
+	context "context"
+	reflect "reflect"
+
+	operationtypes "github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateOperationType mocks base method.
+func (m *MockRepository) CreateOperationType(arg0 context.Context, arg1 *operationtypes.OperationType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOperationType", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOperationType indicates an expected call of CreateOperationType.
+func (mr *MockRepositoryMockRecorder) CreateOperationType(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOperationType", reflect.TypeOf((*MockRepository)(nil).CreateOperationType), arg0, arg1)
+}
+
+// GetOperationTypeByID mocks base method.
+func (m *MockRepository) GetOperationTypeByID(arg0 context.Context, arg1 operationtypes.Type) (*operationtypes.OperationType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOperationTypeByID", arg0, arg1)
+	ret0, _ := ret[0].(*operationtypes.OperationType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOperationTypeByID indicates an expected call of GetOperationTypeByID.
+func (mr *MockRepositoryMockRecorder) GetOperationTypeByID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOperationTypeByID", reflect.TypeOf((*MockRepository)(nil).GetOperationTypeByID), arg0, arg1)
+}
+
+// ListOperationTypes mocks base method.
+func (m *MockRepository) ListOperationTypes(arg0 context.Context) ([]*operationtypes.OperationType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOperationTypes", arg0)
+	ret0, _ := ret[0].([]*operationtypes.OperationType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOperationTypes indicates an expected call of ListOperationTypes.
+func (mr *MockRepositoryMockRecorder) ListOperationTypes(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOperationTypes", reflect.TypeOf((*MockRepository)(nil).ListOperationTypes), arg0)
+}
+
+// UpdateOperationType mocks base method.
+func (m *MockRepository) UpdateOperationType(arg0 context.Context, arg1 *operationtypes.OperationType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOperationType", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOperationType indicates an expected call of UpdateOperationType.
+func (mr *MockRepositoryMockRecorder) UpdateOperationType(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOperationType", reflect.TypeOf((*MockRepository)(nil).UpdateOperationType), arg0, arg1)
+}
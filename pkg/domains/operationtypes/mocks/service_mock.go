@@ -0,0 +1,114 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/domains/operationtypes/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source ./pkg/domains/operationtypes/service.go -destination ./pkg/domains/operationtypes/mocks/service_mock.go
+//
+// Package mock_operationtypes is a generated GoMock package.
+package mock_operationtypes
+
+import (
+	context "context"
+	reflect "reflect"
+
+	operationtypes "github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateOperationType mocks base method.
+func (m *MockService) CreateOperationType(arg0 context.Context, arg1 *operationtypes.CreateOperationTypeRequest) (*operationtypes.OperationType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOperationType", arg0, arg1)
+	ret0, _ := ret[0].(*operationtypes.OperationType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOperationType indicates an expected call of CreateOperationType.
+func (mr *MockServiceMockRecorder) CreateOperationType(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOperationType", reflect.TypeOf((*MockService)(nil).CreateOperationType), arg0, arg1)
+}
+
+// GetOperationType mocks base method.
+func (m *MockService) GetOperationType(arg0 context.Context, arg1 operationtypes.Type) (*operationtypes.OperationType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOperationType", arg0, arg1)
+	ret0, _ := ret[0].(*operationtypes.OperationType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOperationType indicates an expected call of GetOperationType.
+func (mr *MockServiceMockRecorder) GetOperationType(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOperationType", reflect.TypeOf((*MockService)(nil).GetOperationType), arg0, arg1)
+}
+
+// ListOperationTypes mocks base method.
+func (m *MockService) ListOperationTypes(arg0 context.Context) ([]*operationtypes.OperationType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOperationTypes", arg0)
+	ret0, _ := ret[0].([]*operationtypes.OperationType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOperationTypes indicates an expected call of ListOperationTypes.
+func (mr *MockServiceMockRecorder) ListOperationTypes(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOperationTypes", reflect.TypeOf((*MockService)(nil).ListOperationTypes), arg0)
+}
+
+// LoadOperationTypes mocks base method.
+func (m *MockService) LoadOperationTypes(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadOperationTypes", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LoadOperationTypes indicates an expected call of LoadOperationTypes.
+func (mr *MockServiceMockRecorder) LoadOperationTypes(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadOperationTypes", reflect.TypeOf((*MockService)(nil).LoadOperationTypes), arg0)
+}
+
+// UpdateOperationType mocks base method.
+func (m *MockService) UpdateOperationType(arg0 context.Context, arg1 *operationtypes.UpdateOperationTypeRequest) (*operationtypes.OperationType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOperationType", arg0, arg1)
+	ret0, _ := ret[0].(*operationtypes.OperationType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOperationType indicates an expected call of UpdateOperationType.
+func (mr *MockServiceMockRecorder) UpdateOperationType(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOperationType", reflect.TypeOf((*MockService)(nil).UpdateOperationType), arg0, arg1)
+}
@@ -0,0 +1,161 @@
+package operationtypes
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/uptrace/bun"
+)
+
+type Repository interface {
+	ListOperationTypes(context.Context) ([]*OperationType, error)
+	GetOperationTypeByID(context.Context, Type) (*OperationType, error)
+	CreateOperationType(context.Context, *OperationType) error
+	UpdateOperationType(context.Context, *OperationType) error
+}
+
+type OperationTypeModel struct {
+	bun.BaseModel      `bun:"table:operation_types"`
+	ID                 Type     `bun:"id,pk"`
+	Description        string   `bun:"description"`
+	Sign               int      `bun:"sign"`
+	AffectsCreditLimit bool     `bun:"affects_credit_limit"`
+	Category           Category `bun:"category"`
+	SystemGenerated    bool     `bun:"system_generated"`
+}
+
+func NewModelFromEntity(opType *OperationType) *OperationTypeModel {
+	return &OperationTypeModel{
+		ID:                 opType.ID,
+		Description:        opType.Description,
+		Sign:               opType.Sign,
+		AffectsCreditLimit: opType.AffectsCreditLimit,
+		Category:           opType.Category,
+		SystemGenerated:    opType.SystemGenerated,
+	}
+}
+
+func (model *OperationTypeModel) ToEntity() *OperationType {
+	return &OperationType{
+		ID:                 model.ID,
+		Description:        model.Description,
+		Sign:               model.Sign,
+		AffectsCreditLimit: model.AffectsCreditLimit,
+		Category:           model.Category,
+		SystemGenerated:    model.SystemGenerated,
+	}
+}
+
+type dbRepository struct {
+	bunDB *bun.DB
+}
+
+func NewRepository(bunDB *bun.DB) Repository {
+	return &dbRepository{bunDB}
+}
+
+func (repo *dbRepository) ListOperationTypes(ctx context.Context) ([]*OperationType, error) {
+	opTypeModels := []*OperationTypeModel{}
+
+	err := repo.bunDB.NewSelect().
+		Model(&opTypeModels).
+		OrderExpr("id ASC").
+		Scan(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	opTypes := make([]*OperationType, 0, len(opTypeModels))
+	for _, model := range opTypeModels {
+		opTypes = append(opTypes, model.ToEntity())
+	}
+
+	return opTypes, nil
+}
+
+func (repo *dbRepository) GetOperationTypeByID(ctx context.Context, id Type) (*OperationType, error) {
+	opTypeModel := OperationTypeModel{}
+
+	err := repo.bunDB.NewSelect().
+		Model(&opTypeModel).
+		Where("id = ?", id).
+		Scan(ctx)
+
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return nil, errorlib.ErrNotFound(err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return opTypeModel.ToEntity(), nil
+}
+
+func (repo *dbRepository) CreateOperationType(ctx context.Context, opType *OperationType) error {
+	_, err := repo.bunDB.NewInsert().
+		Model(NewModelFromEntity(opType)).
+		Exec(ctx)
+
+	if err != nil && strings.Contains(err.Error(), "unique constraint") {
+		return errorlib.ErrDuplicated(err)
+	}
+
+	return err
+}
+
+// UpdateOperationType keeps the system_generated flag, it can only be set when the type is created,
+// and the category when it's empty. The sign can't be changed once a transaction references the type,
+// the stored amounts depend on it.
+func (repo *dbRepository) UpdateOperationType(ctx context.Context, opType *OperationType) error {
+	opTypeModel := NewModelFromEntity(opType)
+
+	columns := []string{"description", "sign", "affects_credit_limit"}
+	if opType.Category != "" {
+		columns = append(columns, "category")
+	}
+
+	result, err := repo.bunDB.NewUpdate().
+		Model(opTypeModel).
+		Column(columns...).
+		WherePK().
+		Where(
+			"sign = ? OR NOT EXISTS (SELECT 1 FROM transactions AS t WHERE t.operation_type_id = ?)",
+			opType.Sign, opType.ID,
+		).
+		Returning("category, system_generated").
+		Exec(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	} else if rows == 0 {
+		return repo.notUpdatedError(ctx, opType.ID)
+	}
+
+	opType.Category = opTypeModel.Category
+	opType.SystemGenerated = opTypeModel.SystemGenerated
+
+	return nil
+}
+
+func (repo *dbRepository) notUpdatedError(ctx context.Context, id Type) error {
+	exists, err := repo.bunDB.NewSelect().
+		Model((*OperationTypeModel)(nil)).
+		Where("id = ?", id).
+		Exists(ctx)
+
+	if err != nil {
+		return err
+	} else if !exists {
+		return errorlib.ErrNotFound(nil)
+	}
+
+	return ErrOperationTypeInUse(nil)
+}
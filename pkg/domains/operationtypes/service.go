@@ -0,0 +1,200 @@
+package operationtypes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/validation"
+)
+
+var ErrOperationTypeInUse = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"operation_type_in_use",
+	"operation type sign can't be changed, it already has transactions",
+	http.StatusUnprocessableEntity,
+)
+
+type Service interface {
+	LoadOperationTypes(context.Context) error
+	ListOperationTypes(context.Context) ([]*OperationType, error)
+	GetOperationType(context.Context, Type) (*OperationType, error)
+	CreateOperationType(context.Context, *CreateOperationTypeRequest) (*OperationType, error)
+	UpdateOperationType(context.Context, *UpdateOperationTypeRequest) (*OperationType, error)
+}
+
+type CreateOperationTypeRequest struct {
+	OperationTypeID    Type     `json:"operation_type_id"    validate:"required,gt=0"`
+	Description        string   `json:"description"          validate:"required,max=255"`
+	Sign               int      `json:"sign"                 validate:"required,oneof=-1 1"`
+	AffectsCreditLimit *bool    `json:"affects_credit_limit" validate:"required"`
+	Category           Category `json:"category"             validate:"omitempty,oneof=purchase withdrawal payment other"`
+	SystemGenerated    bool     `json:"system_generated"`
+}
+
+type UpdateOperationTypeRequest struct {
+	OperationTypeID    Type     `json:"-"                    validate:"required"`
+	Description        string   `json:"description"          validate:"required,max=255"`
+	Sign               int      `json:"sign"                 validate:"required,oneof=-1 1"`
+	AffectsCreditLimit *bool    `json:"affects_credit_limit" validate:"required"`
+	Category           Category `json:"category"             validate:"omitempty,oneof=purchase withdrawal payment other"`
+}
+
+// operationTypesService keeps an in memory registry of the operation types, loaded
+// at startup, refreshed on every write or cache miss, and reloaded by the RefreshJob.
+type operationTypesService struct {
+	repo     Repository
+	validate *validator.Validate
+
+	mutex    sync.RWMutex
+	registry map[Type]OperationType
+}
+
+func NewService(repo Repository) Service {
+	return &operationTypesService{
+		repo:     repo,
+		validate: validation.New(),
+		registry: map[Type]OperationType{},
+	}
+}
+
+func (svc *operationTypesService) LoadOperationTypes(ctx context.Context) error {
+	opTypes, err := svc.repo.ListOperationTypes(ctx)
+	if err != nil {
+		return err
+	}
+
+	registry := make(map[Type]OperationType, len(opTypes))
+	for _, opType := range opTypes {
+		registry[opType.ID] = *opType
+	}
+
+	svc.mutex.Lock()
+	defer svc.mutex.Unlock()
+
+	svc.registry = registry
+
+	return nil
+}
+
+func (svc *operationTypesService) ListOperationTypes(_ context.Context) ([]*OperationType, error) {
+	svc.mutex.RLock()
+	defer svc.mutex.RUnlock()
+
+	opTypes := make([]*OperationType, 0, len(svc.registry))
+	for _, opType := range svc.registry {
+		opType := opType
+		opTypes = append(opTypes, &opType)
+	}
+
+	sort.Slice(opTypes, func(i, j int) bool {
+		return opTypes[i].ID < opTypes[j].ID
+	})
+
+	return opTypes, nil
+}
+
+func (svc *operationTypesService) GetOperationType(ctx context.Context, id Type) (*OperationType, error) {
+	svc.mutex.RLock()
+	opType, ok := svc.registry[id]
+	svc.mutex.RUnlock()
+
+	if ok {
+		return &opType, nil
+	}
+
+	// the operation type may have been created by another instance
+	loaded, err := svc.repo.GetOperationTypeByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.store(loaded)
+
+	return loaded, nil
+}
+
+func (svc *operationTypesService) CreateOperationType(
+	ctx context.Context,
+	req *CreateOperationTypeRequest,
+) (*OperationType, error) {
+	if err := svc.validate.Struct(req); err != nil {
+		return nil, errorlib.ErrInvalidPayload(err)
+	}
+
+	opType := &OperationType{
+		ID:                 req.OperationTypeID,
+		Description:        strings.TrimSpace(req.Description),
+		Sign:               req.Sign,
+		AffectsCreditLimit: *req.AffectsCreditLimit,
+		Category:           req.Category,
+		SystemGenerated:    req.SystemGenerated,
+	}
+
+	if opType.Category == "" {
+		opType.Category = OtherCategory
+	}
+
+	if opType.Description == "" {
+		return nil, errorlib.ErrInvalidPayload(nil)
+	}
+
+	if err := svc.repo.CreateOperationType(ctx, opType); err != nil {
+		return nil, err
+	}
+
+	svc.store(opType)
+
+	return opType, nil
+}
+
+func (svc *operationTypesService) UpdateOperationType(
+	ctx context.Context,
+	req *UpdateOperationTypeRequest,
+) (*OperationType, error) {
+	if err := svc.validate.Struct(req); err != nil {
+		return nil, errorlib.ErrInvalidPayload(err)
+	}
+
+	opType := &OperationType{
+		ID:                 req.OperationTypeID,
+		Description:        strings.TrimSpace(req.Description),
+		Sign:               req.Sign,
+		AffectsCreditLimit: *req.AffectsCreditLimit,
+		Category:           req.Category,
+	}
+
+	if opType.Description == "" {
+		return nil, errorlib.ErrInvalidPayload(nil)
+	}
+
+	if err := svc.repo.UpdateOperationType(ctx, opType); err != nil {
+		if errors.Is(err, errorlib.ErrNotFound(nil)) {
+			svc.remove(opType.ID)
+		}
+
+		return nil, err
+	}
+
+	svc.store(opType)
+
+	return opType, nil
+}
+
+func (svc *operationTypesService) store(opType *OperationType) {
+	svc.mutex.Lock()
+	defer svc.mutex.Unlock()
+
+	svc.registry[opType.ID] = *opType
+}
+
+func (svc *operationTypesService) remove(id Type) {
+	svc.mutex.Lock()
+	defer svc.mutex.Unlock()
+
+	delete(svc.registry, id)
+}
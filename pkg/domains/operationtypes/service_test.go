@@ -0,0 +1,198 @@
+package operationtypes_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	mocks "github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes/mocks"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	assert "github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestLoadOperationTypes(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	svc := operationtypes.NewService(repo)
+
+	ctx := context.TODO()
+
+	repo.EXPECT().ListOperationTypes(ctx).
+		Return([]*operationtypes.OperationType{
+			{ID: operationtypes.PaymentType, Description: "PAYMENT", Sign: 1, AffectsCreditLimit: true},
+			{ID: operationtypes.CashPurchaseType, Description: "CASH PURCHASE", Sign: -1, AffectsCreditLimit: true},
+		}, nil)
+
+	err := svc.LoadOperationTypes(ctx)
+	assert.NoError(t, err)
+
+	t.Run("should list the operation types sorted by id", func(t *testing.T) {
+		opTypes, err := svc.ListOperationTypes(ctx)
+		assert.NoError(t, err)
+
+		assert.Len(t, opTypes, 2)
+		assert.Equal(t, operationtypes.CashPurchaseType, opTypes[0].ID)
+		assert.Equal(t, operationtypes.PaymentType, opTypes[1].ID)
+	})
+
+	t.Run("should get the operation type from the registry", func(t *testing.T) {
+		opType, err := svc.GetOperationType(ctx, operationtypes.PaymentType)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "PAYMENT", opType.Description)
+		assert.Equal(t, operationtypes.PositiveSign, opType.Sign)
+		assert.True(t, opType.AffectsCreditLimit)
+	})
+
+	t.Run("should load missing operation types from the repository", func(t *testing.T) {
+		repo.EXPECT().GetOperationTypeByID(ctx, operationtypes.Type(5)).
+			Return(&operationtypes.OperationType{ID: 5, Description: "FEE", Sign: -1}, nil).
+			Times(1)
+
+		for i := 0; i < 2; i++ {
+			opType, err := svc.GetOperationType(ctx, operationtypes.Type(5))
+			assert.NoError(t, err)
+			assert.Equal(t, "FEE", opType.Description)
+		}
+	})
+
+	t.Run("should return error if operation type is not found", func(t *testing.T) {
+		repo.EXPECT().GetOperationTypeByID(ctx, operationtypes.Type(6)).
+			Return(nil, errorlib.ErrNotFound(nil))
+
+		_, err := svc.GetOperationType(ctx, operationtypes.Type(6))
+		assert.ErrorIs(t, err, errorlib.ErrNotFound(nil))
+	})
+}
+
+func TestCreateOperationType(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	svc := operationtypes.NewService(repo)
+
+	affectsCreditLimit := false
+
+	t.Run("should create a new operation type", func(t *testing.T) {
+		ctx := context.TODO()
+
+		repo.EXPECT().CreateOperationType(ctx, gomock.Any()).
+			Return(nil)
+
+		opType, err := svc.CreateOperationType(ctx, &operationtypes.CreateOperationTypeRequest{
+			OperationTypeID:    5,
+			Description:        " ANNUAL FEE ",
+			Sign:               -1,
+			AffectsCreditLimit: &affectsCreditLimit,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, operationtypes.Type(5), opType.ID)
+		assert.Equal(t, "ANNUAL FEE", opType.Description)
+		assert.Equal(t, operationtypes.NegativeSign, opType.Sign)
+		assert.False(t, opType.AffectsCreditLimit)
+		assert.Equal(t, operationtypes.OtherCategory, opType.Category)
+		assert.False(t, opType.SystemGenerated)
+
+		cached, err := svc.GetOperationType(ctx, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, opType, cached)
+	})
+
+	for _, req := range []*operationtypes.CreateOperationTypeRequest{
+		{OperationTypeID: 0, Description: "FEE", Sign: -1, AffectsCreditLimit: &affectsCreditLimit},
+		{OperationTypeID: 5, Description: " ", Sign: -1, AffectsCreditLimit: &affectsCreditLimit},
+		{OperationTypeID: 5, Description: "FEE", Sign: 0, AffectsCreditLimit: &affectsCreditLimit},
+		{OperationTypeID: 5, Description: "FEE", Sign: 2, AffectsCreditLimit: &affectsCreditLimit},
+		{OperationTypeID: 5, Description: "FEE", Sign: 1},
+		{OperationTypeID: 5, Description: "FEE", Sign: -1, AffectsCreditLimit: &affectsCreditLimit, Category: "fee"},
+	} {
+		t.Run("should return error if payload is invalid", func(t *testing.T) {
+			_, err := svc.CreateOperationType(context.TODO(), req)
+			assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+		})
+	}
+
+	t.Run("should return error if operation type already exists", func(t *testing.T) {
+		ctx := context.TODO()
+
+		repo.EXPECT().CreateOperationType(ctx, gomock.Any()).
+			Return(errorlib.ErrDuplicated(nil))
+
+		_, err := svc.CreateOperationType(ctx, &operationtypes.CreateOperationTypeRequest{
+			OperationTypeID:    1,
+			Description:        "CASH PURCHASE",
+			Sign:               -1,
+			AffectsCreditLimit: &affectsCreditLimit,
+		})
+		assert.ErrorIs(t, err, errorlib.ErrDuplicated(nil))
+	})
+}
+
+func TestUpdateOperationType(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	svc := operationtypes.NewService(repo)
+
+	affectsCreditLimit := true
+
+	t.Run("should update the operation type", func(t *testing.T) {
+		ctx := context.TODO()
+
+		repo.EXPECT().UpdateOperationType(ctx, gomock.Any()).
+			Return(nil)
+
+		opType, err := svc.UpdateOperationType(ctx, &operationtypes.UpdateOperationTypeRequest{
+			OperationTypeID:    operationtypes.PaymentType,
+			Description:        "PAYMENT",
+			Sign:               1,
+			AffectsCreditLimit: &affectsCreditLimit,
+		})
+		assert.NoError(t, err)
+
+		cached, err := svc.GetOperationType(ctx, operationtypes.PaymentType)
+		assert.NoError(t, err)
+		assert.Equal(t, opType, cached)
+	})
+
+	t.Run("should return error if operation type is not found", func(t *testing.T) {
+		ctx := context.TODO()
+
+		repo.EXPECT().UpdateOperationType(ctx, gomock.Any()).
+			Return(errorlib.ErrNotFound(nil))
+
+		_, err := svc.UpdateOperationType(ctx, &operationtypes.UpdateOperationTypeRequest{
+			OperationTypeID:    10,
+			Description:        "UNKNOWN",
+			Sign:               1,
+			AffectsCreditLimit: &affectsCreditLimit,
+		})
+		assert.ErrorIs(t, err, errorlib.ErrNotFound(nil))
+	})
+
+	t.Run("should return error if changing the sign of a type with transactions", func(t *testing.T) {
+		ctx := context.TODO()
+
+		repo.EXPECT().UpdateOperationType(ctx, gomock.Any()).
+			Return(operationtypes.ErrOperationTypeInUse(nil))
+
+		_, err := svc.UpdateOperationType(ctx, &operationtypes.UpdateOperationTypeRequest{
+			OperationTypeID:    operationtypes.PaymentType,
+			Description:        "PAYMENT",
+			Sign:               -1,
+			AffectsCreditLimit: &affectsCreditLimit,
+		})
+		assert.ErrorIs(t, err, operationtypes.ErrOperationTypeInUse(nil))
+
+		cached, err := svc.GetOperationType(ctx, operationtypes.PaymentType)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, cached.Sign)
+	})
+}
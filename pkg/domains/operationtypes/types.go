@@ -7,8 +7,22 @@ const (
 	InstallmentType  Type = 2
 	WithdrawType     Type = 3
 	PaymentType      Type = 4
+	InterestType     Type = 5
+	LateFeeType      Type = 6
+	PenaltyType      Type = 7
 )
 
-func IsValidOperationType(id Type) bool {
-	return id >= CashPurchaseType && id <= PaymentType
-}
+// Category groups the operation types in the account balance totals.
+type Category string
+
+const (
+	PurchaseCategory   Category = "purchase"
+	WithdrawalCategory Category = "withdrawal"
+	PaymentCategory    Category = "payment"
+	OtherCategory      Category = "other"
+)
+
+const (
+	NegativeSign = -1
+	PositiveSign = 1
+)
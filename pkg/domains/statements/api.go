@@ -0,0 +1,104 @@
+package statements
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/httprouter"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+)
+
+const dateLayout = "2006-01-02"
+
+type httpHandler struct {
+	service Service
+}
+
+func SetupHTTPRoutes(router *gin.Engine, service Service, middlewares ...gin.HandlerFunc) {
+	handler := httpHandler{
+		service: service,
+	}
+
+	routeGroup := router.Group("/statements", middlewares...)
+	routeGroup.GET("/:statement_id", handler.GetStatement)
+
+	router.GET("/accounts/:account_id/statements", handler.ListAccountStatements)
+}
+
+func (handler *httpHandler) GetStatement(ctx *gin.Context) {
+	statementID, err := strconv.ParseInt(ctx.Param("statement_id"), 10, 64)
+	if err != nil {
+		httprouter.AbortWithError(ctx, errorlib.ErrNotFound(err))
+		return
+	}
+
+	statement, err := handler.service.GetStatement(ctx, statementID)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, NewAPIResponseFromEntity(statement))
+}
+
+func (handler *httpHandler) ListAccountStatements(ctx *gin.Context) {
+	accountID, err := strconv.ParseInt(ctx.Param("account_id"), 10, 64)
+	if err != nil {
+		httprouter.AbortWithError(ctx, errorlib.ErrNotFound(err))
+		return
+	}
+
+	statements, err := handler.service.ListAccountStatements(ctx, accountID)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	resp := make([]*StatementAPIResponse, 0, len(statements))
+	for _, statement := range statements {
+		resp = append(resp, NewAPIResponseFromEntity(statement))
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+type StatementAPIResponse struct {
+	StatementID       int64       `json:"statement_id"`
+	AccountID         int64       `json:"account_id"`
+	Currency          string      `json:"currency"`
+	PeriodStart       string      `json:"period_start"`
+	ClosingDate       string      `json:"closing_date"`
+	DueDate           string      `json:"due_date"`
+	OpeningBalance    money.Money `json:"opening_balance"`
+	Purchases         money.Money `json:"purchases"`
+	Withdrawals       money.Money `json:"withdrawals"`
+	Payments          money.Money `json:"payments"`
+	Other             money.Money `json:"other"`
+	ClosingBalance    money.Money `json:"closing_balance"`
+	MinimumPayment    money.Money `json:"minimum_payment"`
+	TransactionsCount int         `json:"transactions_count"`
+	CreatedAt         time.Time   `json:"created_at"`
+}
+
+func NewAPIResponseFromEntity(statement *Statement) *StatementAPIResponse {
+	return &StatementAPIResponse{
+		StatementID:       statement.ID,
+		AccountID:         statement.AccountID,
+		Currency:          statement.Currency,
+		PeriodStart:       statement.PeriodStart.Format(dateLayout),
+		ClosingDate:       statement.ClosingDate.Format(dateLayout),
+		DueDate:           statement.DueDate.Format(dateLayout),
+		OpeningBalance:    statement.OpeningBalance,
+		Purchases:         statement.Purchases,
+		Withdrawals:       statement.Withdrawals,
+		Payments:          statement.Payments,
+		Other:             statement.Other,
+		ClosingBalance:    statement.ClosingBalance,
+		MinimumPayment:    statement.MinimumPayment,
+		TransactionsCount: statement.TransactionsCount,
+		CreatedAt:         statement.CreatedAt,
+	}
+}
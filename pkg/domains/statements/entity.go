@@ -0,0 +1,102 @@
+package statements
+
+import (
+	"time"
+
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	minimumPaymentRate  = decimal.RequireFromString("0.15") //nolint:gochecknoglobals // billing rule
+	minimumPaymentFloor = money.NewFromInt(10)              //nolint:gochecknoglobals // billing rule
+)
+
+// Statement is the snapshot of a closed billing cycle, the cycle includes the
+// transactions from the period start until the end of the closing date (UTC).
+type Statement struct {
+	ID                int64
+	AccountID         int64
+	Currency          string
+	PeriodStart       time.Time
+	ClosingDate       time.Time
+	DueDate           time.Time
+	OpeningBalance    money.Money
+	Purchases         money.Money
+	Withdrawals       money.Money
+	Payments          money.Money
+	Other             money.Money
+	ClosingBalance    money.Money
+	MinimumPayment    money.Money
+	TransactionsCount int
+	CreatedAt         time.Time
+}
+
+// OverdueBalance is the amount of a statement not paid until its due date, the payments
+// and credits posted after the closing date reduce it, while the charges increase it.
+type OverdueBalance struct {
+	Statement *Statement
+	Amount    money.Money
+	Paid      money.Money
+}
+
+// IsMinimumPaid checks if the payments after the closing date reached the statement minimum payment.
+func (overdue *OverdueBalance) IsMinimumPaid() bool {
+	return !overdue.Paid.LessThan(overdue.Statement.MinimumPayment)
+}
+
+type CycleTotals struct {
+	Purchases         money.Money
+	Withdrawals       money.Money
+	Payments          money.Money
+	Other             money.Money
+	TransactionsCount int
+}
+
+func (totals *CycleTotals) Total() money.Money {
+	return totals.Purchases.Add(totals.Withdrawals).Add(totals.Payments).Add(totals.Other)
+}
+
+// NextClosingDate returns the first closing date on or after the given date.
+func NextClosingDate(from time.Time, closingDay int) time.Time {
+	from = truncateDate(from)
+
+	closingDate := time.Date(from.Year(), from.Month(), closingDay, 0, 0, 0, 0, time.UTC)
+	if closingDate.Before(from) {
+		closingDate = closingDate.AddDate(0, 1, 0)
+	}
+
+	return closingDate
+}
+
+// DueDate returns the first due day after the closing date.
+func DueDate(closingDate time.Time, dueDay int) time.Time {
+	dueDate := time.Date(closingDate.Year(), closingDate.Month(), dueDay, 0, 0, 0, 0, time.UTC)
+	if !dueDate.After(closingDate) {
+		dueDate = dueDate.AddDate(0, 1, 0)
+	}
+
+	return dueDate
+}
+
+// MinimumPayment is 15% of the amount due, but not less than the floor value
+// (or the whole amount due, when it is lower than the floor).
+func MinimumPayment(closingBalance money.Money) money.Money {
+	if !closingBalance.IsNegative() {
+		return money.NewFromInt(0)
+	}
+
+	due := closingBalance.Abs()
+	minimum := due.Convert(minimumPaymentRate)
+
+	if floor := money.Min(due, minimumPaymentFloor); minimum.LessThan(floor) {
+		return floor
+	}
+
+	return minimum
+}
+
+func truncateDate(date time.Time) time.Time {
+	date = date.UTC()
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
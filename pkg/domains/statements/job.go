@@ -0,0 +1,43 @@
+package statements
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Job closes the billing cycles periodically until the context is canceled.
+type Job struct {
+	service  Service
+	logger   *zerolog.Logger
+	interval time.Duration
+}
+
+func NewJob(service Service, logger *zerolog.Logger, interval time.Duration) *Job {
+	return &Job{
+		service:  service,
+		logger:   logger,
+		interval: interval,
+	}
+}
+
+func (job *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+
+	for {
+		created, err := job.service.CloseCycles(ctx, time.Now())
+		if err != nil && ctx.Err() == nil {
+			job.logger.Err(err).Msg("Failed to close billing cycles")
+		} else if created > 0 {
+			job.logger.Info().Int("statements", created).Msg("Billing cycles closed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/domains/statements/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source ./pkg/domains/statements/repository.go -destination ./pkg/domains/statements/mocks/repository_mock.go
+//
+// Package mock_statements is a generated GoMock package.
+package mock_statements
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	statements "github.com/rudineirk/pismo-challenge/pkg/domains/statements"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateStatement mocks base method.
+func (m *MockRepository) CreateStatement(arg0 context.Context, arg1 *statements.Statement) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateStatement", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateStatement indicates an expected call of CreateStatement.
+func (mr *MockRepositoryMockRecorder) CreateStatement(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStatement", reflect.TypeOf((*MockRepository)(nil).CreateStatement), arg0, arg1)
+}
+
+// GetCycleTotals mocks base method.
+func (m *MockRepository) GetCycleTotals(ctx context.Context, accountID int64, from, to time.Time) (*statements.CycleTotals, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCycleTotals", ctx, accountID, from, to)
+	ret0, _ := ret[0].(*statements.CycleTotals)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCycleTotals indicates an expected call of GetCycleTotals.
+func (mr *MockRepositoryMockRecorder) GetCycleTotals(ctx, accountID, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCycleTotals", reflect.TypeOf((*MockRepository)(nil).GetCycleTotals), ctx, accountID, from, to)
+}
+
+// GetLastDueStatement mocks base method.
+func (m *MockRepository) GetLastDueStatement(ctx context.Context, accountID int64, date time.Time) (*statements.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastDueStatement", ctx, accountID, date)
+	ret0, _ := ret[0].(*statements.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastDueStatement indicates an expected call of GetLastDueStatement.
+func (mr *MockRepositoryMockRecorder) GetLastDueStatement(ctx, accountID, date any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastDueStatement", reflect.TypeOf((*MockRepository)(nil).GetLastDueStatement), ctx, accountID, date)
+}
+
+// GetLastStatement mocks base method.
+func (m *MockRepository) GetLastStatement(ctx context.Context, accountID int64) (*statements.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastStatement", ctx, accountID)
+	ret0, _ := ret[0].(*statements.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastStatement indicates an expected call of GetLastStatement.
+func (mr *MockRepositoryMockRecorder) GetLastStatement(ctx, accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastStatement", reflect.TypeOf((*MockRepository)(nil).GetLastStatement), ctx, accountID)
+}
+
+// GetStatementByID mocks base method.
+func (m *MockRepository) GetStatementByID(arg0 context.Context, arg1 int64) (*statements.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatementByID", arg0, arg1)
+	ret0, _ := ret[0].(*statements.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatementByID indicates an expected call of GetStatementByID.
+func (mr *MockRepositoryMockRecorder) GetStatementByID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatementByID", reflect.TypeOf((*MockRepository)(nil).GetStatementByID), arg0, arg1)
+}
+
+// ListAccountStatements mocks base method.
+func (m *MockRepository) ListAccountStatements(ctx context.Context, accountID int64) ([]*statements.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountStatements", ctx, accountID)
+	ret0, _ := ret[0].([]*statements.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountStatements indicates an expected call of ListAccountStatements.
+func (mr *MockRepositoryMockRecorder) ListAccountStatements(ctx, accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountStatements", reflect.TypeOf((*MockRepository)(nil).ListAccountStatements), ctx, accountID)
+}
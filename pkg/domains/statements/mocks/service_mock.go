@@ -0,0 +1,101 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/domains/statements/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source ./pkg/domains/statements/service.go -destination ./pkg/domains/statements/mocks/service_mock.go
+//
+// Package mock_statements is a generated GoMock package.
+package mock_statements
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	statements "github.com/rudineirk/pismo-challenge/pkg/domains/statements"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// CloseCycles mocks base method.
+func (m *MockService) CloseCycles(ctx context.Context, now time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseCycles", ctx, now)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseCycles indicates an expected call of CloseCycles.
+func (mr *MockServiceMockRecorder) CloseCycles(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseCycles", reflect.TypeOf((*MockService)(nil).CloseCycles), ctx, now)
+}
+
+// GetOverdueBalance mocks base method.
+func (m *MockService) GetOverdueBalance(ctx context.Context, accountID int64, date time.Time) (*statements.OverdueBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOverdueBalance", ctx, accountID, date)
+	ret0, _ := ret[0].(*statements.OverdueBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOverdueBalance indicates an expected call of GetOverdueBalance.
+func (mr *MockServiceMockRecorder) GetOverdueBalance(ctx, accountID, date any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverdueBalance", reflect.TypeOf((*MockService)(nil).GetOverdueBalance), ctx, accountID, date)
+}
+
+// GetStatement mocks base method.
+func (m *MockService) GetStatement(arg0 context.Context, arg1 int64) (*statements.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatement", arg0, arg1)
+	ret0, _ := ret[0].(*statements.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatement indicates an expected call of GetStatement.
+func (mr *MockServiceMockRecorder) GetStatement(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatement", reflect.TypeOf((*MockService)(nil).GetStatement), arg0, arg1)
+}
+
+// ListAccountStatements mocks base method.
+func (m *MockService) ListAccountStatements(ctx context.Context, accountID int64) ([]*statements.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountStatements", ctx, accountID)
+	ret0, _ := ret[0].([]*statements.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountStatements indicates an expected call of ListAccountStatements.
+func (mr *MockServiceMockRecorder) ListAccountStatements(ctx, accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountStatements", reflect.TypeOf((*MockService)(nil).ListAccountStatements), ctx, accountID)
+}
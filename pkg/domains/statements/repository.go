@@ -0,0 +1,272 @@
+package statements
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/database"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/uptrace/bun"
+)
+
+type Repository interface {
+	CreateStatement(context.Context, *Statement) error
+	GetStatementByID(context.Context, int64) (*Statement, error)
+	GetLastStatement(ctx context.Context, accountID int64) (*Statement, error)
+	GetLastDueStatement(ctx context.Context, accountID int64, date time.Time) (*Statement, error)
+	ListAccountStatements(ctx context.Context, accountID int64) ([]*Statement, error)
+	GetCycleTotals(ctx context.Context, accountID int64, from time.Time, to time.Time) (*CycleTotals, error)
+}
+
+type StatementModel struct {
+	bun.BaseModel     `bun:"table:statements"`
+	ID                int64       `bun:"id,pk,autoincrement"`
+	AccountID         int64       `bun:"account_id"`
+	Currency          string      `bun:"currency"`
+	PeriodStart       time.Time   `bun:"period_start,type:date"`
+	ClosingDate       time.Time   `bun:"closing_date,type:date"`
+	DueDate           time.Time   `bun:"due_date,type:date"`
+	OpeningBalance    money.Money `bun:"opening_balance"`
+	Purchases         money.Money `bun:"purchases"`
+	Withdrawals       money.Money `bun:"withdrawals"`
+	Payments          money.Money `bun:"payments"`
+	Other             money.Money `bun:"other"`
+	ClosingBalance    money.Money `bun:"closing_balance"`
+	MinimumPayment    money.Money `bun:"minimum_payment"`
+	TransactionsCount int         `bun:"transactions_count"`
+	CreatedAt         time.Time   `bun:"created_at"`
+}
+
+func NewModelFromEntity(statement *Statement) *StatementModel {
+	return &StatementModel{
+		ID:                statement.ID,
+		AccountID:         statement.AccountID,
+		Currency:          statement.Currency,
+		PeriodStart:       statement.PeriodStart,
+		ClosingDate:       statement.ClosingDate,
+		DueDate:           statement.DueDate,
+		OpeningBalance:    statement.OpeningBalance,
+		Purchases:         statement.Purchases,
+		Withdrawals:       statement.Withdrawals,
+		Payments:          statement.Payments,
+		Other:             statement.Other,
+		ClosingBalance:    statement.ClosingBalance,
+		MinimumPayment:    statement.MinimumPayment,
+		TransactionsCount: statement.TransactionsCount,
+		CreatedAt:         statement.CreatedAt,
+	}
+}
+
+func (model *StatementModel) ToEntity() *Statement {
+	return &Statement{
+		ID:                model.ID,
+		AccountID:         model.AccountID,
+		Currency:          model.Currency,
+		PeriodStart:       model.PeriodStart.UTC(),
+		ClosingDate:       model.ClosingDate.UTC(),
+		DueDate:           model.DueDate.UTC(),
+		OpeningBalance:    model.OpeningBalance,
+		Purchases:         model.Purchases,
+		Withdrawals:       model.Withdrawals,
+		Payments:          model.Payments,
+		Other:             model.Other,
+		ClosingBalance:    model.ClosingBalance,
+		MinimumPayment:    model.MinimumPayment,
+		TransactionsCount: model.TransactionsCount,
+		CreatedAt:         model.CreatedAt,
+	}
+}
+
+type CycleTotalsModel struct {
+	Purchases         money.Money `bun:"purchases"`
+	Withdrawals       money.Money `bun:"withdrawals"`
+	Payments          money.Money `bun:"payments"`
+	Total             money.Money `bun:"total"`
+	TransactionsCount int         `bun:"transactions_count"`
+}
+
+type dbRepository struct {
+	bunDB *bun.DB
+}
+
+func NewRepository(bunDB *bun.DB) Repository {
+	return &dbRepository{bunDB}
+}
+
+func (repo *dbRepository) CreateStatement(ctx context.Context, statement *Statement) error {
+	statementModel := NewModelFromEntity(statement)
+
+	_, err := database.Conn(ctx, repo.bunDB).NewInsert().
+		Model(statementModel).
+		Exec(ctx)
+
+	if err != nil && strings.Contains(err.Error(), "unique constraint") {
+		return errorlib.ErrDuplicated(err)
+	} else if err != nil {
+		return err
+	}
+
+	statement.ID = statementModel.ID
+
+	return nil
+}
+
+func (repo *dbRepository) GetStatementByID(ctx context.Context, id int64) (*Statement, error) {
+	return repo.getStatement(ctx, func(query *bun.SelectQuery) *bun.SelectQuery {
+		return query.Where("id = ?", id)
+	})
+}
+
+func (repo *dbRepository) GetLastStatement(ctx context.Context, accountID int64) (*Statement, error) {
+	return repo.getStatement(ctx, func(query *bun.SelectQuery) *bun.SelectQuery {
+		return query.
+			Where("account_id = ?", accountID).
+			Order("closing_date DESC").
+			Limit(1)
+	})
+}
+
+// GetLastDueStatement returns the most recent statement with the due date before the given date.
+func (repo *dbRepository) GetLastDueStatement(
+	ctx context.Context,
+	accountID int64,
+	date time.Time,
+) (*Statement, error) {
+	return repo.getStatement(ctx, func(query *bun.SelectQuery) *bun.SelectQuery {
+		return query.
+			Where("account_id = ?", accountID).
+			Where("due_date < ?", date).
+			Order("closing_date DESC").
+			Limit(1)
+	})
+}
+
+func (repo *dbRepository) getStatement(
+	ctx context.Context,
+	filter func(*bun.SelectQuery) *bun.SelectQuery,
+) (*Statement, error) {
+	statementModel := StatementModel{}
+
+	query := database.Conn(ctx, repo.bunDB).NewSelect().
+		Model(&statementModel)
+
+	err := filter(query).Scan(ctx)
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return nil, errorlib.ErrNotFound(err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return statementModel.ToEntity(), nil
+}
+
+func (repo *dbRepository) ListAccountStatements(ctx context.Context, accountID int64) ([]*Statement, error) {
+	statementModels := []*StatementModel{}
+
+	err := database.Conn(ctx, repo.bunDB).NewSelect().
+		Model(&statementModels).
+		Where("account_id = ?", accountID).
+		Order("closing_date DESC").
+		Scan(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	statements := make([]*Statement, 0, len(statementModels))
+	for _, model := range statementModels {
+		statements = append(statements, model.ToEntity())
+	}
+
+	return statements, nil
+}
+
+// GetCycleTotals sums the account transactions with event date in the [from, to) range. The installment
+// purchases with a plan are billed by their schedule, with the installments due in the range. The first
+// reversal of a plan cancels its installments not billed yet, and bills what is left of the plan at once.
+func (repo *dbRepository) GetCycleTotals(
+	ctx context.Context,
+	accountID int64,
+	from time.Time,
+	to time.Time,
+) (*CycleTotals, error) {
+	conn := database.Conn(ctx, repo.bunDB)
+	totalsModel := CycleTotalsModel{}
+
+	installments := conn.NewSelect().
+		TableExpr("installments AS i").
+		Join("JOIN installment_plans AS p ON p.id = i.plan_id").
+		ColumnExpr("? AS operation_type_id", operationtypes.InstallmentType).
+		ColumnExpr("i.amount").
+		ColumnExpr("0 AS counted").
+		Where("p.account_id = ?", accountID).
+		Where("i.due_date >= ?", from).
+		Where("i.due_date < ?", to).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM transactions AS r
+			WHERE r.reverses_transaction_id = p.transaction_id AND r.event_date <= i.due_date
+		)`)
+
+	entries := conn.NewSelect().
+		TableExpr("transactions AS t").
+		Join("LEFT JOIN installment_plans AS p ON p.transaction_id = COALESCE(t.reverses_transaction_id, t.id)").
+		ColumnExpr("t.operation_type_id").
+		ColumnExpr(`CASE
+			WHEN p.id IS NULL THEN t.amount
+			WHEN t.reverses_transaction_id IS NULL THEN 0
+			WHEN EXISTS (
+				SELECT 1 FROM transactions AS r
+				WHERE r.reverses_transaction_id = t.reverses_transaction_id
+					AND (r.event_date, r.id) < (t.event_date, t.id)
+			) THEN t.amount
+			ELSE t.amount + (
+				SELECT COALESCE(SUM(i.amount), 0) FROM installments AS i
+				WHERE i.plan_id = p.id AND i.due_date >= t.event_date
+			)
+		END AS amount`).
+		ColumnExpr("1 AS counted").
+		Where("t.account_id = ?", accountID).
+		Where("t.event_date >= ?", from).
+		Where("t.event_date < ?", to).
+		UnionAll(installments)
+
+	err := conn.NewSelect().
+		With("entries", entries).
+		TableExpr("entries").
+		ColumnExpr(
+			"COALESCE(SUM(amount) FILTER (WHERE operation_type_id IN (?, ?)), 0) AS purchases",
+			operationtypes.CashPurchaseType,
+			operationtypes.InstallmentType,
+		).
+		ColumnExpr(
+			"COALESCE(SUM(amount) FILTER (WHERE operation_type_id = ?), 0) AS withdrawals",
+			operationtypes.WithdrawType,
+		).
+		ColumnExpr(
+			"COALESCE(SUM(amount) FILTER (WHERE operation_type_id = ?), 0) AS payments",
+			operationtypes.PaymentType,
+		).
+		ColumnExpr("COALESCE(SUM(amount), 0) AS total").
+		ColumnExpr("COALESCE(SUM(counted), 0) AS transactions_count").
+		Scan(ctx, &totalsModel)
+
+	if err != nil {
+		return nil, err
+	}
+
+	totals := &CycleTotals{
+		Purchases:         totalsModel.Purchases,
+		Withdrawals:       totalsModel.Withdrawals,
+		Payments:          totalsModel.Payments,
+		TransactionsCount: totalsModel.TransactionsCount,
+	}
+
+	totals.Other = totalsModel.Total.Sub(totals.Purchases).Sub(totals.Withdrawals).Sub(totals.Payments)
+
+	return totals, nil
+}
@@ -0,0 +1,169 @@
+package statements
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+)
+
+const accountsBatchSize = 100
+
+type Service interface {
+	CloseCycles(ctx context.Context, now time.Time) (int, error)
+	GetStatement(context.Context, int64) (*Statement, error)
+	ListAccountStatements(ctx context.Context, accountID int64) ([]*Statement, error)
+	GetOverdueBalance(ctx context.Context, accountID int64, date time.Time) (*OverdueBalance, error)
+}
+
+type statementsService struct {
+	repo        Repository
+	accountsSvc accounts.Service
+}
+
+func NewService(repo Repository, accountsSvc accounts.Service) Service {
+	return &statementsService{
+		repo:        repo,
+		accountsSvc: accountsSvc,
+	}
+}
+
+// CloseCycles creates the statements of all the billing cycles closed before
+// the given date, returning the number of created statements.
+func (svc *statementsService) CloseCycles(ctx context.Context, now time.Time) (int, error) {
+	created := 0
+	afterID := int64(0)
+
+	for {
+		accountsBatch, err := svc.accountsSvc.ListAccounts(ctx, afterID, accountsBatchSize)
+		if err != nil {
+			return created, err
+		}
+
+		for _, account := range accountsBatch {
+			closed, err := svc.closeAccountCycles(ctx, account, now)
+			created += closed
+
+			if err != nil {
+				return created, err
+			}
+		}
+
+		if len(accountsBatch) < accountsBatchSize {
+			return created, nil
+		}
+
+		afterID = accountsBatch[len(accountsBatch)-1].ID
+	}
+}
+
+func (svc *statementsService) closeAccountCycles(
+	ctx context.Context,
+	account *accounts.Account,
+	now time.Time,
+) (int, error) {
+	periodStart := truncateDate(account.CreatedAt)
+	openingBalance := money.NewFromInt(0)
+
+	lastStatement, err := svc.repo.GetLastStatement(ctx, account.ID)
+	if err == nil {
+		periodStart = lastStatement.ClosingDate.AddDate(0, 0, 1)
+		openingBalance = lastStatement.ClosingBalance
+	} else if !errors.Is(err, errorlib.ErrNotFound(nil)) {
+		return 0, err
+	}
+
+	today := truncateDate(now)
+	created := 0
+
+	for {
+		closingDate := NextClosingDate(periodStart, account.ClosingDay)
+		if !closingDate.Before(today) {
+			return created, nil
+		}
+
+		periodEnd := closingDate.AddDate(0, 0, 1)
+
+		totals, err := svc.repo.GetCycleTotals(ctx, account.ID, periodStart, periodEnd)
+		if err != nil {
+			return created, err
+		}
+
+		closingBalance := openingBalance.Add(totals.Total())
+		statement := &Statement{
+			AccountID:         account.ID,
+			Currency:          account.Currency,
+			PeriodStart:       periodStart,
+			ClosingDate:       closingDate,
+			DueDate:           DueDate(closingDate, account.DueDay),
+			OpeningBalance:    openingBalance,
+			Purchases:         totals.Purchases,
+			Withdrawals:       totals.Withdrawals,
+			Payments:          totals.Payments,
+			Other:             totals.Other,
+			ClosingBalance:    closingBalance,
+			MinimumPayment:    MinimumPayment(closingBalance),
+			TransactionsCount: totals.TransactionsCount,
+			CreatedAt:         now,
+		}
+
+		err = svc.repo.CreateStatement(ctx, statement)
+		if errors.Is(err, errorlib.ErrDuplicated(nil)) {
+			// another job instance already closed this cycle
+			return created, nil
+		} else if err != nil {
+			return created, err
+		}
+
+		created++
+		periodStart = periodEnd
+		openingBalance = closingBalance
+	}
+}
+
+func (svc *statementsService) GetStatement(ctx context.Context, id int64) (*Statement, error) {
+	return svc.repo.GetStatementByID(ctx, id)
+}
+
+func (svc *statementsService) ListAccountStatements(ctx context.Context, accountID int64) ([]*Statement, error) {
+	if _, err := svc.accountsSvc.GetAccountByID(ctx, accountID); err != nil {
+		return nil, err
+	}
+
+	return svc.repo.ListAccountStatements(ctx, accountID)
+}
+
+// GetOverdueBalance calculates the overdue balance at the start of the given date, using the
+// last statement already due. Returns a not found error if the account has no due statements.
+func (svc *statementsService) GetOverdueBalance(
+	ctx context.Context,
+	accountID int64,
+	date time.Time,
+) (*OverdueBalance, error) {
+	date = truncateDate(date)
+
+	statement, err := svc.repo.GetLastDueStatement(ctx, accountID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := svc.repo.GetCycleTotals(ctx, accountID, statement.ClosingDate.AddDate(0, 0, 1), date)
+	if err != nil {
+		return nil, err
+	}
+
+	// purchases and withdrawals after the closing date belong to the next statement
+	amount := statement.ClosingBalance.Neg().Sub(totals.Payments).Sub(totals.Other)
+	if amount.IsNegative() {
+		amount = money.NewFromInt(0)
+	}
+
+	return &OverdueBalance{
+		Statement: statement,
+		Amount:    amount,
+		Paid:      totals.Payments,
+	}, nil
+}
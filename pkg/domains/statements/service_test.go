@@ -0,0 +1,276 @@
+package statements_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
+	accountMocks "github.com/rudineirk/pismo-challenge/pkg/domains/accounts/mocks"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/statements"
+	mocks "github.com/rudineirk/pismo-challenge/pkg/domains/statements/mocks"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	assert "github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestNextClosingDate(t *testing.T) {
+	assert.Equal(t, date(2023, 11, 25), statements.NextClosingDate(date(2023, 11, 10), 25))
+	assert.Equal(t, date(2023, 11, 25), statements.NextClosingDate(date(2023, 11, 25), 25))
+	assert.Equal(t, date(2023, 12, 25), statements.NextClosingDate(date(2023, 11, 26), 25))
+	assert.Equal(t, date(2024, 1, 5), statements.NextClosingDate(date(2023, 12, 6), 5))
+	assert.Equal(t, date(2023, 11, 25), statements.NextClosingDate(time.Date(2023, 11, 25, 23, 59, 0, 0, time.UTC), 25))
+}
+
+func TestDueDate(t *testing.T) {
+	assert.Equal(t, date(2023, 12, 5), statements.DueDate(date(2023, 11, 25), 5))
+	assert.Equal(t, date(2023, 11, 28), statements.DueDate(date(2023, 11, 10), 28))
+	assert.Equal(t, date(2023, 12, 10), statements.DueDate(date(2023, 11, 10), 10))
+	assert.Equal(t, date(2024, 1, 5), statements.DueDate(date(2023, 12, 25), 5))
+}
+
+func TestMinimumPayment(t *testing.T) {
+	assert.Equal(t, "0.00", statements.MinimumPayment(money.MustParse("120")).String())
+	assert.Equal(t, "0.00", statements.MinimumPayment(money.NewFromInt(0)).String())
+	assert.Equal(t, "150.00", statements.MinimumPayment(money.MustParse("-1000")).String())
+	assert.Equal(t, "10.00", statements.MinimumPayment(money.MustParse("-50")).String())
+	assert.Equal(t, "7.50", statements.MinimumPayment(money.MustParse("-7.5")).String())
+	assert.Equal(t, "18.52", statements.MinimumPayment(money.MustParse("-123.45")).String())
+}
+
+func TestCloseCycles(t *testing.T) {
+	setupMocks := func(t *testing.T) (statements.Service, *mocks.MockRepository, *accountMocks.MockService) {
+		mockCtrl := gomock.NewController(t)
+		repo := mocks.NewMockRepository(mockCtrl)
+		accountsSvc := accountMocks.NewMockService(mockCtrl)
+
+		return statements.NewService(repo, accountsSvc), repo, accountsSvc
+	}
+
+	account := &accounts.Account{
+		ID:         1,
+		Currency:   "BRL",
+		ClosingDay: 25,
+		DueDay:     5,
+		CreatedAt:  time.Date(2023, 10, 3, 14, 30, 0, 0, time.UTC),
+	}
+
+	t.Run("should close all the pending cycles since the account creation", func(t *testing.T) {
+		svc, repo, accountsSvc := setupMocks(t)
+		ctx := context.TODO()
+		now := time.Date(2023, 11, 27, 3, 0, 0, 0, time.UTC)
+
+		accountsSvc.EXPECT().ListAccounts(ctx, int64(0), gomock.Any()).
+			Return([]*accounts.Account{account}, nil)
+		repo.EXPECT().GetLastStatement(ctx, account.ID).
+			Return(nil, errorlib.ErrNotFound(nil))
+
+		repo.EXPECT().GetCycleTotals(ctx, account.ID, date(2023, 10, 3), date(2023, 10, 26)).
+			Return(&statements.CycleTotals{
+				Purchases:         money.MustParse("-200"),
+				Payments:          money.MustParse("50"),
+				TransactionsCount: 3,
+			}, nil)
+		repo.EXPECT().GetCycleTotals(ctx, account.ID, date(2023, 10, 26), date(2023, 11, 26)).
+			Return(&statements.CycleTotals{
+				Purchases:         money.MustParse("-100"),
+				Withdrawals:       money.MustParse("-20"),
+				Payments:          money.MustParse("150"),
+				Other:             money.MustParse("5"),
+				TransactionsCount: 4,
+			}, nil)
+
+		created := []*statements.Statement{}
+		repo.EXPECT().CreateStatement(ctx, gomock.Any()).
+			Do(func(_ context.Context, statement *statements.Statement) {
+				created = append(created, statement)
+			}).
+			Return(nil).
+			Times(2)
+
+		count, err := svc.CloseCycles(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.Len(t, created, 2)
+
+		assert.Equal(t, date(2023, 10, 3), created[0].PeriodStart)
+		assert.Equal(t, date(2023, 10, 25), created[0].ClosingDate)
+		assert.Equal(t, date(2023, 11, 5), created[0].DueDate)
+		assert.Equal(t, "0.00", created[0].OpeningBalance.String())
+		assert.Equal(t, "-150.00", created[0].ClosingBalance.String())
+		assert.Equal(t, "22.50", created[0].MinimumPayment.String())
+		assert.Equal(t, 3, created[0].TransactionsCount)
+
+		assert.Equal(t, date(2023, 10, 26), created[1].PeriodStart)
+		assert.Equal(t, date(2023, 11, 25), created[1].ClosingDate)
+		assert.Equal(t, date(2023, 12, 5), created[1].DueDate)
+		assert.Equal(t, "-150.00", created[1].OpeningBalance.String())
+		assert.Equal(t, "-115.00", created[1].ClosingBalance.String())
+		assert.Equal(t, "17.25", created[1].MinimumPayment.String())
+		assert.Equal(t, "BRL", created[1].Currency)
+	})
+
+	t.Run("should continue from the last statement", func(t *testing.T) {
+		svc, repo, accountsSvc := setupMocks(t)
+		ctx := context.TODO()
+		now := time.Date(2023, 12, 26, 0, 0, 0, 0, time.UTC)
+
+		accountsSvc.EXPECT().ListAccounts(ctx, int64(0), gomock.Any()).
+			Return([]*accounts.Account{account}, nil)
+		repo.EXPECT().GetLastStatement(ctx, account.ID).
+			Return(&statements.Statement{
+				ClosingDate:    date(2023, 11, 25),
+				ClosingBalance: money.MustParse("-115"),
+			}, nil)
+		repo.EXPECT().GetCycleTotals(ctx, account.ID, date(2023, 11, 26), date(2023, 12, 26)).
+			Return(&statements.CycleTotals{Payments: money.MustParse("115")}, nil)
+
+		var statement *statements.Statement
+		repo.EXPECT().CreateStatement(ctx, gomock.Any()).
+			Do(func(_ context.Context, created *statements.Statement) {
+				statement = created
+			}).
+			Return(nil)
+
+		count, err := svc.CloseCycles(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, "-115.00", statement.OpeningBalance.String())
+		assert.Equal(t, "0.00", statement.ClosingBalance.String())
+		assert.Equal(t, "0.00", statement.MinimumPayment.String())
+	})
+
+	t.Run("should not close the cycle on its closing date", func(t *testing.T) {
+		svc, repo, accountsSvc := setupMocks(t)
+		ctx := context.TODO()
+		now := time.Date(2023, 12, 25, 23, 0, 0, 0, time.UTC)
+
+		accountsSvc.EXPECT().ListAccounts(ctx, int64(0), gomock.Any()).
+			Return([]*accounts.Account{account}, nil)
+		repo.EXPECT().GetLastStatement(ctx, account.ID).
+			Return(&statements.Statement{ClosingDate: date(2023, 11, 25)}, nil)
+
+		count, err := svc.CloseCycles(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("should skip the account when the cycle was already closed", func(t *testing.T) {
+		svc, repo, accountsSvc := setupMocks(t)
+		ctx := context.TODO()
+		now := time.Date(2023, 12, 26, 0, 0, 0, 0, time.UTC)
+
+		accountsSvc.EXPECT().ListAccounts(ctx, int64(0), gomock.Any()).
+			Return([]*accounts.Account{account}, nil)
+		repo.EXPECT().GetLastStatement(ctx, account.ID).
+			Return(&statements.Statement{ClosingDate: date(2023, 11, 25)}, nil)
+		repo.EXPECT().GetCycleTotals(ctx, account.ID, gomock.Any(), gomock.Any()).
+			Return(&statements.CycleTotals{}, nil)
+		repo.EXPECT().CreateStatement(ctx, gomock.Any()).
+			Return(errorlib.ErrDuplicated(nil))
+
+		count, err := svc.CloseCycles(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("should return the repository errors", func(t *testing.T) {
+		svc, repo, accountsSvc := setupMocks(t)
+		ctx := context.TODO()
+		expectedErr := errors.New("connection refused")
+
+		accountsSvc.EXPECT().ListAccounts(ctx, int64(0), gomock.Any()).
+			Return([]*accounts.Account{account}, nil)
+		repo.EXPECT().GetLastStatement(ctx, account.ID).
+			Return(nil, expectedErr)
+
+		_, err := svc.CloseCycles(ctx, time.Now())
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestListAccountStatements(t *testing.T) {
+	t.Run("should return not found for unknown accounts", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		repo := mocks.NewMockRepository(mockCtrl)
+		accountsSvc := accountMocks.NewMockService(mockCtrl)
+		svc := statements.NewService(repo, accountsSvc)
+		ctx := context.TODO()
+
+		accountsSvc.EXPECT().GetAccountByID(ctx, int64(10)).
+			Return(nil, errorlib.ErrNotFound(nil))
+
+		_, err := svc.ListAccountStatements(ctx, 10)
+		assert.ErrorIs(t, err, errorlib.ErrNotFound(nil))
+	})
+}
+
+func TestGetOverdueBalance(t *testing.T) {
+	setupMocks := func(t *testing.T) (statements.Service, *mocks.MockRepository) {
+		mockCtrl := gomock.NewController(t)
+		repo := mocks.NewMockRepository(mockCtrl)
+
+		return statements.NewService(repo, accountMocks.NewMockService(mockCtrl)), repo
+	}
+
+	statement := &statements.Statement{
+		ID:             1,
+		ClosingDate:    date(2023, 11, 25),
+		DueDate:        date(2023, 12, 5),
+		ClosingBalance: money.MustParse("-1000"),
+		MinimumPayment: money.MustParse("150"),
+	}
+
+	t.Run("should discount the payments and add the charges after the closing date", func(t *testing.T) {
+		svc, repo := setupMocks(t)
+		ctx := context.TODO()
+
+		repo.EXPECT().GetLastDueStatement(ctx, int64(1), date(2023, 12, 8)).
+			Return(statement, nil)
+		repo.EXPECT().GetCycleTotals(ctx, int64(1), date(2023, 11, 26), date(2023, 12, 8)).
+			Return(&statements.CycleTotals{
+				Purchases: money.MustParse("-300"),
+				Payments:  money.MustParse("100"),
+				Other:     money.MustParse("-33.78"),
+			}, nil)
+
+		overdue, err := svc.GetOverdueBalance(ctx, 1, time.Date(2023, 12, 8, 15, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		assert.Equal(t, "933.78", overdue.Amount.String())
+		assert.Equal(t, "100.00", overdue.Paid.String())
+		assert.False(t, overdue.IsMinimumPaid())
+	})
+
+	t.Run("should return zero if the statement was paid", func(t *testing.T) {
+		svc, repo := setupMocks(t)
+		ctx := context.TODO()
+
+		repo.EXPECT().GetLastDueStatement(ctx, int64(1), gomock.Any()).
+			Return(statement, nil)
+		repo.EXPECT().GetCycleTotals(ctx, int64(1), gomock.Any(), gomock.Any()).
+			Return(&statements.CycleTotals{Payments: money.MustParse("1200")}, nil)
+
+		overdue, err := svc.GetOverdueBalance(ctx, 1, date(2023, 12, 8))
+		assert.NoError(t, err)
+		assert.True(t, overdue.Amount.IsZero())
+		assert.True(t, overdue.IsMinimumPaid())
+	})
+
+	t.Run("should return not found if there are no due statements", func(t *testing.T) {
+		svc, repo := setupMocks(t)
+		ctx := context.TODO()
+
+		repo.EXPECT().GetLastDueStatement(ctx, int64(1), gomock.Any()).
+			Return(nil, errorlib.ErrNotFound(nil))
+
+		_, err := svc.GetOverdueBalance(ctx, 1, date(2023, 12, 8))
+		assert.ErrorIs(t, err, errorlib.ErrNotFound(nil))
+	})
+}
@@ -2,67 +2,375 @@ package transactions
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/httprouter"
 	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/validation"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	ContentTypeNDJSON = "application/x-ndjson"
+	ContentTypeCSV    = "text/csv"
+	ContentTypeOFX    = "application/x-ofx"
 )
 
 type httpHandler struct {
 	service Service
 }
 
-func SetupHTTPRoutes(router *gin.Engine, service Service) {
+func SetupHTTPRoutes(router *gin.Engine, service Service, middlewares ...gin.HandlerFunc) {
 	handler := httpHandler{
 		service: service,
 	}
 
-	routeGroup := router.Group("/transactions")
+	routeGroup := router.Group("/transactions", middlewares...)
 	routeGroup.POST("", handler.CreateTransaction)
+	routeGroup.POST("/batch", handler.ImportTransactions)
+	routeGroup.POST("/:transaction_id/reversal", handler.ReverseTransaction)
+	routeGroup.GET("/:transaction_id/installments", handler.GetInstallmentPlan)
+
+	router.GET("/accounts/:account_id/transactions", handler.ListAccountTransactions)
+	router.GET("/accounts/:account_id/transactions/export", handler.ExportAccountTransactions)
 }
 
 func (handler *httpHandler) CreateTransaction(ctx *gin.Context) {
 	req := CreateTransactionRequest{}
-	if err := ctx.BindJSON(&req); err != nil {
+	if err := httprouter.BindJSON(ctx, &req); err != nil {
 		return
 	}
 
 	account, err := handler.service.CreateTransaction(ctx, &req)
 
 	if err != nil {
-		isBadRequest := errors.Is(err, ErrAccountIDNotFound(nil)) ||
-			errors.Is(err, ErrInvalidOperationTypeID(nil)) ||
-			errors.Is(err, ErrInvalidAmount(nil)) ||
-			errors.Is(err, errorlib.ErrInvalidPayload(nil))
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
 
-		if isBadRequest {
-			ctx.JSON(http.StatusBadRequest, err)
-		} else {
-			_ = ctx.AbortWithError(http.StatusInternalServerError, err)
-		}
+	ctx.JSON(http.StatusCreated, NewAPIResponseFromEntity(account))
+}
 
+func (handler *httpHandler) ImportTransactions(ctx *gin.Context) {
+	req := ImportTransactionsRequest{}
+	if err := httprouter.BindQuery(ctx, &req); err != nil {
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, NewAPIResponseFromEntity(account))
+	switch ctx.ContentType() {
+	case ContentTypeNDJSON:
+		req.Format = BatchFormatNDJSON
+	case ContentTypeCSV:
+		req.Format = BatchFormatCSV
+	default:
+		httprouter.AbortWithError(ctx, ErrUnsupportedBatchFormat(nil))
+		return
+	}
+
+	req.Body = ctx.Request.Body
+
+	result, err := handler.service.ImportTransactions(ctx, &req)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, NewBatchAPIResponseFromEntity(result))
+}
+
+func (handler *httpHandler) ReverseTransaction(ctx *gin.Context) {
+	transactionIDRaw := ctx.Param("transaction_id")
+
+	transactionID, err := strconv.ParseInt(transactionIDRaw, 10, 64)
+	if err != nil {
+		httprouter.AbortWithError(ctx, errorlib.ErrNotFound(err))
+		return
+	}
+
+	req := ReverseTransactionRequest{}
+	if err := httprouter.ShouldBindJSON(ctx, &req); err != nil && !errors.Is(err, io.EOF) {
+		httprouter.AbortWithError(ctx, errorlib.ErrInvalidPayload(err))
+		return
+	}
+
+	req.TransactionID = transactionID
+
+	transaction, err := handler.service.ReverseTransaction(ctx, &req)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, NewAPIResponseFromEntity(transaction))
+}
+
+func (handler *httpHandler) GetInstallmentPlan(ctx *gin.Context) {
+	transactionIDRaw := ctx.Param("transaction_id")
+
+	transactionID, err := strconv.ParseInt(transactionIDRaw, 10, 64)
+	if err != nil {
+		httprouter.AbortWithError(ctx, errorlib.ErrNotFound(err))
+		return
+	}
+
+	plan, err := handler.service.GetInstallmentPlan(ctx, transactionID)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, NewInstallmentPlanAPIResponseFromEntity(plan))
+}
+
+func (handler *httpHandler) ListAccountTransactions(ctx *gin.Context) {
+	accountIDRaw := ctx.Param("account_id")
+
+	accountID, err := strconv.ParseInt(accountIDRaw, 10, 64)
+	if err != nil {
+		httprouter.AbortWithError(ctx, errorlib.ErrNotFound(err))
+		return
+	}
+
+	req := ListTransactionsRequest{}
+	if err := httprouter.BindQuery(ctx, &req); err != nil {
+		return
+	}
+
+	req.AccountID = accountID
+
+	page, err := handler.service.ListAccountTransactions(ctx, &req)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, NewPageAPIResponseFromEntity(page))
+}
+
+func (handler *httpHandler) ExportAccountTransactions(ctx *gin.Context) {
+	accountIDRaw := ctx.Param("account_id")
+
+	accountID, err := strconv.ParseInt(accountIDRaw, 10, 64)
+	if err != nil {
+		httprouter.AbortWithError(ctx, errorlib.ErrNotFound(err))
+		return
+	}
+
+	req := ExportTransactionsRequest{}
+	if err := httprouter.BindQuery(ctx, &req); err != nil {
+		return
+	}
+
+	req.AccountID = accountID
+
+	contentType := ContentTypeCSV
+	if req.Format == ExportFormatOFX {
+		contentType = ContentTypeOFX
+	}
+
+	output := &exportWriter{
+		ctx:         ctx,
+		contentType: contentType,
+		filename:    fmt.Sprintf("account-%d-transactions.%s", accountID, req.Format),
+	}
+
+	// when the file was already partially sent the response can't be changed, and the error is only logged
+	if err := handler.service.ExportAccountTransactions(ctx, &req, output); err != nil {
+		httprouter.AbortWithError(ctx, err)
+	}
+}
+
+// exportWriter sets the file headers on the first write, so the errors returned before
+// streaming the file can still be sent as problem responses.
+type exportWriter struct {
+	ctx         *gin.Context
+	contentType string
+	filename    string
+}
+
+func (writer *exportWriter) Write(data []byte) (int, error) {
+	if !writer.ctx.Writer.Written() {
+		writer.ctx.Header("Content-Type", writer.contentType)
+		writer.ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", writer.filename))
+		writer.ctx.Status(http.StatusOK)
+	}
+
+	return writer.ctx.Writer.Write(data)
 }
 
 type TransactionAPIResponse struct {
 	TransactionID   int64               `json:"transaction_id"`
 	AccountID       int64               `json:"account_id"`
 	OperationTypeID operationtypes.Type `json:"operation_type_id"`
-	Amount          float64             `json:"amount"`
+	Amount          money.Money         `json:"amount"`
+	Balance         money.Money         `json:"balance"`
+	Currency        string              `json:"currency"`
 	EventDate       time.Time           `json:"event_date"`
+
+	FXConversion          *FXConversionAPIResponse `json:"fx_conversion"`
+	ReversesTransactionID *int64                   `json:"reverses_transaction_id"`
+}
+
+type FXConversionAPIResponse struct {
+	OriginalCurrency string          `json:"original_currency"`
+	OriginalAmount   money.Money     `json:"original_amount"`
+	Rate             decimal.Decimal `json:"rate"`
 }
 
 func NewAPIResponseFromEntity(transaction *Transaction) *TransactionAPIResponse {
-	return &TransactionAPIResponse{
+	resp := &TransactionAPIResponse{
 		TransactionID:   transaction.ID,
 		AccountID:       transaction.AccountID,
 		OperationTypeID: transaction.OperationTypeID,
 		Amount:          transaction.Amount,
+		Balance:         transaction.Balance,
+		Currency:        transaction.Currency,
 		EventDate:       transaction.EventDate,
+
+		ReversesTransactionID: transaction.ReversesTransactionID,
+	}
+
+	if conversion := transaction.FXConversion; conversion != nil {
+		resp.FXConversion = &FXConversionAPIResponse{
+			OriginalCurrency: conversion.Currency,
+			OriginalAmount:   conversion.Amount,
+			Rate:             conversion.Rate,
+		}
+	}
+
+	return resp
+}
+
+type TransactionsPageAPIResponse struct {
+	Items      []*TransactionAPIResponse `json:"items"`
+	NextCursor *string                   `json:"next_cursor"`
+}
+
+func NewPageAPIResponseFromEntity(page *TransactionsPage) *TransactionsPageAPIResponse {
+	resp := &TransactionsPageAPIResponse{
+		Items: make([]*TransactionAPIResponse, 0, len(page.Items)),
+	}
+
+	for _, transaction := range page.Items {
+		resp.Items = append(resp.Items, NewAPIResponseFromEntity(transaction))
 	}
+
+	if page.NextCursor != "" {
+		resp.NextCursor = &page.NextCursor
+	}
+
+	return resp
+}
+
+type BatchLineStatus string
+
+const (
+	BatchLineCreated    BatchLineStatus = "created"
+	BatchLineFailed     BatchLineStatus = "failed"
+	BatchLineRolledBack BatchLineStatus = "rolled_back"
+)
+
+type BatchAPIResponse struct {
+	Created    int                     `json:"created"`
+	Failed     int                     `json:"failed"`
+	RolledBack bool                    `json:"rolled_back"`
+	Lines      []*BatchLineAPIResponse `json:"lines"`
+}
+
+type BatchLineAPIResponse struct {
+	Line          int                        `json:"line"`
+	Status        BatchLineStatus            `json:"status"`
+	TransactionID *int64                     `json:"transaction_id,omitempty"`
+	Error         *BatchLineErrorAPIResponse `json:"error,omitempty"`
+}
+
+// BatchLineErrorAPIResponse lists the invalid fields of the line like the problem responses.
+type BatchLineErrorAPIResponse struct {
+	Code    string                  `json:"code"`
+	Message string                  `json:"message"`
+	Errors  []validation.FieldError `json:"errors,omitempty"`
+}
+
+func NewBatchAPIResponseFromEntity(result *BatchResult) *BatchAPIResponse {
+	resp := &BatchAPIResponse{
+		Created:    result.Created,
+		Failed:     result.Failed,
+		RolledBack: result.RolledBack,
+		Lines:      make([]*BatchLineAPIResponse, 0, len(result.Lines)),
+	}
+
+	for _, line := range result.Lines {
+		lineResp := &BatchLineAPIResponse{Line: line.Line}
+
+		if line.Err != nil {
+			lineResp.Status = BatchLineFailed
+			lineResp.Error = &BatchLineErrorAPIResponse{
+				Code:    line.Err.Code,
+				Message: line.Err.Message,
+				Errors:  validation.Fields(line.Err),
+			}
+		} else if line.Transaction != nil {
+			lineResp.Status = BatchLineCreated
+			lineResp.TransactionID = &line.Transaction.ID
+		} else {
+			lineResp.Status = BatchLineRolledBack
+		}
+
+		resp.Lines = append(resp.Lines, lineResp)
+	}
+
+	return resp
+}
+
+type InstallmentPlanAPIResponse struct {
+	PlanID         int64                     `json:"plan_id"`
+	TransactionID  int64                     `json:"transaction_id"`
+	AccountID      int64                     `json:"account_id"`
+	Installments   int                       `json:"installments"`
+	TotalAmount    money.Money               `json:"total_amount"`
+	PaidAmount     money.Money               `json:"paid_amount"`
+	ReversedAmount money.Money               `json:"reversed_amount"`
+	Schedule       []*InstallmentAPIResponse `json:"schedule"`
+}
+
+type InstallmentAPIResponse struct {
+	Number         int               `json:"number"`
+	Amount         money.Money       `json:"amount"`
+	PaidAmount     money.Money       `json:"paid_amount"`
+	ReversedAmount money.Money       `json:"reversed_amount"`
+	Status         InstallmentStatus `json:"status"`
+	DueDate        string            `json:"due_date"`
+}
+
+func NewInstallmentPlanAPIResponseFromEntity(plan *InstallmentPlan) *InstallmentPlanAPIResponse {
+	resp := &InstallmentPlanAPIResponse{
+		PlanID:         plan.ID,
+		TransactionID:  plan.TransactionID,
+		AccountID:      plan.AccountID,
+		Installments:   len(plan.Schedule),
+		TotalAmount:    plan.TotalAmount,
+		PaidAmount:     plan.PaidAmount,
+		ReversedAmount: plan.ReversedAmount,
+		Schedule:       make([]*InstallmentAPIResponse, 0, len(plan.Schedule)),
+	}
+
+	for _, installment := range plan.Schedule {
+		resp.Schedule = append(resp.Schedule, &InstallmentAPIResponse{
+			Number:         installment.Number,
+			Amount:         installment.Amount,
+			PaidAmount:     installment.PaidAmount,
+			ReversedAmount: installment.ReversedAmount,
+			Status:         installment.Status,
+			DueDate:        installment.DueDate.Format(time.DateOnly),
+		})
+	}
+
+	return resp
 }
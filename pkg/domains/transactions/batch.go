@@ -0,0 +1,326 @@
+package transactions
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/metrics"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/validation"
+)
+
+type BatchFormat string
+
+const (
+	BatchFormatNDJSON BatchFormat = "ndjson"
+	BatchFormatCSV    BatchFormat = "csv"
+)
+
+const (
+	maxBatchLines    = 50000
+	maxBatchLineSize = 64 * 1024
+	batchChunkSize   = 500
+
+	batchRolledBackOutcome = "rolled_back"
+)
+
+var errUnsupportedBatchFormat = errors.New("unsupported batch format") //nolint:gochecknoglobals // constant error
+var errEmptyBatch = errors.New("empty batch")                          //nolint:gochecknoglobals // constant error
+var errMissingCSVColumn = errors.New("missing csv column")             //nolint:gochecknoglobals // constant error
+var errBatchRolledBack = errors.New("transactions batch rolled back")  //nolint:gochecknoglobals // constant error
+
+type ImportTransactionsRequest struct {
+	Format BatchFormat `form:"-"`
+	Body   io.Reader   `form:"-"`
+	Atomic bool        `form:"atomic"`
+}
+
+type batchEntry struct {
+	line int
+	req  *CreateTransactionRequest
+	err  *errorlib.Error
+}
+
+// ImportTransactions creates the transactions of each line of the batch, applying the same rules
+// of CreateTransaction. The lines are created in chunks, each one in its own database transaction,
+// and a failed line doesn't affect the others, unless the batch is atomic.
+func (svc *transactionsService) ImportTransactions(
+	ctx context.Context,
+	req *ImportTransactionsRequest,
+) (*BatchResult, error) {
+	var (
+		entries []*batchEntry
+		err     error
+	)
+
+	switch req.Format {
+	case BatchFormatNDJSON:
+		entries, err = parseNDJSONBatch(req.Body)
+	case BatchFormatCSV:
+		entries, err = parseCSVBatch(req.Body)
+	default:
+		err = ErrInvalidBatch(fmt.Errorf("%w: %s", errUnsupportedBatchFormat, req.Format))
+	}
+
+	if err != nil {
+		return nil, err
+	} else if len(entries) == 0 {
+		return nil, ErrInvalidBatch(errEmptyBatch)
+	}
+
+	result := &BatchResult{Lines: make([]*BatchLine, 0, len(entries))}
+
+	if req.Atomic {
+		err := svc.repo.RunInTx(ctx, func(ctx context.Context) error {
+			svc.importBatchChunk(ctx, entries, result)
+
+			if result.Failed > 0 {
+				return errBatchRolledBack
+			}
+
+			return nil
+		})
+
+		if errors.Is(err, errBatchRolledBack) {
+			rollBackBatchResult(result)
+		} else if err != nil {
+			return nil, err
+		}
+
+		svc.recordBatchMetrics(ctx, entries, result)
+
+		return result, nil
+	}
+
+	for start := 0; start < len(entries); start += batchChunkSize {
+		chunk := entries[start:min(start+batchChunkSize, len(entries))]
+		chunkStart := len(result.Lines)
+
+		err := svc.repo.RunInTx(ctx, func(ctx context.Context) error {
+			svc.importBatchChunk(ctx, chunk, result)
+			return nil
+		})
+
+		if err != nil {
+			failBatchChunk(result, chunk, chunkStart, err)
+		}
+	}
+
+	svc.recordBatchMetrics(ctx, entries, result)
+
+	return result, nil
+}
+
+func (svc *transactionsService) importBatchChunk(ctx context.Context, entries []*batchEntry, result *BatchResult) {
+	for _, entry := range entries {
+		line := &BatchLine{Line: entry.line, Err: entry.err}
+
+		if line.Err == nil {
+			err := svc.repo.RunInSavepoint(ctx, func(ctx context.Context) error {
+				transaction, err := svc.createTransaction(ctx, entry.req, false)
+				line.Transaction = transaction
+
+				return err
+			})
+
+			if err != nil {
+				line.Transaction = nil
+				line.Err = newBatchLineError(err)
+
+				if errors.Is(line.Err, ErrBatchLineFailed(nil)) {
+					zerolog.Ctx(ctx).Err(err).Int("line", entry.line).Msg("Failed to create the batch transaction")
+				}
+			}
+		}
+
+		if line.Err != nil {
+			result.Failed++
+		} else {
+			result.Created++
+		}
+
+		result.Lines = append(result.Lines, line)
+	}
+}
+
+// failBatchChunk marks the lines of a chunk that wasn't committed as failed, as none of
+// its transactions were created.
+func failBatchChunk(result *BatchResult, chunk []*batchEntry, chunkStart int, err error) {
+	for idx, entry := range chunk {
+		if chunkStart+idx < len(result.Lines) {
+			if line := result.Lines[chunkStart+idx]; line.Err == nil {
+				line.Transaction = nil
+				line.Err = ErrBatchLineFailed(err)
+				result.Created--
+				result.Failed++
+			}
+
+			continue
+		}
+
+		line := &BatchLine{Line: entry.line, Err: entry.err}
+		if line.Err == nil {
+			line.Err = ErrBatchLineFailed(err)
+		}
+
+		result.Lines = append(result.Lines, line)
+		result.Failed++
+	}
+}
+
+func (svc *transactionsService) recordBatchMetrics(ctx context.Context, entries []*batchEntry, result *BatchResult) {
+	for idx, entry := range entries {
+		var opType operationtypes.Type
+		if entry.req != nil {
+			opType = entry.req.OperationTypeID
+		}
+
+		outcome := metrics.OutcomeSuccess
+		if line := result.Lines[idx]; line.Err != nil {
+			outcome = line.Err.Code
+		} else if result.RolledBack {
+			outcome = batchRolledBackOutcome
+		}
+
+		svc.recordTransactionMetric(ctx, opType, outcome)
+	}
+}
+
+func rollBackBatchResult(result *BatchResult) {
+	result.RolledBack = true
+	result.Created = 0
+
+	for _, line := range result.Lines {
+		line.Transaction = nil
+	}
+}
+
+func newBatchLineError(err error) *errorlib.Error {
+	var libErr *errorlib.Error
+	if errors.As(err, &libErr) {
+		return libErr
+	}
+
+	return ErrBatchLineFailed(err)
+}
+
+func parseNDJSONBatch(body io.Reader) ([]*batchEntry, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxBatchLineSize)
+
+	entries := []*batchEntry{}
+
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		} else if len(entries) == maxBatchLines {
+			return nil, ErrBatchTooLarge(nil)
+		}
+
+		entry := &batchEntry{line: line, req: &CreateTransactionRequest{}}
+		if err := validation.DecodeJSON(data, entry.req); err != nil {
+			entry.err = errorlib.ErrInvalidPayload(err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, ErrInvalidBatch(err)
+	}
+
+	return entries, nil
+}
+
+func parseCSVBatch(body io.Reader) ([]*batchEntry, error) {
+	csvReader := csv.NewReader(body)
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, ErrInvalidBatch(err)
+	}
+
+	columns := map[string]int{}
+	for idx, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = idx
+	}
+
+	for _, name := range []string{"account_id", "operation_type_id", "amount"} {
+		if _, ok := columns[name]; !ok {
+			return nil, ErrInvalidBatch(fmt.Errorf("%w: %s", errMissingCSVColumn, name))
+		}
+	}
+
+	entries := []*batchEntry{}
+
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, ErrInvalidBatch(err)
+		} else if len(entries) == maxBatchLines {
+			return nil, ErrBatchTooLarge(nil)
+		}
+
+		line, _ := csvReader.FieldPos(0)
+		entry := &batchEntry{line: line}
+
+		if err != nil {
+			entry.err = errorlib.ErrInvalidPayload(err)
+		} else if entry.req, err = parseCSVRecord(record, columns); err != nil {
+			entry.err = errorlib.ErrInvalidPayload(err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func parseCSVRecord(record []string, columns map[string]int) (*CreateTransactionRequest, error) {
+	accountID, err := strconv.ParseInt(strings.TrimSpace(record[columns["account_id"]]), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	opTypeID, err := strconv.Atoi(strings.TrimSpace(record[columns["operation_type_id"]]))
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := money.NewFromString(strings.TrimSpace(record[columns["amount"]]))
+	if err != nil {
+		return nil, err
+	}
+
+	req := &CreateTransactionRequest{
+		AccountID:       accountID,
+		OperationTypeID: operationtypes.Type(opTypeID),
+		Amount:          amount,
+	}
+
+	if idx, ok := columns["currency"]; ok {
+		req.Currency = strings.TrimSpace(record[idx])
+	}
+
+	if idx, ok := columns["installments"]; ok && strings.TrimSpace(record[idx]) != "" {
+		if req.Installments, err = strconv.Atoi(strings.TrimSpace(record[idx])); err != nil {
+			return nil, err
+		}
+	}
+
+	return req, nil
+}
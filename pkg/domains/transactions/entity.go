@@ -4,12 +4,99 @@ import (
 	"time"
 
 	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/shopspring/decimal"
 )
 
 type Transaction struct {
 	ID              int64
 	AccountID       int64
 	OperationTypeID operationtypes.Type
-	Amount          float64
+	Amount          money.Money
+	Balance         money.Money
+	Currency        string
 	EventDate       time.Time
+
+	FXConversion          *FXConversion
+	ReversesTransactionID *int64
+}
+
+// FXConversion keeps the original values of a foreign currency transaction,
+// the transaction amount is converted to the account currency.
+type FXConversion struct {
+	Currency string
+	Amount   money.Money
+	Rate     decimal.Decimal
+}
+
+type TransactionsCursor struct {
+	EventDate time.Time `json:"d"`
+	ID        int64     `json:"i"`
+}
+
+type TransactionsFilter struct {
+	AccountID       int64
+	OperationTypeID operationtypes.Type
+	From            time.Time
+	To              time.Time
+	After           *TransactionsCursor
+	Limit           int
+}
+
+type TransactionsPage struct {
+	Items      []*Transaction
+	NextCursor string
+}
+
+// ExportedTransaction has the operation type description, used by the exported files.
+type ExportedTransaction struct {
+	Transaction              *Transaction
+	OperationTypeDescription string
+}
+
+// BatchResult has the result of each line of a transactions batch, when the batch is atomic
+// and a line fails, none of the transactions are created.
+type BatchResult struct {
+	Lines      []*BatchLine
+	Created    int
+	Failed     int
+	RolledBack bool
+}
+
+type BatchLine struct {
+	Line        int
+	Transaction *Transaction
+	Err         *errorlib.Error
+}
+
+type InstallmentStatus string
+
+const (
+	InstallmentPaid          InstallmentStatus = "paid"
+	InstallmentPartiallyPaid InstallmentStatus = "partially_paid"
+	InstallmentUnpaid        InstallmentStatus = "unpaid"
+	InstallmentReversed      InstallmentStatus = "reversed"
+)
+
+type InstallmentPlan struct {
+	ID             int64
+	TransactionID  int64
+	AccountID      int64
+	TotalAmount    money.Money
+	PaidAmount     money.Money
+	ReversedAmount money.Money
+	CreatedAt      time.Time
+	Schedule       []*Installment
+}
+
+type Installment struct {
+	ID             int64
+	PlanID         int64
+	Number         int
+	Amount         money.Money
+	PaidAmount     money.Money
+	ReversedAmount money.Money
+	Status         InstallmentStatus
+	DueDate        time.Time
 }
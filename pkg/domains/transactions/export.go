@@ -0,0 +1,251 @@
+package transactions
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV ExportFormat = "csv"
+	ExportFormatOFX ExportFormat = "ofx"
+)
+
+const ofxDateLayout = "20060102150405.000[0:GMT]"
+
+type ExportTransactionsRequest struct {
+	AccountID int64        `form:"-"      validate:"required"`
+	Format    ExportFormat `form:"format" validate:"required,oneof=csv ofx"`
+	From      time.Time    `form:"from"`
+	To        time.Time    `form:"to"`
+}
+
+type transactionsExporter interface {
+	WriteHeader() error
+	WriteTransaction(*ExportedTransaction) error
+	Close() error
+}
+
+// ExportAccountTransactions writes the account transactions in the requested format, the rows
+// are streamed from the database, so the output is written while the transactions are read.
+func (svc *transactionsService) ExportAccountTransactions(
+	ctx context.Context,
+	req *ExportTransactionsRequest,
+	output io.Writer,
+) error {
+	if err := svc.validate.Struct(req); err != nil {
+		return errorlib.ErrInvalidPayload(err)
+	} else if !req.From.IsZero() && !req.To.IsZero() && req.To.Before(req.From) {
+		return errorlib.ErrInvalidPayload(nil)
+	}
+
+	account, err := svc.accountsSvc.GetAccountByID(ctx, req.AccountID)
+	if err != nil {
+		return err
+	}
+
+	var exporter transactionsExporter
+	if req.Format == ExportFormatOFX {
+		exporter = newOFXExporter(output, account, req.From, req.To)
+	} else {
+		exporter = newCSVExporter(output)
+	}
+
+	if err := exporter.WriteHeader(); err != nil {
+		return err
+	}
+
+	filter := &TransactionsFilter{
+		AccountID: req.AccountID,
+		From:      req.From,
+		To:        req.To,
+	}
+
+	if err := svc.repo.StreamAccountTransactions(ctx, filter, exporter.WriteTransaction); err != nil {
+		return err
+	}
+
+	return exporter.Close()
+}
+
+type csvExporter struct {
+	writer *csv.Writer
+}
+
+func newCSVExporter(output io.Writer) *csvExporter {
+	return &csvExporter{writer: csv.NewWriter(output)}
+}
+
+func (exporter *csvExporter) WriteHeader() error {
+	return exporter.writer.Write([]string{
+		"transaction_id",
+		"event_date",
+		"operation_type_id",
+		"operation_type",
+		"amount",
+		"balance",
+		"currency",
+		"original_amount",
+		"original_currency",
+		"fx_rate",
+		"reverses_transaction_id",
+	})
+}
+
+func (exporter *csvExporter) WriteTransaction(exported *ExportedTransaction) error {
+	transaction := exported.Transaction
+
+	record := []string{
+		strconv.FormatInt(transaction.ID, 10),
+		transaction.EventDate.UTC().Format(time.RFC3339),
+		strconv.Itoa(int(transaction.OperationTypeID)),
+		exported.OperationTypeDescription,
+		transaction.Amount.String(),
+		transaction.Balance.String(),
+		transaction.Currency,
+		"",
+		"",
+		"",
+		"",
+	}
+
+	if conversion := transaction.FXConversion; conversion != nil {
+		record[7] = conversion.Amount.String()
+		record[8] = conversion.Currency
+		record[9] = conversion.Rate.String()
+	}
+
+	if transaction.ReversesTransactionID != nil {
+		record[10] = strconv.FormatInt(*transaction.ReversesTransactionID, 10)
+	}
+
+	return exporter.writer.Write(record)
+}
+
+func (exporter *csvExporter) Close() error {
+	exporter.writer.Flush()
+
+	return exporter.writer.Error()
+}
+
+// ofxExporter writes an OFX 2 credit card statement, the ledger balance is the sum of the
+// exported transactions amounts.
+type ofxExporter struct {
+	writer  *bufio.Writer
+	account *accounts.Account
+	start   time.Time
+	end     time.Time
+	total   money.Money
+}
+
+func newOFXExporter(output io.Writer, account *accounts.Account, from time.Time, to time.Time) *ofxExporter {
+	exporter := &ofxExporter{
+		writer:  bufio.NewWriter(output),
+		account: account,
+		start:   from,
+		end:     to,
+	}
+
+	if exporter.start.IsZero() {
+		exporter.start = account.CreatedAt
+	}
+
+	if exporter.end.IsZero() {
+		exporter.end = time.Now()
+	}
+
+	return exporter
+}
+
+func (exporter *ofxExporter) WriteHeader() error {
+	_, err := fmt.Fprintf(
+		exporter.writer,
+		`<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>`+
+			`<DTSERVER>%s</DTSERVER><LANGUAGE>POR</LANGUAGE></SONRS></SIGNONMSGSRSV1>
+<CREDITCARDMSGSRSV1><CCSTMTTRNRS><TRNUID>0</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<CCSTMTRS><CURDEF>%s</CURDEF><CCACCTFROM><ACCTID>%d</ACCTID></CCACCTFROM>
+<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>
+`,
+		formatOFXDate(time.Now()),
+		exporter.account.Currency,
+		exporter.account.ID,
+		formatOFXDate(exporter.start),
+		formatOFXDate(exporter.end),
+	)
+
+	return err
+}
+
+func (exporter *ofxExporter) WriteTransaction(exported *ExportedTransaction) error {
+	transaction := exported.Transaction
+	exporter.total = exporter.total.Add(transaction.Amount)
+
+	trnType := "CREDIT"
+	if transaction.Amount.IsNegative() {
+		trnType = "DEBIT"
+	}
+
+	name, err := escapeXML(exported.OperationTypeDescription)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(
+		exporter.writer,
+		"<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT>"+
+			"<FITID>%d</FITID><NAME>%s</NAME></STMTTRN>\n",
+		trnType,
+		formatOFXDate(transaction.EventDate),
+		transaction.Amount.String(),
+		transaction.ID,
+		name,
+	)
+
+	return err
+}
+
+func (exporter *ofxExporter) Close() error {
+	_, err := fmt.Fprintf(
+		exporter.writer,
+		`</BANKTRANLIST>
+<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>
+</CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1>
+</OFX>
+`,
+		exporter.total.String(),
+		formatOFXDate(exporter.end),
+	)
+	if err != nil {
+		return err
+	}
+
+	return exporter.writer.Flush()
+}
+
+func formatOFXDate(date time.Time) string {
+	return date.UTC().Format(ofxDateLayout)
+}
+
+func escapeXML(value string) (string, error) {
+	var escaped strings.Builder
+	if err := xml.EscapeText(&escaped, []byte(value)); err != nil {
+		return "", err
+	}
+
+	return escaped.String(), nil
+}
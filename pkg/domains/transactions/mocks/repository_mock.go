@@ -13,6 +13,7 @@ import (
 	reflect "reflect"
 
 	transactions "github.com/rudineirk/pismo-challenge/pkg/domains/transactions"
+	money "github.com/rudineirk/pismo-challenge/pkg/utils/money"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -39,6 +40,20 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CreateInstallmentPlan mocks base method.
+func (m *MockRepository) CreateInstallmentPlan(arg0 context.Context, arg1 *transactions.InstallmentPlan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInstallmentPlan", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateInstallmentPlan indicates an expected call of CreateInstallmentPlan.
+func (mr *MockRepositoryMockRecorder) CreateInstallmentPlan(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInstallmentPlan", reflect.TypeOf((*MockRepository)(nil).CreateInstallmentPlan), arg0, arg1)
+}
+
 // CreateTransaction mocks base method.
 func (m *MockRepository) CreateTransaction(arg0 context.Context, arg1 *transactions.Transaction) error {
 	m.ctrl.T.Helper()
@@ -52,3 +67,148 @@ func (mr *MockRepositoryMockRecorder) CreateTransaction(arg0, arg1 any) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransaction", reflect.TypeOf((*MockRepository)(nil).CreateTransaction), arg0, arg1)
 }
+
+// GetInstallmentPlanByTransactionID mocks base method.
+func (m *MockRepository) GetInstallmentPlanByTransactionID(arg0 context.Context, arg1 int64) (*transactions.InstallmentPlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInstallmentPlanByTransactionID", arg0, arg1)
+	ret0, _ := ret[0].(*transactions.InstallmentPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInstallmentPlanByTransactionID indicates an expected call of GetInstallmentPlanByTransactionID.
+func (mr *MockRepositoryMockRecorder) GetInstallmentPlanByTransactionID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstallmentPlanByTransactionID", reflect.TypeOf((*MockRepository)(nil).GetInstallmentPlanByTransactionID), arg0, arg1)
+}
+
+// GetReversedAmount mocks base method.
+func (m *MockRepository) GetReversedAmount(ctx context.Context, transactionID int64) (money.Money, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReversedAmount", ctx, transactionID)
+	ret0, _ := ret[0].(money.Money)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReversedAmount indicates an expected call of GetReversedAmount.
+func (mr *MockRepositoryMockRecorder) GetReversedAmount(ctx, transactionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReversedAmount", reflect.TypeOf((*MockRepository)(nil).GetReversedAmount), ctx, transactionID)
+}
+
+// GetTransactionByID mocks base method.
+func (m *MockRepository) GetTransactionByID(arg0 context.Context, arg1 int64) (*transactions.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactionByID", arg0, arg1)
+	ret0, _ := ret[0].(*transactions.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactionByID indicates an expected call of GetTransactionByID.
+func (mr *MockRepositoryMockRecorder) GetTransactionByID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionByID", reflect.TypeOf((*MockRepository)(nil).GetTransactionByID), arg0, arg1)
+}
+
+// ListAccountTransactions mocks base method.
+func (m *MockRepository) ListAccountTransactions(arg0 context.Context, arg1 *transactions.TransactionsFilter) ([]*transactions.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountTransactions", arg0, arg1)
+	ret0, _ := ret[0].([]*transactions.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountTransactions indicates an expected call of ListAccountTransactions.
+func (mr *MockRepositoryMockRecorder) ListAccountTransactions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountTransactions", reflect.TypeOf((*MockRepository)(nil).ListAccountTransactions), arg0, arg1)
+}
+
+// ListOpenTransactions mocks base method.
+func (m *MockRepository) ListOpenTransactions(ctx context.Context, accountID int64, positive bool) ([]*transactions.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOpenTransactions", ctx, accountID, positive)
+	ret0, _ := ret[0].([]*transactions.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOpenTransactions indicates an expected call of ListOpenTransactions.
+func (mr *MockRepositoryMockRecorder) ListOpenTransactions(ctx, accountID, positive any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOpenTransactions", reflect.TypeOf((*MockRepository)(nil).ListOpenTransactions), ctx, accountID, positive)
+}
+
+// LockAccount mocks base method.
+func (m *MockRepository) LockAccount(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockAccount indicates an expected call of LockAccount.
+func (mr *MockRepositoryMockRecorder) LockAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockAccount", reflect.TypeOf((*MockRepository)(nil).LockAccount), arg0, arg1)
+}
+
+// RunInSavepoint mocks base method.
+func (m *MockRepository) RunInSavepoint(arg0 context.Context, arg1 func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunInSavepoint", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunInSavepoint indicates an expected call of RunInSavepoint.
+func (mr *MockRepositoryMockRecorder) RunInSavepoint(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunInSavepoint", reflect.TypeOf((*MockRepository)(nil).RunInSavepoint), arg0, arg1)
+}
+
+// RunInTx mocks base method.
+func (m *MockRepository) RunInTx(arg0 context.Context, arg1 func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunInTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunInTx indicates an expected call of RunInTx.
+func (mr *MockRepositoryMockRecorder) RunInTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunInTx", reflect.TypeOf((*MockRepository)(nil).RunInTx), arg0, arg1)
+}
+
+// StreamAccountTransactions mocks base method.
+func (m *MockRepository) StreamAccountTransactions(arg0 context.Context, arg1 *transactions.TransactionsFilter, arg2 func(*transactions.ExportedTransaction) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamAccountTransactions", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamAccountTransactions indicates an expected call of StreamAccountTransactions.
+func (mr *MockRepositoryMockRecorder) StreamAccountTransactions(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamAccountTransactions", reflect.TypeOf((*MockRepository)(nil).StreamAccountTransactions), arg0, arg1, arg2)
+}
+
+// UpdateTransactionBalance mocks base method.
+func (m *MockRepository) UpdateTransactionBalance(arg0 context.Context, arg1 *transactions.Transaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTransactionBalance", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTransactionBalance indicates an expected call of UpdateTransactionBalance.
+func (mr *MockRepositoryMockRecorder) UpdateTransactionBalance(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTransactionBalance", reflect.TypeOf((*MockRepository)(nil).UpdateTransactionBalance), arg0, arg1)
+}
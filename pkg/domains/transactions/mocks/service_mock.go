@@ -0,0 +1,145 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/domains/transactions/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source ./pkg/domains/transactions/service.go -destination ./pkg/domains/transactions/mocks/service_mock.go
+//
+// Package mock_transactions is a generated GoMock package.
+package mock_transactions
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	transactions "github.com/rudineirk/pismo-challenge/pkg/domains/transactions"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateSystemTransaction mocks base method.
+func (m *MockService) CreateSystemTransaction(arg0 context.Context, arg1 *transactions.CreateTransactionRequest) (*transactions.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSystemTransaction", arg0, arg1)
+	ret0, _ := ret[0].(*transactions.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSystemTransaction indicates an expected call of CreateSystemTransaction.
+func (mr *MockServiceMockRecorder) CreateSystemTransaction(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSystemTransaction", reflect.TypeOf((*MockService)(nil).CreateSystemTransaction), arg0, arg1)
+}
+
+// CreateTransaction mocks base method.
+func (m *MockService) CreateTransaction(arg0 context.Context, arg1 *transactions.CreateTransactionRequest) (*transactions.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransaction", arg0, arg1)
+	ret0, _ := ret[0].(*transactions.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransaction indicates an expected call of CreateTransaction.
+func (mr *MockServiceMockRecorder) CreateTransaction(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransaction", reflect.TypeOf((*MockService)(nil).CreateTransaction), arg0, arg1)
+}
+
+// ExportAccountTransactions mocks base method.
+func (m *MockService) ExportAccountTransactions(arg0 context.Context, arg1 *transactions.ExportTransactionsRequest, arg2 io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportAccountTransactions", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportAccountTransactions indicates an expected call of ExportAccountTransactions.
+func (mr *MockServiceMockRecorder) ExportAccountTransactions(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportAccountTransactions", reflect.TypeOf((*MockService)(nil).ExportAccountTransactions), arg0, arg1, arg2)
+}
+
+// GetInstallmentPlan mocks base method.
+func (m *MockService) GetInstallmentPlan(ctx context.Context, transactionID int64) (*transactions.InstallmentPlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInstallmentPlan", ctx, transactionID)
+	ret0, _ := ret[0].(*transactions.InstallmentPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInstallmentPlan indicates an expected call of GetInstallmentPlan.
+func (mr *MockServiceMockRecorder) GetInstallmentPlan(ctx, transactionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstallmentPlan", reflect.TypeOf((*MockService)(nil).GetInstallmentPlan), ctx, transactionID)
+}
+
+// ImportTransactions mocks base method.
+func (m *MockService) ImportTransactions(arg0 context.Context, arg1 *transactions.ImportTransactionsRequest) (*transactions.BatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportTransactions", arg0, arg1)
+	ret0, _ := ret[0].(*transactions.BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportTransactions indicates an expected call of ImportTransactions.
+func (mr *MockServiceMockRecorder) ImportTransactions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportTransactions", reflect.TypeOf((*MockService)(nil).ImportTransactions), arg0, arg1)
+}
+
+// ListAccountTransactions mocks base method.
+func (m *MockService) ListAccountTransactions(arg0 context.Context, arg1 *transactions.ListTransactionsRequest) (*transactions.TransactionsPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountTransactions", arg0, arg1)
+	ret0, _ := ret[0].(*transactions.TransactionsPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountTransactions indicates an expected call of ListAccountTransactions.
+func (mr *MockServiceMockRecorder) ListAccountTransactions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountTransactions", reflect.TypeOf((*MockService)(nil).ListAccountTransactions), arg0, arg1)
+}
+
+// ReverseTransaction mocks base method.
+func (m *MockService) ReverseTransaction(arg0 context.Context, arg1 *transactions.ReverseTransactionRequest) (*transactions.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseTransaction", arg0, arg1)
+	ret0, _ := ret[0].(*transactions.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseTransaction indicates an expected call of ReverseTransaction.
+func (mr *MockServiceMockRecorder) ReverseTransaction(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseTransaction", reflect.TypeOf((*MockService)(nil).ReverseTransaction), arg0, arg1)
+}
@@ -2,14 +2,33 @@ package transactions
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/database"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/shopspring/decimal"
 	"github.com/uptrace/bun"
 )
 
+const exportCursorFetchSize = 500
+
 type Repository interface {
+	RunInTx(context.Context, func(context.Context) error) error
+	RunInSavepoint(context.Context, func(context.Context) error) error
+	LockAccount(context.Context, int64) error
 	CreateTransaction(context.Context, *Transaction) error
+	GetTransactionByID(context.Context, int64) (*Transaction, error)
+	GetReversedAmount(ctx context.Context, transactionID int64) (money.Money, error)
+	ListAccountTransactions(context.Context, *TransactionsFilter) ([]*Transaction, error)
+	StreamAccountTransactions(context.Context, *TransactionsFilter, func(*ExportedTransaction) error) error
+	ListOpenTransactions(ctx context.Context, accountID int64, positive bool) ([]*Transaction, error)
+	UpdateTransactionBalance(context.Context, *Transaction) error
+	CreateInstallmentPlan(context.Context, *InstallmentPlan) error
+	GetInstallmentPlanByTransactionID(context.Context, int64) (*InstallmentPlan, error)
 }
 
 type TransactionModel struct {
@@ -17,20 +36,117 @@ type TransactionModel struct {
 	ID              int64               `bun:"id,pk,autoincrement"`
 	AccountID       int64               `bun:"account_id"`
 	OperationTypeID operationtypes.Type `bun:"operation_type_id"`
-	Amount          float64             `bun:"amount"`
+	Amount          money.Money         `bun:"amount"`
+	Balance         money.Money         `bun:"balance"`
+	Currency        string              `bun:"currency"`
 	EventDate       time.Time           `bun:"event_date"`
+
+	OriginalCurrency      *string          `bun:"original_currency"`
+	OriginalAmount        *money.Money     `bun:"original_amount"`
+	FXRate                *decimal.Decimal `bun:"fx_rate"`
+	ReversesTransactionID *int64           `bun:"reverses_transaction_id"`
 }
 
 func NewModelFromEntity(transaction *Transaction) *TransactionModel {
-	return &TransactionModel{
+	model := &TransactionModel{
 		ID:              transaction.ID,
 		AccountID:       transaction.AccountID,
 		OperationTypeID: transaction.OperationTypeID,
 		Amount:          transaction.Amount,
+		Balance:         transaction.Balance,
+		Currency:        transaction.Currency,
 		EventDate:       transaction.EventDate,
+
+		ReversesTransactionID: transaction.ReversesTransactionID,
+	}
+
+	if conversion := transaction.FXConversion; conversion != nil {
+		model.OriginalCurrency = &conversion.Currency
+		model.OriginalAmount = &conversion.Amount
+		model.FXRate = &conversion.Rate
+	}
+
+	return model
+}
+
+func (model *TransactionModel) ToEntity() *Transaction {
+	transaction := &Transaction{
+		ID:              model.ID,
+		AccountID:       model.AccountID,
+		OperationTypeID: model.OperationTypeID,
+		Amount:          model.Amount,
+		Balance:         model.Balance,
+		Currency:        model.Currency,
+		EventDate:       model.EventDate,
+
+		ReversesTransactionID: model.ReversesTransactionID,
+	}
+
+	if model.OriginalCurrency != nil && model.OriginalAmount != nil && model.FXRate != nil {
+		transaction.FXConversion = &FXConversion{
+			Currency: *model.OriginalCurrency,
+			Amount:   *model.OriginalAmount,
+			Rate:     *model.FXRate,
+		}
+	}
+
+	return transaction
+}
+
+type ExportedTransactionModel struct {
+	TransactionModel         `bun:",extend"`
+	OperationTypeDescription string `bun:"operation_type_description"`
+}
+
+func (model *ExportedTransactionModel) ToEntity() *ExportedTransaction {
+	return &ExportedTransaction{
+		Transaction:              model.TransactionModel.ToEntity(),
+		OperationTypeDescription: model.OperationTypeDescription,
 	}
 }
 
+type InstallmentPlanModel struct {
+	bun.BaseModel `bun:"table:installment_plans"`
+	ID            int64               `bun:"id,pk,autoincrement"`
+	TransactionID int64               `bun:"transaction_id"`
+	AccountID     int64               `bun:"account_id"`
+	TotalAmount   money.Money         `bun:"total_amount"`
+	CreatedAt     time.Time           `bun:"created_at"`
+	Schedule      []*InstallmentModel `bun:"rel:has-many,join:id=plan_id"`
+}
+
+type InstallmentModel struct {
+	bun.BaseModel `bun:"table:installments"`
+	ID            int64       `bun:"id,pk,autoincrement"`
+	PlanID        int64       `bun:"plan_id"`
+	Number        int         `bun:"number"`
+	Amount        money.Money `bun:"amount"`
+	DueDate       time.Time   `bun:"due_date,type:date"`
+}
+
+func (model *InstallmentPlanModel) ToEntity() *InstallmentPlan {
+	plan := &InstallmentPlan{
+		ID:            model.ID,
+		TransactionID: model.TransactionID,
+		AccountID:     model.AccountID,
+		TotalAmount:   model.TotalAmount,
+		CreatedAt:     model.CreatedAt,
+		Schedule:      make([]*Installment, 0, len(model.Schedule)),
+	}
+
+	for _, installment := range model.Schedule {
+		plan.Schedule = append(plan.Schedule, &Installment{
+			ID:      installment.ID,
+			PlanID:  installment.PlanID,
+			Number:  installment.Number,
+			Amount:  installment.Amount,
+			DueDate: installment.DueDate,
+		})
+	}
+
+	return plan
+}
+
 type dbRepository struct {
 	bunDB *bun.DB
 }
@@ -39,10 +155,35 @@ func NewRepository(bunDB *bun.DB) Repository {
 	return &dbRepository{bunDB}
 }
 
+func (repo *dbRepository) RunInTx(ctx context.Context, fn func(context.Context) error) error {
+	return database.RunInTx(ctx, repo.bunDB, fn)
+}
+
+func (repo *dbRepository) RunInSavepoint(ctx context.Context, fn func(context.Context) error) error {
+	return database.RunInSavepoint(ctx, repo.bunDB, fn)
+}
+
+func (repo *dbRepository) LockAccount(ctx context.Context, accountID int64) error {
+	var id int64
+
+	err := database.Conn(ctx, repo.bunDB).NewSelect().
+		TableExpr("accounts").
+		Column("id").
+		Where("id = ?", accountID).
+		For("UPDATE").
+		Scan(ctx, &id)
+
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return errorlib.ErrNotFound(err)
+	}
+
+	return err
+}
+
 func (repo *dbRepository) CreateTransaction(ctx context.Context, transaction *Transaction) error {
 	transactionModel := NewModelFromEntity(transaction)
 
-	_, err := repo.bunDB.NewInsert().
+	_, err := database.Conn(ctx, repo.bunDB).NewInsert().
 		Model(transactionModel).
 		Exec(ctx)
 
@@ -54,3 +195,238 @@ func (repo *dbRepository) CreateTransaction(ctx context.Context, transaction *Tr
 
 	return nil
 }
+
+func (repo *dbRepository) GetTransactionByID(ctx context.Context, id int64) (*Transaction, error) {
+	transactionModel := &TransactionModel{}
+
+	err := database.Conn(ctx, repo.bunDB).NewSelect().
+		Model(transactionModel).
+		Where("id = ?", id).
+		Scan(ctx)
+
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return nil, errorlib.ErrNotFound(err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return transactionModel.ToEntity(), nil
+}
+
+func (repo *dbRepository) GetReversedAmount(ctx context.Context, transactionID int64) (money.Money, error) {
+	var reversed money.Money
+
+	err := database.Conn(ctx, repo.bunDB).NewSelect().
+		Model((*TransactionModel)(nil)).
+		ColumnExpr("COALESCE(SUM(ABS(amount)), 0)").
+		Where("reverses_transaction_id = ?", transactionID).
+		Scan(ctx, &reversed)
+
+	return reversed, err
+}
+
+func (repo *dbRepository) ListAccountTransactions(
+	ctx context.Context,
+	filter *TransactionsFilter,
+) ([]*Transaction, error) {
+	transactionModels := []*TransactionModel{}
+
+	query := repo.bunDB.NewSelect().
+		Model(&transactionModels).
+		Where("account_id = ?", filter.AccountID)
+
+	if filter.OperationTypeID != 0 {
+		query = query.Where("operation_type_id = ?", filter.OperationTypeID)
+	}
+
+	if !filter.From.IsZero() {
+		query = query.Where("event_date >= ?", filter.From)
+	}
+
+	if !filter.To.IsZero() {
+		query = query.Where("event_date < ?", filter.To)
+	}
+
+	if filter.After != nil {
+		query = query.Where("(event_date, id) < (?, ?)", filter.After.EventDate, filter.After.ID)
+	}
+
+	err := query.
+		OrderExpr("event_date DESC, id DESC").
+		Limit(filter.Limit).
+		Scan(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*Transaction, 0, len(transactionModels))
+	for _, model := range transactionModels {
+		transactions = append(transactions, model.ToEntity())
+	}
+
+	return transactions, nil
+}
+
+// StreamAccountTransactions reads the transactions with a database cursor, in batches,
+// so the whole account history isn't loaded in memory.
+func (repo *dbRepository) StreamAccountTransactions(
+	ctx context.Context,
+	filter *TransactionsFilter,
+	fn func(*ExportedTransaction) error,
+) error {
+	return database.RunInTx(ctx, repo.bunDB, func(ctx context.Context) error {
+		conn := database.Conn(ctx, repo.bunDB)
+
+		query := conn.NewSelect().
+			TableExpr("transactions AS t").
+			ColumnExpr("t.*").
+			ColumnExpr("ot.description AS operation_type_description").
+			Join("JOIN operation_types AS ot ON ot.id = t.operation_type_id").
+			Where("t.account_id = ?", filter.AccountID)
+
+		if filter.OperationTypeID != 0 {
+			query = query.Where("t.operation_type_id = ?", filter.OperationTypeID)
+		}
+
+		if !filter.From.IsZero() {
+			query = query.Where("t.event_date >= ?", filter.From)
+		}
+
+		if !filter.To.IsZero() {
+			query = query.Where("t.event_date < ?", filter.To)
+		}
+
+		query = query.OrderExpr("t.event_date ASC, t.id ASC")
+
+		if _, err := conn.NewRaw("DECLARE transactions_export NO SCROLL CURSOR FOR ?", query).Exec(ctx); err != nil {
+			return err
+		}
+
+		for {
+			models := []*ExportedTransactionModel{}
+
+			err := conn.NewRaw("FETCH ? FROM transactions_export", exportCursorFetchSize).Scan(ctx, &models)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			} else if len(models) == 0 {
+				break
+			}
+
+			for _, model := range models {
+				if err := fn(model.ToEntity()); err != nil {
+					return err
+				}
+			}
+		}
+
+		_, err := conn.NewRaw("CLOSE transactions_export").Exec(ctx)
+
+		return err
+	})
+}
+
+func (repo *dbRepository) ListOpenTransactions(
+	ctx context.Context,
+	accountID int64,
+	positive bool,
+) ([]*Transaction, error) {
+	transactionModels := []*TransactionModel{}
+
+	query := database.Conn(ctx, repo.bunDB).NewSelect().
+		Model(&transactionModels).
+		Where("account_id = ?", accountID)
+
+	if positive {
+		query = query.Where("balance > 0")
+	} else {
+		query = query.Where("balance < 0")
+	}
+
+	err := query.
+		OrderExpr("event_date ASC, id ASC").
+		For("UPDATE").
+		Scan(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*Transaction, 0, len(transactionModels))
+	for _, model := range transactionModels {
+		transactions = append(transactions, model.ToEntity())
+	}
+
+	return transactions, nil
+}
+
+func (repo *dbRepository) UpdateTransactionBalance(ctx context.Context, transaction *Transaction) error {
+	_, err := database.Conn(ctx, repo.bunDB).NewUpdate().
+		Model((*TransactionModel)(nil)).
+		Set("balance = ?", transaction.Balance).
+		Where("id = ?", transaction.ID).
+		Exec(ctx)
+
+	return err
+}
+
+func (repo *dbRepository) CreateInstallmentPlan(ctx context.Context, plan *InstallmentPlan) error {
+	conn := database.Conn(ctx, repo.bunDB)
+
+	planModel := &InstallmentPlanModel{
+		TransactionID: plan.TransactionID,
+		AccountID:     plan.AccountID,
+		TotalAmount:   plan.TotalAmount,
+		CreatedAt:     plan.CreatedAt,
+	}
+
+	if _, err := conn.NewInsert().Model(planModel).Exec(ctx); err != nil {
+		return err
+	}
+
+	plan.ID = planModel.ID
+
+	installmentModels := make([]*InstallmentModel, 0, len(plan.Schedule))
+	for _, installment := range plan.Schedule {
+		installment.PlanID = plan.ID
+		installmentModels = append(installmentModels, &InstallmentModel{
+			PlanID:  installment.PlanID,
+			Number:  installment.Number,
+			Amount:  installment.Amount,
+			DueDate: installment.DueDate,
+		})
+	}
+
+	if _, err := conn.NewInsert().Model(&installmentModels).Exec(ctx); err != nil {
+		return err
+	}
+
+	for idx, installment := range plan.Schedule {
+		installment.ID = installmentModels[idx].ID
+	}
+
+	return nil
+}
+
+func (repo *dbRepository) GetInstallmentPlanByTransactionID(
+	ctx context.Context,
+	transactionID int64,
+) (*InstallmentPlan, error) {
+	planModel := InstallmentPlanModel{}
+
+	err := repo.bunDB.NewSelect().
+		Model(&planModel).
+		Relation("Schedule", func(query *bun.SelectQuery) *bun.SelectQuery {
+			return query.OrderExpr("number ASC")
+		}).
+		Where("transaction_id = ?", transactionID).
+		Scan(ctx)
+
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return nil, errorlib.ErrNotFound(err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return planModel.ToEntity(), nil
+}
@@ -2,49 +2,157 @@ package transactions
 
 import (
 	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/fxrates"
 	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/metrics"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/outbox"
 	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
-	"github.com/shopspring/decimal"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/pagination"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/validation"
+)
+
+const (
+	TransactionCreatedEvent   = "TransactionCreated"
+	invalidOperationTypeLabel = "invalid"
 )
 
 var ErrAccountIDNotFound = errorlib.NewError( //nolint:gochecknoglobals // error maker
 	"account_id_not_found",
 	"account_id not found",
+	http.StatusBadRequest,
+)
+var ErrAccountBlocked = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"account_blocked",
+	"account is blocked for debits",
+	http.StatusUnprocessableEntity,
+)
+var ErrAccountClosed = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"account_closed",
+	"account is closed",
+	http.StatusUnprocessableEntity,
 )
 var ErrInvalidOperationTypeID = errorlib.NewError( //nolint:gochecknoglobals // error maker
 	"invalid_operation_type_id",
 	"invalid operation_type_id",
+	http.StatusBadRequest,
 )
 var ErrInvalidAmount = errorlib.NewError( //nolint:gochecknoglobals // error maker
 	"invalid_amount",
 	"invalid amount",
+	http.StatusBadRequest,
+)
+var ErrInsufficientCreditLimit = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"insufficient_credit_limit",
+	"insufficient available credit limit",
+	http.StatusUnprocessableEntity,
+)
+var ErrInvalidCursor = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"invalid_cursor",
+	"invalid cursor",
+	http.StatusBadRequest,
+)
+var ErrTransactionAlreadyReversed = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"transaction_already_reversed",
+	"transaction already fully reversed",
+	http.StatusConflict,
+)
+var ErrInvalidInstallments = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"invalid_installments",
+	"invalid installments",
+	http.StatusBadRequest,
+)
+var ErrReversalNotReversible = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"reversal_not_reversible",
+	"a reversal transaction cannot be reversed",
+	http.StatusUnprocessableEntity,
+)
+var ErrInvalidBatch = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"invalid_batch",
+	"invalid transactions batch",
+	http.StatusBadRequest,
+)
+var ErrBatchTooLarge = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"batch_too_large",
+	"transactions batch has too many lines",
+	http.StatusRequestEntityTooLarge,
+)
+var ErrUnsupportedBatchFormat = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"unsupported_batch_format",
+	"the batch Content-Type must be application/x-ndjson or text/csv",
+	http.StatusUnsupportedMediaType,
+)
+var ErrBatchLineFailed = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"batch_line_failed",
+	"failed to create the transaction",
+	http.StatusInternalServerError,
 )
 
 type Service interface {
 	CreateTransaction(context.Context, *CreateTransactionRequest) (*Transaction, error)
+	CreateSystemTransaction(context.Context, *CreateTransactionRequest) (*Transaction, error)
+	ListAccountTransactions(context.Context, *ListTransactionsRequest) (*TransactionsPage, error)
+	ExportAccountTransactions(context.Context, *ExportTransactionsRequest, io.Writer) error
+	ReverseTransaction(context.Context, *ReverseTransactionRequest) (*Transaction, error)
+	GetInstallmentPlan(ctx context.Context, transactionID int64) (*InstallmentPlan, error)
+	ImportTransactions(context.Context, *ImportTransactionsRequest) (*BatchResult, error)
 }
 
 type CreateTransactionRequest struct {
 	AccountID       int64               `json:"account_id"        validate:"required"`
 	OperationTypeID operationtypes.Type `json:"operation_type_id" validate:"required"`
-	Amount          float64             `json:"amount"            validate:"required"`
+	Amount          money.Money         `json:"amount"            validate:"required"`
+	Currency        string              `json:"currency"          validate:"omitempty,iso4217"`
+	Installments    int                 `json:"installments"      validate:"omitempty,min=1,max=48"`
+}
+
+type ReverseTransactionRequest struct {
+	TransactionID int64        `json:"-"      validate:"required"`
+	Amount        *money.Money `json:"amount" validate:"omitempty,gt=0"`
+}
+
+type ListTransactionsRequest struct {
+	AccountID       int64               `form:"-"                 validate:"required"`
+	OperationTypeID operationtypes.Type `form:"operation_type_id"`
+	From            time.Time           `form:"from"`
+	To              time.Time           `form:"to"`
+	Cursor          string              `form:"cursor"`
+	Limit           int                 `form:"limit"             validate:"omitempty,min=1,max=100"`
 }
 
 type transactionsService struct {
 	repo        Repository
 	accountsSvc accounts.Service
+	opTypesSvc  operationtypes.Service
+	fxRatesSvc  fxrates.Service
+	events      outbox.Store
 	validate    *validator.Validate
 }
 
-func NewService(repo Repository, accountsSvc accounts.Service) Service {
+func NewService(
+	repo Repository,
+	accountsSvc accounts.Service,
+	opTypesSvc operationtypes.Service,
+	fxRatesSvc fxrates.Service,
+	events outbox.Store,
+) Service {
+	validate := validation.New()
+
 	return &transactionsService{
 		repo:        repo,
 		accountsSvc: accountsSvc,
-		validate:    validator.New(validator.WithRequiredStructEnabled()),
+		opTypesSvc:  opTypesSvc,
+		fxRatesSvc:  fxRatesSvc,
+		events:      events,
+		validate:    validate,
 	}
 }
 
@@ -52,43 +160,540 @@ func (svc *transactionsService) CreateTransaction(
 	ctx context.Context,
 	req *CreateTransactionRequest,
 ) (*Transaction, error) {
+	transaction, err := svc.createTransaction(ctx, req, false)
+	svc.recordTransactionMetric(ctx, req.OperationTypeID, metrics.Outcome(err))
+
+	return transaction, err
+}
+
+// CreateSystemTransaction posts the charges generated by the system jobs, only the system
+// generated operation types are accepted.
+func (svc *transactionsService) CreateSystemTransaction(
+	ctx context.Context,
+	req *CreateTransactionRequest,
+) (*Transaction, error) {
+	transaction, err := svc.createTransaction(ctx, req, true)
+	svc.recordTransactionMetric(ctx, req.OperationTypeID, metrics.Outcome(err))
+
+	return transaction, err
+}
+
+func (svc *transactionsService) createTransaction(
+	ctx context.Context,
+	req *CreateTransactionRequest,
+	systemGenerated bool,
+) (*Transaction, error) {
+	req.Currency = money.NormalizeCurrency(req.Currency)
+
 	if err := svc.validate.Struct(req); err != nil {
 		return nil, errorlib.ErrInvalidPayload(err)
-	} else if !operationtypes.IsValidOperationType(req.OperationTypeID) {
+	}
+
+	opType, err := svc.getOperationType(ctx, req.OperationTypeID)
+	if err != nil {
+		return nil, err
+	} else if opType.SystemGenerated != systemGenerated {
 		return nil, ErrInvalidOperationTypeID(nil)
-	} else if svc.isValidAmount(req.Amount, req.OperationTypeID) {
+	} else if !svc.isValidAmount(req.Amount, opType) {
 		return nil, ErrInvalidAmount(nil)
-	} else if err := svc.validateAccountID(ctx, req.AccountID); err != nil {
+	}
+
+	account, err := svc.accountsSvc.GetAccountByID(ctx, req.AccountID)
+	if err != nil {
 		return nil, ErrAccountIDNotFound(err)
 	}
 
-	account := &Transaction{
+	transaction := &Transaction{
 		AccountID:       req.AccountID,
 		OperationTypeID: req.OperationTypeID,
 		Amount:          req.Amount,
+		Currency:        account.Currency,
 		EventDate:       time.Now(),
 	}
 
-	if err := svc.repo.CreateTransaction(ctx, account); err != nil {
+	if req.Currency != "" && req.Currency != account.Currency {
+		if err := svc.convertAmount(ctx, transaction, req.Currency); err != nil {
+			return nil, err
+		}
+	}
+
+	transaction.Balance = transaction.Amount
+
+	var plan *InstallmentPlan
+	if req.Installments > 0 {
+		if plan, err = svc.newInstallmentPlan(transaction, req.Installments); err != nil {
+			return nil, err
+		}
+	}
+
+	err = svc.repo.RunInTx(ctx, func(ctx context.Context) error {
+		if err := svc.repo.LockAccount(ctx, transaction.AccountID); err != nil {
+			return err
+		}
+
+		// reload after locking the account, so the status and limit changed concurrently are checked
+		account, err := svc.accountsSvc.GetAccountByID(ctx, transaction.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if !systemGenerated {
+			if err := svc.checkAccountStatus(account, opType.Sign); err != nil {
+				return err
+			}
+		}
+
+		settledLimit, err := svc.settleOpenTransactions(ctx, transaction)
+		if err != nil {
+			return err
+		}
+
+		if err := svc.checkAvailableCreditLimit(account, transaction, opType); err != nil {
+			return err
+		}
+
+		if err := svc.repo.CreateTransaction(ctx, transaction); err != nil {
+			return err
+		}
+
+		if plan != nil {
+			plan.TransactionID = transaction.ID
+			if err := svc.repo.CreateInstallmentPlan(ctx, plan); err != nil {
+				return err
+			}
+		}
+
+		if err := svc.updateAvailableCreditLimit(ctx, transaction, opType, settledLimit); err != nil {
+			return err
+		}
+
+		return svc.addTransactionCreatedEvent(ctx, transaction)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (svc *transactionsService) ListAccountTransactions(
+	ctx context.Context,
+	req *ListTransactionsRequest,
+) (*TransactionsPage, error) {
+	if err := svc.validate.Struct(req); err != nil {
+		return nil, errorlib.ErrInvalidPayload(err)
+	} else if !req.From.IsZero() && !req.To.IsZero() && req.To.Before(req.From) {
+		return nil, errorlib.ErrInvalidPayload(nil)
+	}
+
+	if req.OperationTypeID != 0 {
+		if _, err := svc.getOperationType(ctx, req.OperationTypeID); err != nil {
+			return nil, err
+		}
+	}
+
+	filter := &TransactionsFilter{
+		AccountID:       req.AccountID,
+		OperationTypeID: req.OperationTypeID,
+		From:            req.From,
+		To:              req.To,
+	}
+
+	if req.Cursor != "" {
+		filter.After = &TransactionsCursor{}
+		if err := pagination.DecodeCursor(req.Cursor, filter.After); err != nil {
+			return nil, ErrInvalidCursor(err)
+		}
+	}
+
+	if err := svc.validateAccountID(ctx, req.AccountID); err != nil {
+		return nil, err
+	}
+
+	limit := pagination.NormalizeLimit(req.Limit)
+	filter.Limit = limit + 1
+
+	transactions, err := svc.repo.ListAccountTransactions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &TransactionsPage{Items: transactions}
+	if len(transactions) > limit {
+		page.Items = transactions[:limit]
+		last := page.Items[limit-1]
+
+		page.NextCursor, err = pagination.EncodeCursor(TransactionsCursor{EventDate: last.EventDate, ID: last.ID})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+func (svc *transactionsService) ReverseTransaction(
+	ctx context.Context,
+	req *ReverseTransactionRequest,
+) (*Transaction, error) {
+	if err := svc.validate.Struct(req); err != nil {
+		return nil, errorlib.ErrInvalidPayload(err)
+	} else if req.Amount != nil && !req.Amount.HasCentsPrecision() {
+		return nil, ErrInvalidAmount(nil)
+	}
+
+	transaction, err := svc.repo.GetTransactionByID(ctx, req.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var reversal *Transaction
+
+	err = svc.repo.RunInTx(ctx, func(ctx context.Context) error {
+		if err := svc.repo.LockAccount(ctx, transaction.AccountID); err != nil {
+			return err
+		}
+
+		// reload after locking the account, so the balance and reversals are up to date
+		original, err := svc.repo.GetTransactionByID(ctx, req.TransactionID)
+		if err != nil {
+			return err
+		}
+
+		if original.ReversesTransactionID != nil {
+			return ErrReversalNotReversible(nil)
+		}
+
+		opType, err := svc.opTypesSvc.GetOperationType(ctx, original.OperationTypeID)
+		if err != nil {
+			return err
+		}
+
+		reversed, err := svc.repo.GetReversedAmount(ctx, original.ID)
+		if err != nil {
+			return err
+		}
+
+		reversible := original.Amount.Abs().Sub(reversed)
+		if !reversible.IsPositive() {
+			return ErrTransactionAlreadyReversed(nil)
+		}
+
+		amount := reversible
+		if req.Amount != nil {
+			amount = *req.Amount
+		}
+
+		if amount.GreaterThan(reversible) {
+			return ErrInvalidAmount(nil)
+		}
+
+		if original.Amount.IsPositive() {
+			amount = amount.Neg()
+		}
+
+		account, err := svc.accountsSvc.GetAccountByID(ctx, original.AccountID)
+		if err != nil {
+			return err
+		}
+
+		// the reversal of a payment is a debit, rejected on blocked accounts
+		if err := svc.checkAccountStatus(account, amount.Sign()); err != nil {
+			return err
+		}
+
+		reversal = &Transaction{
+			AccountID:             original.AccountID,
+			OperationTypeID:       original.OperationTypeID,
+			Amount:                amount,
+			Balance:               amount,
+			Currency:              original.Currency,
+			EventDate:             time.Now(),
+			ReversesTransactionID: &original.ID,
+		}
+
+		settledLimit := money.Money{}
+		if original.Balance.Sign() == -reversal.Balance.Sign() {
+			if settledLimit, err = svc.settleTransactions(ctx, reversal, []*Transaction{original}); err != nil {
+				return err
+			}
+		}
+
+		settledOpenLimit, err := svc.settleOpenTransactions(ctx, reversal)
+		if err != nil {
+			return err
+		}
+
+		if err := svc.checkAvailableCreditLimit(account, reversal, opType); err != nil {
+			return err
+		}
+
+		if err := svc.repo.CreateTransaction(ctx, reversal); err != nil {
+			return err
+		}
+
+		settledLimit = settledLimit.Add(settledOpenLimit)
+		if err := svc.updateAvailableCreditLimit(ctx, reversal, opType, settledLimit); err != nil {
+			return err
+		}
+
+		return svc.addTransactionCreatedEvent(ctx, reversal)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reversal, nil
+}
+
+// convertAmount converts the transaction amount from the given currency to the account currency,
+// using the FX rate effective at the transaction event date.
+func (svc *transactionsService) convertAmount(ctx context.Context, transaction *Transaction, currency string) error {
+	rate, err := svc.fxRatesSvc.GetRate(ctx, currency, transaction.Currency, transaction.EventDate)
+	if err != nil {
+		return err
+	}
+
+	transaction.FXConversion = &FXConversion{
+		Currency: currency,
+		Amount:   transaction.Amount,
+		Rate:     rate.Rate,
+	}
+
+	transaction.Amount = transaction.Amount.Convert(rate.Rate)
+	if transaction.Amount.IsZero() {
+		return ErrInvalidAmount(nil)
+	}
+
+	return nil
+}
+
+func (svc *transactionsService) GetInstallmentPlan(ctx context.Context, transactionID int64) (*InstallmentPlan, error) {
+	plan, err := svc.repo.GetInstallmentPlanByTransactionID(ctx, transactionID)
+	if err != nil {
 		return nil, err
 	}
 
-	return account, nil
+	transaction, err := svc.repo.GetTransactionByID(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	reversed, err := svc.repo.GetReversedAmount(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the reversals also settle the purchase, but they cancel the last installments instead of paying them
+	settled := transaction.Amount.Sub(transaction.Balance).Abs()
+	plan.ReversedAmount = money.Min(reversed, plan.TotalAmount.Abs())
+	plan.PaidAmount = money.NewFromInt(0)
+
+	if settled.GreaterThan(plan.ReversedAmount) {
+		plan.PaidAmount = settled.Sub(plan.ReversedAmount)
+	}
+
+	remainingReversed := plan.ReversedAmount
+	for idx := len(plan.Schedule) - 1; idx >= 0; idx-- {
+		installment := plan.Schedule[idx]
+		installment.ReversedAmount = money.Min(remainingReversed, installment.Amount.Abs())
+		remainingReversed = remainingReversed.Sub(installment.ReversedAmount)
+	}
+
+	// installments are paid in order, with the amount already settled from the purchase
+	remaining := plan.PaidAmount
+
+	for _, installment := range plan.Schedule {
+		installment.PaidAmount = money.Min(remaining, installment.Amount.Abs().Sub(installment.ReversedAmount))
+		remaining = remaining.Sub(installment.PaidAmount)
+
+		switch {
+		case installment.ReversedAmount.Equal(installment.Amount.Abs()):
+			installment.Status = InstallmentReversed
+		case installment.PaidAmount.Add(installment.ReversedAmount).Equal(installment.Amount.Abs()):
+			installment.Status = InstallmentPaid
+		case installment.PaidAmount.IsPositive():
+			installment.Status = InstallmentPartiallyPaid
+		default:
+			installment.Status = InstallmentUnpaid
+		}
+	}
+
+	return plan, nil
+}
+
+// newInstallmentPlan splits the transaction amount in monthly installments,
+// the first one is due a month after the purchase.
+func (svc *transactionsService) newInstallmentPlan(transaction *Transaction, installments int) (*InstallmentPlan, error) {
+	if transaction.OperationTypeID != operationtypes.InstallmentType {
+		return nil, ErrInvalidInstallments(nil)
+	}
+
+	plan := &InstallmentPlan{
+		AccountID:   transaction.AccountID,
+		TotalAmount: transaction.Amount,
+		CreatedAt:   transaction.EventDate,
+		Schedule:    make([]*Installment, 0, installments),
+	}
+
+	for idx, amount := range transaction.Amount.Split(installments) {
+		if amount.IsZero() {
+			return nil, ErrInvalidInstallments(nil)
+		}
+
+		plan.Schedule = append(plan.Schedule, &Installment{
+			Number:  idx + 1,
+			Amount:  amount,
+			Status:  InstallmentUnpaid,
+			DueDate: addMonths(transaction.EventDate, idx+1),
+		})
+	}
+
+	return plan, nil
+}
+
+// checkAccountStatus rejects any transaction on closed accounts, while blocked and
+// suspended accounts can still receive credits, like payments.
+func (svc *transactionsService) checkAccountStatus(account *accounts.Account, sign int) error {
+	switch account.Status {
+	case accounts.ClosedStatus:
+		return ErrAccountClosed(nil)
+	case accounts.BlockedStatus, accounts.SuspendedStatus:
+		if sign < 0 {
+			return ErrAccountBlocked(nil)
+		}
+	case accounts.ActiveStatus:
+	}
+
+	return nil
 }
 
-func (svc *transactionsService) isValidAmount(amount float64, opType operationtypes.Type) bool {
-	sign := -1
-	if opType == operationtypes.PaymentType {
-		sign = 1
+// checkAvailableCreditLimit runs after the settlement, so only the amount not covered by the
+// credit left by previous payments consumes the credit limit.
+func (svc *transactionsService) checkAvailableCreditLimit(
+	account *accounts.Account,
+	transaction *Transaction,
+	opType *operationtypes.OperationType,
+) error {
+	if !opType.AffectsCreditLimit || !transaction.Balance.IsNegative() {
+		return nil
 	}
 
-	decimalAmount := decimal.NewFromFloat(amount)
-	hasTwoDecimalsPrecision := decimalAmount.
-		Mul(decimal.NewFromInt(100)).
-		Mod(decimal.NewFromInt(1)).
-		IsZero()
+	if transaction.Balance.Abs().GreaterThan(account.AvailableCreditLimit) {
+		return ErrInsufficientCreditLimit(nil)
+	}
+
+	return nil
+}
+
+// updateAvailableCreditLimit consumes the limit with the open balance of debits, and credits only
+// restore the amount of the debits they settled that affect the credit limit, so overpayments and
+// payments of interest and fees don't raise the limit above the granted one.
+func (svc *transactionsService) updateAvailableCreditLimit(
+	ctx context.Context,
+	transaction *Transaction,
+	opType *operationtypes.OperationType,
+	settledLimit money.Money,
+) error {
+	if !opType.AffectsCreditLimit {
+		return nil
+	}
+
+	amount := settledLimit
+	if transaction.Amount.IsNegative() {
+		amount = transaction.Balance
+	}
+
+	if amount.IsZero() {
+		return nil
+	}
 
-	return !hasTwoDecimalsPrecision || decimalAmount.Sign() != sign
+	return svc.accountsSvc.AddAvailableCreditLimit(ctx, transaction.AccountID, amount)
+}
+
+// settleOpenTransactions discharges the oldest open transactions with the opposite sign,
+// so payments pay down purchases and purchases consume the credit left by payments.
+func (svc *transactionsService) settleOpenTransactions(ctx context.Context, transaction *Transaction) (money.Money, error) {
+	if transaction.Balance.IsZero() {
+		return money.Money{}, nil
+	}
+
+	openTransactions, err := svc.repo.ListOpenTransactions(ctx, transaction.AccountID, transaction.Balance.IsNegative())
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	return svc.settleTransactions(ctx, transaction, openTransactions)
+}
+
+// settleTransactions returns the amount of the settled debits that affect the credit limit.
+func (svc *transactionsService) settleTransactions(
+	ctx context.Context,
+	transaction *Transaction,
+	openTransactions []*Transaction,
+) (money.Money, error) {
+	remaining := transaction.Balance
+	settledLimit := money.Money{}
+
+	for _, openTransaction := range openTransactions {
+		if remaining.IsZero() {
+			break
+		}
+
+		openBalance := openTransaction.Balance
+
+		settled := money.Min(remaining.Abs(), openBalance.Abs())
+		if remaining.IsPositive() {
+			remaining = remaining.Sub(settled)
+			openBalance = openBalance.Add(settled)
+
+			opType, err := svc.opTypesSvc.GetOperationType(ctx, openTransaction.OperationTypeID)
+			if err != nil {
+				return money.Money{}, err
+			} else if opType.AffectsCreditLimit {
+				settledLimit = settledLimit.Add(settled)
+			}
+		} else {
+			remaining = remaining.Add(settled)
+			openBalance = openBalance.Sub(settled)
+		}
+
+		openTransaction.Balance = openBalance
+		if err := svc.repo.UpdateTransactionBalance(ctx, openTransaction); err != nil {
+			return money.Money{}, err
+		}
+	}
+
+	transaction.Balance = remaining
+
+	return settledLimit, nil
+}
+
+func (svc *transactionsService) addTransactionCreatedEvent(ctx context.Context, transaction *Transaction) error {
+	event, err := outbox.NewEvent(TransactionCreatedEvent, transaction.AccountID, NewAPIResponseFromEntity(transaction))
+	if err != nil {
+		return err
+	}
+
+	return svc.events.AddEvent(ctx, event)
+}
+
+func (svc *transactionsService) getOperationType(
+	ctx context.Context,
+	id operationtypes.Type,
+) (*operationtypes.OperationType, error) {
+	opType, err := svc.opTypesSvc.GetOperationType(ctx, id)
+	if err != nil && errors.Is(err, errorlib.ErrNotFound(nil)) {
+		return nil, ErrInvalidOperationTypeID(err)
+	}
+
+	return opType, err
+}
+
+func (svc *transactionsService) isValidAmount(amount money.Money, opType *operationtypes.OperationType) bool {
+	return amount.HasCentsPrecision() && amount.Sign() == opType.Sign
 }
 
 func (svc *transactionsService) validateAccountID(ctx context.Context, id int64) error {
@@ -96,3 +701,27 @@ func (svc *transactionsService) validateAccountID(ctx context.Context, id int64)
 
 	return err
 }
+
+// recordTransactionMetric labels the unknown operation types with a fixed value, so the ids sent
+// by the clients can't create new metric series.
+func (svc *transactionsService) recordTransactionMetric(
+	ctx context.Context,
+	opType operationtypes.Type,
+	outcome string,
+) {
+	label := invalidOperationTypeLabel
+	if _, err := svc.opTypesSvc.GetOperationType(ctx, opType); err == nil {
+		label = strconv.Itoa(int(opType))
+	}
+
+	metrics.Transactions.WithLabelValues(label, outcome).Inc()
+}
+
+// addMonths keeps the day of the month, using the last day on shorter months.
+func addMonths(date time.Time, months int) time.Time {
+	year, month, day := date.UTC().Date()
+	firstDay := time.Date(year, month+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstDay.AddDate(0, 1, -1).Day()
+
+	return time.Date(firstDay.Year(), firstDay.Month(), min(day, lastDay), 0, 0, 0, 0, time.UTC)
+}
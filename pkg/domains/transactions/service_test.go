@@ -2,16 +2,29 @@ package transactions_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
 	accountMocks "github.com/rudineirk/pismo-challenge/pkg/domains/accounts/mocks"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/fxrates"
+	fxRatesMocks "github.com/rudineirk/pismo-challenge/pkg/domains/fxrates/mocks"
 	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	opTypesMocks "github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes/mocks"
 	"github.com/rudineirk/pismo-challenge/pkg/domains/transactions"
 	mocks "github.com/rudineirk/pismo-challenge/pkg/domains/transactions/mocks"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/metrics"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/outbox"
+	outboxMocks "github.com/rudineirk/pismo-challenge/pkg/infra/outbox/mocks"
 	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/validation"
+	"github.com/shopspring/decimal"
 	assert "github.com/stretchr/testify/require"
 
 	"go.uber.org/mock/gomock"
@@ -24,19 +37,35 @@ func TestCreateTransaction(t *testing.T) {
 	repo := mocks.NewMockRepository(mockCtrl)
 	accountsSvc := accountMocks.NewMockService(mockCtrl)
 
-	svc := transactions.NewService(repo, accountsSvc)
+	svc := transactions.NewService(
+		repo,
+		accountsSvc,
+		newOperationTypesMock(mockCtrl),
+		fxRatesMocks.NewMockService(mockCtrl),
+		newOutboxStoreMock(mockCtrl),
+	)
 
 	for _, req := range []*transactions.CreateTransactionRequest{
-		{AccountID: 1, OperationTypeID: operationtypes.CashPurchaseType, Amount: -0.01},
-		{AccountID: 1, OperationTypeID: operationtypes.CashPurchaseType, Amount: -1000},
-		{AccountID: 1, OperationTypeID: operationtypes.InstallmentType, Amount: -0.01},
-		{AccountID: 1, OperationTypeID: operationtypes.InstallmentType, Amount: -1000},
-		{AccountID: 1, OperationTypeID: operationtypes.WithdrawType, Amount: -0.01},
-		{AccountID: 1, OperationTypeID: operationtypes.WithdrawType, Amount: -1000},
-		{AccountID: 1, OperationTypeID: operationtypes.PaymentType, Amount: 0.01},
-		{AccountID: 1, OperationTypeID: operationtypes.PaymentType, Amount: 1000},
+		{AccountID: 1, OperationTypeID: operationtypes.CashPurchaseType, Amount: money.MustParse("-0.01")},
+		{AccountID: 1, OperationTypeID: operationtypes.CashPurchaseType, Amount: money.MustParse("-1000")},
+		{AccountID: 1, OperationTypeID: operationtypes.InstallmentType, Amount: money.MustParse("-0.01")},
+		{AccountID: 1, OperationTypeID: operationtypes.InstallmentType, Amount: money.MustParse("-1000")},
+		{AccountID: 1, OperationTypeID: operationtypes.WithdrawType, Amount: money.MustParse("-0.01")},
+		{AccountID: 1, OperationTypeID: operationtypes.WithdrawType, Amount: money.MustParse("-1000")},
+		{AccountID: 1, OperationTypeID: operationtypes.PaymentType, Amount: money.MustParse("0.01")},
+		{AccountID: 1, OperationTypeID: operationtypes.PaymentType, Amount: money.MustParse("1000")},
 	} {
 		t.Run("should create a new transaction", func(t *testing.T) {
+			expectRunInTx(repo)
+
+			repo.EXPECT().
+				LockAccount(gomock.Any(), req.AccountID).
+				Return(nil)
+
+			repo.EXPECT().
+				ListOpenTransactions(gomock.Any(), req.AccountID, req.Amount.IsNegative()).
+				Return([]*transactions.Transaction{}, nil)
+
 			repo.EXPECT().
 				CreateTransaction(gomock.Any(), gomock.Any()).
 				Do(func(_ context.Context, transaction *transactions.Transaction) {
@@ -44,9 +73,16 @@ func TestCreateTransaction(t *testing.T) {
 				}).
 				Return(nil)
 
+			if req.Amount.IsNegative() {
+				accountsSvc.EXPECT().
+					AddAvailableCreditLimit(gomock.Any(), req.AccountID, req.Amount).
+					Return(nil)
+			}
+
 			accountsSvc.EXPECT().
 				GetAccountByID(gomock.Any(), gomock.Any()).
-				Return(&accounts.Account{ID: 1}, nil)
+				Return(&accounts.Account{ID: 1, AvailableCreditLimit: money.MustParse("1000")}, nil).
+				Times(2)
 
 			ctx := context.TODO()
 			now := time.Now()
@@ -58,6 +94,7 @@ func TestCreateTransaction(t *testing.T) {
 			assert.Equal(t, req.AccountID, transaction.AccountID)
 			assert.Equal(t, req.OperationTypeID, transaction.OperationTypeID)
 			assert.Equal(t, req.Amount, transaction.Amount)
+			assert.Equal(t, req.Amount, transaction.Balance)
 			assert.WithinDuration(t, now, transaction.EventDate, 5*time.Millisecond)
 		})
 	}
@@ -70,15 +107,21 @@ func TestCreateAccountInvalid(t *testing.T) {
 	repo := mocks.NewMockRepository(mockCtrl)
 	accountsSvc := accountMocks.NewMockService(mockCtrl)
 
-	svc := transactions.NewService(repo, accountsSvc)
+	svc := transactions.NewService(
+		repo,
+		accountsSvc,
+		newOperationTypesMock(mockCtrl),
+		fxRatesMocks.NewMockService(mockCtrl),
+		newOutboxStoreMock(mockCtrl),
+	)
 
 	for _, req := range []*transactions.CreateTransactionRequest{
 		{},
-		{OperationTypeID: operationtypes.PaymentType, Amount: 1.15},
-		{AccountID: 1, Amount: 1.15},
-		{Amount: 1.15},
-		{AccountID: 1, OperationTypeID: operationtypes.PaymentType, Amount: 0},
-		{AccountID: 1, OperationTypeID: operationtypes.PaymentType, Amount: -0},
+		{OperationTypeID: operationtypes.PaymentType, Amount: money.MustParse("1.15")},
+		{AccountID: 1, Amount: money.MustParse("1.15")},
+		{Amount: money.MustParse("1.15")},
+		{AccountID: 1, OperationTypeID: operationtypes.PaymentType, Amount: money.MustParse("0")},
+		{AccountID: 1, OperationTypeID: operationtypes.PaymentType, Amount: money.MustParse("-0")},
 		{AccountID: 1, OperationTypeID: operationtypes.PaymentType},
 	} {
 		t.Run("should validate required field", func(t *testing.T) {
@@ -91,22 +134,28 @@ func TestCreateAccountInvalid(t *testing.T) {
 
 	t.Run("should return error if transaction type is invalid", func(t *testing.T) {
 		ctx := context.TODO()
+		counter := metrics.Transactions.WithLabelValues("invalid", "invalid_operation_type_id")
+		recorded := testutil.ToFloat64(counter)
 
 		_, err := svc.CreateTransaction(ctx, &transactions.CreateTransactionRequest{
 			AccountID:       1,
 			OperationTypeID: operationtypes.Type(789),
-			Amount:          1.15,
+			Amount:          money.MustParse("1.15"),
 		})
 		assert.ErrorIs(t, err, transactions.ErrInvalidOperationTypeID(nil))
+
+		// the unknown ids don't create new metric series
+		assert.Equal(t, recorded+1, testutil.ToFloat64(counter))
+		assert.Equal(t, 0, metrics.Transactions.DeletePartialMatch(prometheus.Labels{"operation_type": "789"}))
 	})
 
 	for _, req := range []*transactions.CreateTransactionRequest{
-		{AccountID: 1, OperationTypeID: operationtypes.CashPurchaseType, Amount: 0.01},
-		{AccountID: 1, OperationTypeID: operationtypes.CashPurchaseType, Amount: -1.019},
-		{AccountID: 1, OperationTypeID: operationtypes.InstallmentType, Amount: 0.01},
-		{AccountID: 1, OperationTypeID: operationtypes.WithdrawType, Amount: 0.01},
-		{AccountID: 1, OperationTypeID: operationtypes.PaymentType, Amount: -0.01},
-		{AccountID: 1, OperationTypeID: operationtypes.PaymentType, Amount: 1.019},
+		{AccountID: 1, OperationTypeID: operationtypes.CashPurchaseType, Amount: money.MustParse("0.01")},
+		{AccountID: 1, OperationTypeID: operationtypes.CashPurchaseType, Amount: money.MustParse("-1.019")},
+		{AccountID: 1, OperationTypeID: operationtypes.InstallmentType, Amount: money.MustParse("0.01")},
+		{AccountID: 1, OperationTypeID: operationtypes.WithdrawType, Amount: money.MustParse("0.01")},
+		{AccountID: 1, OperationTypeID: operationtypes.PaymentType, Amount: money.MustParse("-0.01")},
+		{AccountID: 1, OperationTypeID: operationtypes.PaymentType, Amount: money.MustParse("1.019")},
 	} {
 		t.Run("should return error if amount is invalid", func(t *testing.T) {
 			ctx := context.TODO()
@@ -126,7 +175,7 @@ func TestCreateAccountInvalid(t *testing.T) {
 		_, err := svc.CreateTransaction(ctx, &transactions.CreateTransactionRequest{
 			AccountID:       1,
 			OperationTypeID: operationtypes.PaymentType,
-			Amount:          1.15,
+			Amount:          money.MustParse("1.15"),
 		})
 		assert.ErrorIs(t, err, transactions.ErrAccountIDNotFound(nil))
 	})
@@ -134,22 +183,1866 @@ func TestCreateAccountInvalid(t *testing.T) {
 	t.Run("should return error from repo create transaction", func(t *testing.T) {
 		dbTimeoutErr := errors.New("db timeout error")
 
+		expectRunInTx(repo)
+
+		repo.EXPECT().
+			LockAccount(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		repo.EXPECT().
+			ListOpenTransactions(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]*transactions.Transaction{}, nil)
+
 		repo.EXPECT().
 			CreateTransaction(gomock.Any(), gomock.Any()).
 			Return(dbTimeoutErr)
 
 		accountsSvc.EXPECT().
 			GetAccountByID(gomock.Any(), gomock.Any()).
-			Return(&accounts.Account{ID: 1}, nil)
+			Return(&accounts.Account{ID: 1}, nil).
+			Times(2)
 
 		ctx := context.TODO()
 
 		transaction, err := svc.CreateTransaction(ctx, &transactions.CreateTransactionRequest{
 			AccountID:       1,
 			OperationTypeID: operationtypes.PaymentType,
-			Amount:          1.15,
+			Amount:          money.MustParse("1.15"),
+		})
+		assert.ErrorIs(t, err, dbTimeoutErr)
+		assert.Nil(t, transaction)
+	})
+}
+
+func TestCreateTransactionDischarge(t *testing.T) {
+	setupMocks := func(
+		t *testing.T,
+		openTransactions []*transactions.Transaction,
+		limitChange string,
+	) (transactions.Service, map[int64]string) {
+		mockCtrl := gomock.NewController(t)
+		repo := mocks.NewMockRepository(mockCtrl)
+		accountsSvc := accountMocks.NewMockService(mockCtrl)
+
+		svc := transactions.NewService(
+			repo,
+			accountsSvc,
+			newOperationTypesMock(mockCtrl),
+			fxRatesMocks.NewMockService(mockCtrl),
+			newOutboxStoreMock(mockCtrl),
+		)
+		updatedBalances := map[int64]string{}
+
+		accountsSvc.EXPECT().
+			GetAccountByID(gomock.Any(), gomock.Any()).
+			Return(&accounts.Account{ID: 1, AvailableCreditLimit: money.MustParse("1000")}, nil).
+			MinTimes(1)
+
+		accountsSvc.EXPECT().
+			AddAvailableCreditLimit(gomock.Any(), int64(1), money.MustParse(limitChange)).
+			Return(nil)
+
+		expectRunInTx(repo)
+
+		repo.EXPECT().
+			LockAccount(gomock.Any(), int64(1)).
+			Return(nil)
+
+		repo.EXPECT().
+			ListOpenTransactions(gomock.Any(), int64(1), gomock.Any()).
+			Return(openTransactions, nil)
+
+		repo.EXPECT().
+			UpdateTransactionBalance(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, transaction *transactions.Transaction) {
+				updatedBalances[transaction.ID] = transaction.Balance.String()
+			}).
+			Return(nil).
+			AnyTimes()
+
+		repo.EXPECT().
+			CreateTransaction(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		return svc, updatedBalances
+	}
+
+	t.Run("should pay down the oldest purchases first", func(t *testing.T) {
+		svc, updatedBalances := setupMocks(t, []*transactions.Transaction{
+			newOpenTransaction(1, operationtypes.CashPurchaseType, "-50", "-50"),
+			newOpenTransaction(2, operationtypes.CashPurchaseType, "-23.5", "-23.5"),
+			newOpenTransaction(3, operationtypes.CashPurchaseType, "-18.7", "-18.7"),
+		}, "60")
+
+		transaction, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.PaymentType,
+			Amount:          money.MustParse("60"),
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "0.00", transaction.Balance.String())
+		assert.Equal(t, map[int64]string{1: "0.00", 2: "-13.50"}, updatedBalances)
+	})
+
+	t.Run("should keep the leftover payment as credit", func(t *testing.T) {
+		svc, updatedBalances := setupMocks(t, []*transactions.Transaction{
+			newOpenTransaction(1, operationtypes.CashPurchaseType, "-50", "-13.5"),
+			newOpenTransaction(2, operationtypes.CashPurchaseType, "-18.7", "-18.7"),
+		}, "32.2")
+
+		transaction, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.PaymentType,
+			Amount:          money.MustParse("100"),
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "67.80", transaction.Balance.String())
+		assert.Equal(t, map[int64]string{1: "0.00", 2: "0.00"}, updatedBalances)
+	})
+
+	t.Run("should consume the credit left by payments", func(t *testing.T) {
+		svc, updatedBalances := setupMocks(t, []*transactions.Transaction{
+			newOpenTransaction(4, operationtypes.PaymentType, "100", "67.8"),
+		}, "-12.3")
+
+		transaction, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.CashPurchaseType,
+			Amount:          money.MustParse("-80.1"),
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "-12.30", transaction.Balance.String())
+		assert.Equal(t, map[int64]string{4: "0.00"}, updatedBalances)
+	})
+
+	t.Run("should return error if settling fails", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		repo := mocks.NewMockRepository(mockCtrl)
+		accountsSvc := accountMocks.NewMockService(mockCtrl)
+
+		svc := transactions.NewService(
+			repo,
+			accountsSvc,
+			newOperationTypesMock(mockCtrl),
+			fxRatesMocks.NewMockService(mockCtrl),
+			newOutboxStoreMock(mockCtrl),
+		)
+		dbTimeoutErr := errors.New("db timeout error")
+
+		accountsSvc.EXPECT().
+			GetAccountByID(gomock.Any(), gomock.Any()).
+			Return(&accounts.Account{ID: 1}, nil).
+			Times(2)
+
+		expectRunInTx(repo)
+
+		repo.EXPECT().
+			LockAccount(gomock.Any(), int64(1)).
+			Return(nil)
+
+		repo.EXPECT().
+			ListOpenTransactions(gomock.Any(), int64(1), false).
+			Return(nil, dbTimeoutErr)
+
+		transaction, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.PaymentType,
+			Amount:          money.MustParse("10"),
 		})
 		assert.ErrorIs(t, err, dbTimeoutErr)
 		assert.Nil(t, transaction)
 	})
 }
+
+func TestCreateTransactionCreditLimit(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	accountsSvc := accountMocks.NewMockService(mockCtrl)
+
+	svc := transactions.NewService(
+		repo,
+		accountsSvc,
+		newOperationTypesMock(mockCtrl),
+		fxRatesMocks.NewMockService(mockCtrl),
+		newOutboxStoreMock(mockCtrl),
+	)
+
+	for _, req := range []*transactions.CreateTransactionRequest{
+		{AccountID: 1, OperationTypeID: operationtypes.CashPurchaseType, Amount: money.MustParse("-100.01")},
+		{AccountID: 1, OperationTypeID: operationtypes.InstallmentType, Amount: money.MustParse("-150")},
+		{AccountID: 1, OperationTypeID: operationtypes.WithdrawType, Amount: money.MustParse("-1000")},
+	} {
+		t.Run("should reject transactions above the available credit limit", func(t *testing.T) {
+			accountsSvc.EXPECT().
+				GetAccountByID(gomock.Any(), int64(1)).
+				Return(&accounts.Account{ID: 1, AvailableCreditLimit: money.MustParse("100")}, nil).
+				Times(2)
+
+			expectRunInTx(repo)
+
+			repo.EXPECT().
+				LockAccount(gomock.Any(), int64(1)).
+				Return(nil)
+
+			repo.EXPECT().
+				ListOpenTransactions(gomock.Any(), int64(1), true).
+				Return([]*transactions.Transaction{}, nil)
+
+			transaction, err := svc.CreateTransaction(context.TODO(), req)
+			assert.ErrorIs(t, err, transactions.ErrInsufficientCreditLimit(nil))
+			assert.Nil(t, transaction)
+		})
+	}
+
+	t.Run("should accept transactions equal to the available credit limit", func(t *testing.T) {
+		accountsSvc.EXPECT().
+			GetAccountByID(gomock.Any(), int64(1)).
+			Return(&accounts.Account{ID: 1, AvailableCreditLimit: money.MustParse("100")}, nil).
+			Times(2)
+
+		accountsSvc.EXPECT().
+			AddAvailableCreditLimit(gomock.Any(), int64(1), money.MustParse("-100")).
+			Return(nil)
+
+		expectRunInTx(repo)
+
+		repo.EXPECT().
+			LockAccount(gomock.Any(), int64(1)).
+			Return(nil)
+
+		repo.EXPECT().
+			ListOpenTransactions(gomock.Any(), int64(1), true).
+			Return([]*transactions.Transaction{}, nil)
+
+		repo.EXPECT().
+			CreateTransaction(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		_, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.CashPurchaseType,
+			Amount:          money.MustParse("-100"),
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("should only consume the credit limit not covered by previous payments", func(t *testing.T) {
+		accountsSvc.EXPECT().
+			GetAccountByID(gomock.Any(), int64(1)).
+			Return(&accounts.Account{ID: 1, AvailableCreditLimit: money.MustParse("100")}, nil).
+			Times(2)
+
+		accountsSvc.EXPECT().
+			AddAvailableCreditLimit(gomock.Any(), int64(1), money.MustParse("-70")).
+			Return(nil)
+
+		expectRunInTx(repo)
+
+		repo.EXPECT().
+			LockAccount(gomock.Any(), int64(1)).
+			Return(nil)
+
+		repo.EXPECT().
+			ListOpenTransactions(gomock.Any(), int64(1), true).
+			Return([]*transactions.Transaction{newOpenTransaction(1, operationtypes.PaymentType, "200", "80")}, nil)
+
+		repo.EXPECT().
+			UpdateTransactionBalance(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		repo.EXPECT().
+			CreateTransaction(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		_, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.CashPurchaseType,
+			Amount:          money.MustParse("-150"),
+		})
+		assert.NoError(t, err)
+	})
+
+	for name, testCase := range map[string]struct {
+		openTransactions []*transactions.Transaction
+		restored         string
+	}{
+		"should restore the credit limit with payments": {
+			openTransactions: []*transactions.Transaction{
+				newOpenTransaction(1, operationtypes.CashPurchaseType, "-300", "-300"),
+			},
+			restored: "250.5",
+		},
+		"should only restore the settled purchases with overpayments": {
+			openTransactions: []*transactions.Transaction{
+				newOpenTransaction(1, operationtypes.CashPurchaseType, "-100", "-100"),
+			},
+			restored: "100",
+		},
+		"should not restore the credit limit with the settled interest": {
+			openTransactions: []*transactions.Transaction{
+				newOpenTransaction(1, operationtypes.InterestType, "-30", "-30"),
+				newOpenTransaction(2, operationtypes.CashPurchaseType, "-300", "-300"),
+			},
+			restored: "220.5",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			accountsSvc.EXPECT().
+				GetAccountByID(gomock.Any(), int64(1)).
+				Return(&accounts.Account{ID: 1}, nil).
+				Times(2)
+
+			accountsSvc.EXPECT().
+				AddAvailableCreditLimit(gomock.Any(), int64(1), money.MustParse(testCase.restored)).
+				Return(nil)
+
+			expectRunInTx(repo)
+
+			repo.EXPECT().
+				LockAccount(gomock.Any(), int64(1)).
+				Return(nil)
+
+			repo.EXPECT().
+				ListOpenTransactions(gomock.Any(), int64(1), false).
+				Return(testCase.openTransactions, nil)
+
+			repo.EXPECT().
+				UpdateTransactionBalance(gomock.Any(), gomock.Any()).
+				Return(nil).
+				Times(len(testCase.openTransactions))
+
+			repo.EXPECT().
+				CreateTransaction(gomock.Any(), gomock.Any()).
+				Return(nil)
+
+			_, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+				AccountID:       1,
+				OperationTypeID: operationtypes.PaymentType,
+				Amount:          money.MustParse("250.5"),
+			})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCreateTransactionOperationTypeRules(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	accountsSvc := accountMocks.NewMockService(mockCtrl)
+	opTypesSvc := opTypesMocks.NewMockService(mockCtrl)
+
+	svc := transactions.NewService(
+		repo,
+		accountsSvc,
+		opTypesSvc,
+		fxRatesMocks.NewMockService(mockCtrl),
+		newOutboxStoreMock(mockCtrl),
+	)
+
+	feeType := operationtypes.Type(10)
+	opTypesSvc.EXPECT().
+		GetOperationType(gomock.Any(), feeType).
+		Return(&operationtypes.OperationType{ID: feeType, Sign: operationtypes.NegativeSign}, nil).
+		AnyTimes()
+
+	t.Run("should use the sign of the operation type", func(t *testing.T) {
+		_, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: feeType,
+			Amount:          money.MustParse("10"),
+		})
+		assert.ErrorIs(t, err, transactions.ErrInvalidAmount(nil))
+	})
+
+	t.Run("should skip the credit limit if operation type doesn't affect it", func(t *testing.T) {
+		accountsSvc.EXPECT().
+			GetAccountByID(gomock.Any(), int64(1)).
+			Return(&accounts.Account{ID: 1}, nil).
+			Times(2)
+
+		expectRunInTx(repo)
+
+		repo.EXPECT().
+			LockAccount(gomock.Any(), int64(1)).
+			Return(nil)
+
+		repo.EXPECT().
+			ListOpenTransactions(gomock.Any(), int64(1), true).
+			Return([]*transactions.Transaction{}, nil)
+
+		repo.EXPECT().
+			CreateTransaction(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		transaction, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: feeType,
+			Amount:          money.MustParse("-10"),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "-10.00", transaction.Amount.String())
+	})
+}
+
+func TestCreateSystemTransaction(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	accountsSvc := accountMocks.NewMockService(mockCtrl)
+	opTypesSvc := opTypesMocks.NewMockService(mockCtrl)
+
+	svc := transactions.NewService(
+		repo,
+		accountsSvc,
+		opTypesSvc,
+		fxRatesMocks.NewMockService(mockCtrl),
+		newOutboxStoreMock(mockCtrl),
+	)
+
+	opTypesSvc.EXPECT().
+		GetOperationType(gomock.Any(), operationtypes.InterestType).
+		Return(&operationtypes.OperationType{
+			ID:              operationtypes.InterestType,
+			Sign:            operationtypes.NegativeSign,
+			SystemGenerated: true,
+		}, nil).
+		AnyTimes()
+	opTypesSvc.EXPECT().
+		GetOperationType(gomock.Any(), operationtypes.CashPurchaseType).
+		Return(&operationtypes.OperationType{
+			ID:                 operationtypes.CashPurchaseType,
+			Sign:               operationtypes.NegativeSign,
+			AffectsCreditLimit: true,
+		}, nil).
+		AnyTimes()
+
+	t.Run("should reject system generated operation types in the public API", func(t *testing.T) {
+		_, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.InterestType,
+			Amount:          money.MustParse("-1.5"),
+		})
+		assert.ErrorIs(t, err, transactions.ErrInvalidOperationTypeID(nil))
+	})
+
+	t.Run("should reject customer operation types as system transactions", func(t *testing.T) {
+		_, err := svc.CreateSystemTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.CashPurchaseType,
+			Amount:          money.MustParse("-1.5"),
+		})
+		assert.ErrorIs(t, err, transactions.ErrInvalidOperationTypeID(nil))
+	})
+
+	t.Run("should post the system generated charges", func(t *testing.T) {
+		accountsSvc.EXPECT().
+			GetAccountByID(gomock.Any(), int64(1)).
+			Return(&accounts.Account{ID: 1, Currency: "BRL"}, nil).
+			Times(2)
+
+		expectRunInTx(repo)
+
+		repo.EXPECT().
+			LockAccount(gomock.Any(), int64(1)).
+			Return(nil)
+
+		repo.EXPECT().
+			ListOpenTransactions(gomock.Any(), int64(1), true).
+			Return([]*transactions.Transaction{}, nil)
+
+		repo.EXPECT().
+			CreateTransaction(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		transaction, err := svc.CreateSystemTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.InterestType,
+			Amount:          money.MustParse("-1.5"),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, operationtypes.InterestType, transaction.OperationTypeID)
+		assert.Equal(t, "-1.50", transaction.Amount.String())
+		assert.WithinDuration(t, time.Now(), transaction.EventDate, time.Second)
+	})
+}
+
+func TestCreateTransactionAccountStatus(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	accountsSvc := accountMocks.NewMockService(mockCtrl)
+	opTypesSvc := opTypesMocks.NewMockService(mockCtrl)
+
+	svc := transactions.NewService(
+		repo,
+		accountsSvc,
+		opTypesSvc,
+		fxRatesMocks.NewMockService(mockCtrl),
+		newOutboxStoreMock(mockCtrl),
+	)
+
+	opTypesSvc.EXPECT().
+		GetOperationType(gomock.Any(), operationtypes.CashPurchaseType).
+		Return(&operationtypes.OperationType{
+			ID:                 operationtypes.CashPurchaseType,
+			Sign:               operationtypes.NegativeSign,
+			AffectsCreditLimit: true,
+		}, nil).
+		AnyTimes()
+	opTypesSvc.EXPECT().
+		GetOperationType(gomock.Any(), operationtypes.PaymentType).
+		Return(&operationtypes.OperationType{
+			ID:   operationtypes.PaymentType,
+			Sign: operationtypes.PositiveSign,
+		}, nil).
+		AnyTimes()
+
+	accountsSvc.EXPECT().
+		GetAccountByID(gomock.Any(), int64(1)).
+		Return(&accounts.Account{ID: 1, Status: accounts.BlockedStatus}, nil).
+		AnyTimes()
+	accountsSvc.EXPECT().
+		GetAccountByID(gomock.Any(), int64(2)).
+		Return(&accounts.Account{ID: 2, Status: accounts.ClosedStatus}, nil).
+		AnyTimes()
+
+	expectLockedAccount := func(accountID int64) {
+		expectRunInTx(repo)
+
+		repo.EXPECT().
+			LockAccount(gomock.Any(), accountID).
+			Return(nil)
+	}
+
+	t.Run("should reject purchases on blocked accounts", func(t *testing.T) {
+		expectLockedAccount(1)
+
+		_, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.CashPurchaseType,
+			Amount:          money.MustParse("-10"),
+		})
+		assert.ErrorIs(t, err, transactions.ErrAccountBlocked(nil))
+	})
+
+	t.Run("should accept payments on blocked accounts", func(t *testing.T) {
+		expectRunInTx(repo)
+
+		repo.EXPECT().
+			LockAccount(gomock.Any(), int64(1)).
+			Return(nil)
+
+		repo.EXPECT().
+			ListOpenTransactions(gomock.Any(), int64(1), false).
+			Return([]*transactions.Transaction{}, nil)
+
+		repo.EXPECT().
+			CreateTransaction(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		transaction, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.PaymentType,
+			Amount:          money.MustParse("10"),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "10.00", transaction.Amount.String())
+	})
+
+	t.Run("should reject any transaction on closed accounts", func(t *testing.T) {
+		expectLockedAccount(2)
+
+		_, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       2,
+			OperationTypeID: operationtypes.CashPurchaseType,
+			Amount:          money.MustParse("-10"),
+		})
+		assert.ErrorIs(t, err, transactions.ErrAccountClosed(nil))
+
+		expectLockedAccount(2)
+
+		_, err = svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       2,
+			OperationTypeID: operationtypes.PaymentType,
+			Amount:          money.MustParse("10"),
+		})
+		assert.ErrorIs(t, err, transactions.ErrAccountClosed(nil))
+	})
+
+	t.Run("should check the status of the account after locking it", func(t *testing.T) {
+		gomock.InOrder(
+			accountsSvc.EXPECT().
+				GetAccountByID(gomock.Any(), int64(3)).
+				Return(&accounts.Account{ID: 3, Status: accounts.ActiveStatus}, nil),
+			accountsSvc.EXPECT().
+				GetAccountByID(gomock.Any(), int64(3)).
+				Return(&accounts.Account{ID: 3, Status: accounts.BlockedStatus}, nil),
+		)
+
+		expectLockedAccount(3)
+
+		_, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       3,
+			OperationTypeID: operationtypes.CashPurchaseType,
+			Amount:          money.MustParse("-10"),
+		})
+		assert.ErrorIs(t, err, transactions.ErrAccountBlocked(nil))
+	})
+}
+
+func TestCreateTransactionFXConversion(t *testing.T) {
+	setupMocks := func(t *testing.T) (transactions.Service, *mocks.MockRepository, *accountMocks.MockService, *fxRatesMocks.MockService) {
+		mockCtrl := gomock.NewController(t)
+		repo := mocks.NewMockRepository(mockCtrl)
+		accountsSvc := accountMocks.NewMockService(mockCtrl)
+		fxRatesSvc := fxRatesMocks.NewMockService(mockCtrl)
+
+		svc := transactions.NewService(
+			repo,
+			accountsSvc,
+			newOperationTypesMock(mockCtrl),
+			fxRatesSvc,
+			newOutboxStoreMock(mockCtrl),
+		)
+
+		accountsSvc.EXPECT().
+			GetAccountByID(gomock.Any(), int64(1)).
+			Return(&accounts.Account{ID: 1, Currency: "BRL", AvailableCreditLimit: money.MustParse("1000")}, nil).
+			AnyTimes()
+
+		return svc, repo, accountsSvc, fxRatesSvc
+	}
+
+	expectCreate := func(repo *mocks.MockRepository, accountsSvc *accountMocks.MockService) {
+		expectRunInTx(repo)
+
+		repo.EXPECT().
+			LockAccount(gomock.Any(), int64(1)).
+			Return(nil)
+
+		repo.EXPECT().
+			ListOpenTransactions(gomock.Any(), int64(1), true).
+			Return([]*transactions.Transaction{}, nil)
+
+		repo.EXPECT().
+			CreateTransaction(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		accountsSvc.EXPECT().
+			AddAvailableCreditLimit(gomock.Any(), int64(1), gomock.Any()).
+			Return(nil)
+	}
+
+	t.Run("should convert foreign currency amounts to the account currency", func(t *testing.T) {
+		svc, repo, accountsSvc, fxRatesSvc := setupMocks(t)
+		expectCreate(repo, accountsSvc)
+
+		fxRatesSvc.EXPECT().
+			GetRate(gomock.Any(), "USD", "BRL", gomock.Any()).
+			Return(&fxrates.Rate{FromCurrency: "USD", ToCurrency: "BRL", Rate: decimal.RequireFromString("4.9137")}, nil)
+
+		transaction, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.CashPurchaseType,
+			Amount:          money.MustParse("-10.5"),
+			Currency:        "usd",
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "BRL", transaction.Currency)
+		assert.Equal(t, "-51.59", transaction.Amount.String())
+		assert.Equal(t, "-51.59", transaction.Balance.String())
+		assert.Equal(t, "USD", transaction.FXConversion.Currency)
+		assert.Equal(t, "-10.50", transaction.FXConversion.Amount.String())
+		assert.Equal(t, "4.9137", transaction.FXConversion.Rate.String())
+	})
+
+	t.Run("should not convert amounts in the account currency", func(t *testing.T) {
+		svc, repo, accountsSvc, _ := setupMocks(t)
+		expectCreate(repo, accountsSvc)
+
+		transaction, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.CashPurchaseType,
+			Amount:          money.MustParse("-10.5"),
+			Currency:        "brl",
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "BRL", transaction.Currency)
+		assert.Equal(t, "-10.50", transaction.Amount.String())
+		assert.Nil(t, transaction.FXConversion)
+	})
+
+	t.Run("should return error if there is no rate for the currency", func(t *testing.T) {
+		svc, _, _, fxRatesSvc := setupMocks(t)
+
+		fxRatesSvc.EXPECT().
+			GetRate(gomock.Any(), "EUR", "BRL", gomock.Any()).
+			Return(nil, fxrates.ErrRateNotFound(nil))
+
+		_, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.CashPurchaseType,
+			Amount:          money.MustParse("-10"),
+			Currency:        "EUR",
+		})
+		assert.ErrorIs(t, err, fxrates.ErrRateNotFound(nil))
+	})
+
+	t.Run("should return error if currency is invalid", func(t *testing.T) {
+		svc, _, _, _ := setupMocks(t)
+
+		_, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.CashPurchaseType,
+			Amount:          money.MustParse("-10"),
+			Currency:        "ABCD",
+		})
+		assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+	})
+}
+
+func TestCreateTransactionInstallments(t *testing.T) {
+	setupMocks := func(t *testing.T) (transactions.Service, *mocks.MockRepository, *accountMocks.MockService) {
+		mockCtrl := gomock.NewController(t)
+		repo := mocks.NewMockRepository(mockCtrl)
+		accountsSvc := accountMocks.NewMockService(mockCtrl)
+
+		svc := transactions.NewService(
+			repo,
+			accountsSvc,
+			newOperationTypesMock(mockCtrl),
+			fxRatesMocks.NewMockService(mockCtrl),
+			newOutboxStoreMock(mockCtrl),
+		)
+
+		accountsSvc.EXPECT().
+			GetAccountByID(gomock.Any(), int64(1)).
+			Return(&accounts.Account{ID: 1, AvailableCreditLimit: money.MustParse("1000")}, nil).
+			AnyTimes()
+
+		return svc, repo, accountsSvc
+	}
+
+	t.Run("should create an installment plan with monthly due dates", func(t *testing.T) {
+		svc, repo, accountsSvc := setupMocks(t)
+		now := time.Now().UTC()
+
+		expectRunInTx(repo)
+
+		repo.EXPECT().
+			LockAccount(gomock.Any(), int64(1)).
+			Return(nil)
+
+		repo.EXPECT().
+			ListOpenTransactions(gomock.Any(), int64(1), true).
+			Return([]*transactions.Transaction{}, nil)
+
+		repo.EXPECT().
+			CreateTransaction(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, transaction *transactions.Transaction) {
+				transaction.ID = 7
+			}).
+			Return(nil)
+
+		repo.EXPECT().
+			CreateInstallmentPlan(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, plan *transactions.InstallmentPlan) {
+				assert.Equal(t, int64(7), plan.TransactionID)
+				assert.Equal(t, int64(1), plan.AccountID)
+				assert.Equal(t, "-100.00", plan.TotalAmount.String())
+				assert.Len(t, plan.Schedule, 3)
+
+				for idx, amount := range []string{"-33.34", "-33.33", "-33.33"} {
+					installment := plan.Schedule[idx]
+					dueMonth := time.Date(now.Year(), now.Month()+time.Month(idx+1), 1, 0, 0, 0, 0, time.UTC)
+
+					assert.Equal(t, idx+1, installment.Number)
+					assert.Equal(t, amount, installment.Amount.String())
+					assert.Equal(t, dueMonth.Year(), installment.DueDate.Year())
+					assert.Equal(t, dueMonth.Month(), installment.DueDate.Month())
+					assert.LessOrEqual(t, installment.DueDate.Day(), now.Day())
+				}
+			}).
+			Return(nil)
+
+		accountsSvc.EXPECT().
+			AddAvailableCreditLimit(gomock.Any(), int64(1), gomock.Any()).
+			Return(nil)
+
+		transaction, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.InstallmentType,
+			Amount:          money.MustParse("-100"),
+			Installments:    3,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "-100.00", transaction.Amount.String())
+	})
+
+	for _, req := range []*transactions.CreateTransactionRequest{
+		{AccountID: 1, OperationTypeID: operationtypes.CashPurchaseType, Amount: money.MustParse("-100"), Installments: 2},
+		{AccountID: 1, OperationTypeID: operationtypes.InstallmentType, Amount: money.MustParse("-0.02"), Installments: 3},
+	} {
+		t.Run("should return error if installments are invalid", func(t *testing.T) {
+			svc, _, _ := setupMocks(t)
+
+			_, err := svc.CreateTransaction(context.TODO(), req)
+			assert.ErrorIs(t, err, transactions.ErrInvalidInstallments(nil))
+		})
+	}
+
+	for _, installments := range []int{-1, 49} {
+		t.Run("should return error if installments are out of range", func(t *testing.T) {
+			svc, _, _ := setupMocks(t)
+
+			_, err := svc.CreateTransaction(context.TODO(), &transactions.CreateTransactionRequest{
+				AccountID:       1,
+				OperationTypeID: operationtypes.InstallmentType,
+				Amount:          money.MustParse("-100"),
+				Installments:    installments,
+			})
+			assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+		})
+	}
+}
+
+func TestGetInstallmentPlan(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	svc := transactions.NewService(
+		repo,
+		accountMocks.NewMockService(mockCtrl),
+		newOperationTypesMock(mockCtrl),
+		fxRatesMocks.NewMockService(mockCtrl),
+		newOutboxStoreMock(mockCtrl),
+	)
+
+	t.Run("should return the schedule with the paid status", func(t *testing.T) {
+		ctx := context.TODO()
+
+		repo.EXPECT().GetInstallmentPlanByTransactionID(ctx, int64(7)).
+			Return(&transactions.InstallmentPlan{
+				ID:            1,
+				TransactionID: 7,
+				TotalAmount:   money.MustParse("-100"),
+				Schedule: []*transactions.Installment{
+					{Number: 1, Amount: money.MustParse("-33.34")},
+					{Number: 2, Amount: money.MustParse("-33.33")},
+					{Number: 3, Amount: money.MustParse("-33.33")},
+				},
+			}, nil)
+
+		repo.EXPECT().GetTransactionByID(ctx, int64(7)).
+			Return(&transactions.Transaction{
+				ID:      7,
+				Amount:  money.MustParse("-100"),
+				Balance: money.MustParse("-50"),
+			}, nil)
+
+		repo.EXPECT().GetReversedAmount(ctx, int64(7)).
+			Return(money.NewFromInt(0), nil)
+
+		plan, err := svc.GetInstallmentPlan(ctx, 7)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "50.00", plan.PaidAmount.String())
+		assert.Equal(t, transactions.InstallmentPaid, plan.Schedule[0].Status)
+		assert.Equal(t, "33.34", plan.Schedule[0].PaidAmount.String())
+		assert.Equal(t, transactions.InstallmentPartiallyPaid, plan.Schedule[1].Status)
+		assert.Equal(t, "16.66", plan.Schedule[1].PaidAmount.String())
+		assert.Equal(t, transactions.InstallmentUnpaid, plan.Schedule[2].Status)
+		assert.Equal(t, "0.00", plan.Schedule[2].PaidAmount.String())
+	})
+
+	t.Run("should cancel the last installments with the reversals", func(t *testing.T) {
+		ctx := context.TODO()
+
+		repo.EXPECT().GetInstallmentPlanByTransactionID(ctx, int64(9)).
+			Return(&transactions.InstallmentPlan{
+				ID:            2,
+				TransactionID: 9,
+				TotalAmount:   money.MustParse("-100"),
+				Schedule: []*transactions.Installment{
+					{Number: 1, Amount: money.MustParse("-33.34")},
+					{Number: 2, Amount: money.MustParse("-33.33")},
+					{Number: 3, Amount: money.MustParse("-33.33")},
+				},
+			}, nil)
+
+		// 40.00 reversed and 20.00 paid
+		repo.EXPECT().GetTransactionByID(ctx, int64(9)).
+			Return(&transactions.Transaction{
+				ID:      9,
+				Amount:  money.MustParse("-100"),
+				Balance: money.MustParse("-40"),
+			}, nil)
+
+		repo.EXPECT().GetReversedAmount(ctx, int64(9)).
+			Return(money.MustParse("40"), nil)
+
+		plan, err := svc.GetInstallmentPlan(ctx, 9)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "20.00", plan.PaidAmount.String())
+		assert.Equal(t, "40.00", plan.ReversedAmount.String())
+
+		statuses := []transactions.InstallmentStatus{}
+		for _, installment := range plan.Schedule {
+			statuses = append(statuses, installment.Status)
+		}
+
+		assert.Equal(t, []transactions.InstallmentStatus{
+			transactions.InstallmentPartiallyPaid,
+			transactions.InstallmentUnpaid,
+			transactions.InstallmentReversed,
+		}, statuses)
+		assert.Equal(t, "20.00", plan.Schedule[0].PaidAmount.String())
+		assert.Equal(t, "6.67", plan.Schedule[1].ReversedAmount.String())
+		assert.Equal(t, "33.33", plan.Schedule[2].ReversedAmount.String())
+	})
+
+	t.Run("should return error if plan is not found", func(t *testing.T) {
+		ctx := context.TODO()
+
+		repo.EXPECT().GetInstallmentPlanByTransactionID(ctx, int64(8)).
+			Return(nil, errorlib.ErrNotFound(nil))
+
+		_, err := svc.GetInstallmentPlan(ctx, 8)
+		assert.ErrorIs(t, err, errorlib.ErrNotFound(nil))
+	})
+}
+
+func TestCreateTransactionEvent(t *testing.T) {
+	setupMocks := func(t *testing.T) (transactions.Service, *mocks.MockRepository, *outboxMocks.MockStore) {
+		mockCtrl := gomock.NewController(t)
+		repo := mocks.NewMockRepository(mockCtrl)
+		accountsSvc := accountMocks.NewMockService(mockCtrl)
+		events := outboxMocks.NewMockStore(mockCtrl)
+
+		svc := transactions.NewService(
+			repo,
+			accountsSvc,
+			newOperationTypesMock(mockCtrl),
+			fxRatesMocks.NewMockService(mockCtrl),
+			events,
+		)
+
+		accountsSvc.EXPECT().
+			GetAccountByID(gomock.Any(), int64(1)).
+			Return(&accounts.Account{ID: 1, Currency: "BRL"}, nil).
+			Times(2)
+
+		expectRunInTx(repo)
+		repo.EXPECT().LockAccount(gomock.Any(), int64(1)).Return(nil)
+		repo.EXPECT().
+			ListOpenTransactions(gomock.Any(), int64(1), false).
+			Return([]*transactions.Transaction{}, nil)
+		repo.EXPECT().
+			CreateTransaction(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, transaction *transactions.Transaction) {
+				transaction.ID = 10
+			}).
+			Return(nil)
+
+		return svc, repo, events
+	}
+
+	t.Run("should add the transaction created event in the same transaction", func(t *testing.T) {
+		svc, _, events := setupMocks(t)
+		ctx := context.TODO()
+
+		var event *outbox.Event
+		events.EXPECT().
+			AddEvent(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, savedEvent *outbox.Event) {
+				event = savedEvent
+			}).
+			Return(nil)
+
+		_, err := svc.CreateTransaction(ctx, &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.PaymentType,
+			Amount:          money.MustParse("50"),
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, transactions.TransactionCreatedEvent, event.Type)
+		assert.Equal(t, int64(1), event.AccountID)
+
+		payload := map[string]any{}
+		assert.NoError(t, json.Unmarshal(event.Payload, &payload))
+		assert.Equal(t, float64(10), payload["transaction_id"])
+		assert.Equal(t, float64(operationtypes.PaymentType), payload["operation_type_id"])
+	})
+
+	t.Run("should return error if the event is not saved", func(t *testing.T) {
+		svc, _, events := setupMocks(t)
+		ctx := context.TODO()
+		dbErr := errors.New("db error")
+
+		events.EXPECT().
+			AddEvent(gomock.Any(), gomock.Any()).
+			Return(dbErr)
+
+		_, err := svc.CreateTransaction(ctx, &transactions.CreateTransactionRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.PaymentType,
+			Amount:          money.MustParse("50"),
+		})
+		assert.ErrorIs(t, err, dbErr)
+	})
+}
+
+func TestListAccountTransactions(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	accountsSvc := accountMocks.NewMockService(mockCtrl)
+
+	svc := transactions.NewService(
+		repo,
+		accountsSvc,
+		newOperationTypesMock(mockCtrl),
+		fxRatesMocks.NewMockService(mockCtrl),
+		newOutboxStoreMock(mockCtrl),
+	)
+	now := time.Now()
+
+	t.Run("should list the account transactions", func(t *testing.T) {
+		accountsSvc.EXPECT().
+			GetAccountByID(gomock.Any(), int64(1)).
+			Return(&accounts.Account{ID: 1}, nil)
+
+		repo.EXPECT().
+			ListAccountTransactions(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, filter *transactions.TransactionsFilter) ([]*transactions.Transaction, error) {
+				assert.Equal(t, int64(1), filter.AccountID)
+				assert.Equal(t, operationtypes.PaymentType, filter.OperationTypeID)
+				assert.Equal(t, 3, filter.Limit)
+				assert.Nil(t, filter.After)
+
+				return []*transactions.Transaction{
+					{ID: 3, AccountID: 1, OperationTypeID: operationtypes.PaymentType, Amount: money.MustParse("10"), EventDate: now},
+				}, nil
+			})
+
+		page, err := svc.ListAccountTransactions(context.TODO(), &transactions.ListTransactionsRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.PaymentType,
+			Limit:           2,
+		})
+		assert.NoError(t, err)
+
+		assert.Len(t, page.Items, 1)
+		assert.Equal(t, int64(3), page.Items[0].ID)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("should return a cursor to the next page", func(t *testing.T) {
+		accountsSvc.EXPECT().
+			GetAccountByID(gomock.Any(), int64(1)).
+			Return(&accounts.Account{ID: 1}, nil).
+			Times(2)
+
+		repo.EXPECT().
+			ListAccountTransactions(gomock.Any(), gomock.Any()).
+			Return([]*transactions.Transaction{
+				{ID: 3, AccountID: 1, EventDate: now},
+				{ID: 2, AccountID: 1, EventDate: now.Add(-time.Minute)},
+				{ID: 1, AccountID: 1, EventDate: now.Add(-2 * time.Minute)},
+			}, nil)
+
+		page, err := svc.ListAccountTransactions(context.TODO(), &transactions.ListTransactionsRequest{
+			AccountID: 1,
+			Limit:     2,
+		})
+		assert.NoError(t, err)
+
+		assert.Len(t, page.Items, 2)
+		assert.NotEmpty(t, page.NextCursor)
+
+		repo.EXPECT().
+			ListAccountTransactions(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, filter *transactions.TransactionsFilter) ([]*transactions.Transaction, error) {
+				assert.NotNil(t, filter.After)
+				assert.Equal(t, int64(2), filter.After.ID)
+				assert.True(t, now.Add(-time.Minute).Equal(filter.After.EventDate))
+
+				return []*transactions.Transaction{{ID: 1, AccountID: 1, EventDate: now.Add(-2 * time.Minute)}}, nil
+			})
+
+		page, err = svc.ListAccountTransactions(context.TODO(), &transactions.ListTransactionsRequest{
+			AccountID: 1,
+			Limit:     2,
+			Cursor:    page.NextCursor,
+		})
+		assert.NoError(t, err)
+
+		assert.Len(t, page.Items, 1)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("should return error if filters are invalid", func(t *testing.T) {
+		ctx := context.TODO()
+
+		_, err := svc.ListAccountTransactions(ctx, &transactions.ListTransactionsRequest{})
+		assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+
+		_, err = svc.ListAccountTransactions(ctx, &transactions.ListTransactionsRequest{AccountID: 1, Limit: 101})
+		assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+
+		_, err = svc.ListAccountTransactions(ctx, &transactions.ListTransactionsRequest{
+			AccountID: 1,
+			From:      now,
+			To:        now.Add(-time.Hour),
+		})
+		assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+
+		_, err = svc.ListAccountTransactions(ctx, &transactions.ListTransactionsRequest{
+			AccountID:       1,
+			OperationTypeID: operationtypes.Type(789),
+		})
+		assert.ErrorIs(t, err, transactions.ErrInvalidOperationTypeID(nil))
+
+		_, err = svc.ListAccountTransactions(ctx, &transactions.ListTransactionsRequest{AccountID: 1, Cursor: "abc-$"})
+		assert.ErrorIs(t, err, transactions.ErrInvalidCursor(nil))
+	})
+
+	t.Run("should return error if account is not found", func(t *testing.T) {
+		accountsSvc.EXPECT().
+			GetAccountByID(gomock.Any(), gomock.Any()).
+			Return(nil, errorlib.ErrNotFound(nil))
+
+		_, err := svc.ListAccountTransactions(context.TODO(), &transactions.ListTransactionsRequest{AccountID: 1})
+		assert.ErrorIs(t, err, errorlib.ErrNotFound(nil))
+	})
+}
+
+func TestExportAccountTransactions(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	accountsSvc := accountMocks.NewMockService(mockCtrl)
+
+	svc := transactions.NewService(
+		repo,
+		accountsSvc,
+		newOperationTypesMock(mockCtrl),
+		fxRatesMocks.NewMockService(mockCtrl),
+		newOutboxStoreMock(mockCtrl),
+	)
+
+	eventDate := time.Date(2023, 12, 5, 10, 30, 0, 0, time.UTC)
+	reversedID := int64(1)
+
+	exported := []*transactions.ExportedTransaction{
+		{
+			Transaction: &transactions.Transaction{
+				ID:              1,
+				AccountID:       1,
+				OperationTypeID: operationtypes.CashPurchaseType,
+				Amount:          money.MustParse("-52.50"),
+				Balance:         money.MustParse("-52.50"),
+				Currency:        "BRL",
+				EventDate:       eventDate,
+				FXConversion: &transactions.FXConversion{
+					Currency: "USD",
+					Amount:   money.MustParse("-10.50"),
+					Rate:     decimal.RequireFromString("5"),
+				},
+			},
+			OperationTypeDescription: "CASH PURCHASE",
+		},
+		{
+			Transaction: &transactions.Transaction{
+				ID:                    2,
+				AccountID:             1,
+				OperationTypeID:       operationtypes.PaymentType,
+				Amount:                money.MustParse("52.50"),
+				Balance:               money.MustParse("0"),
+				Currency:              "BRL",
+				EventDate:             eventDate.Add(time.Hour),
+				ReversesTransactionID: &reversedID,
+			},
+			OperationTypeDescription: "PAYMENT & REFUND",
+		},
+	}
+
+	expectStream := func(from time.Time, to time.Time) {
+		accountsSvc.EXPECT().
+			GetAccountByID(gomock.Any(), int64(1)).
+			Return(&accounts.Account{ID: 1, Currency: "BRL", CreatedAt: eventDate.AddDate(0, -1, 0)}, nil)
+
+		filter := &transactions.TransactionsFilter{AccountID: 1, From: from, To: to}
+
+		repo.EXPECT().
+			StreamAccountTransactions(gomock.Any(), filter, gomock.Any()).
+			DoAndReturn(func(
+				_ context.Context,
+				_ *transactions.TransactionsFilter,
+				fn func(*transactions.ExportedTransaction) error,
+			) error {
+				for _, transaction := range exported {
+					if err := fn(transaction); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			})
+	}
+
+	t.Run("should export the transactions as csv", func(t *testing.T) {
+		expectStream(time.Time{}, time.Time{})
+
+		output := &strings.Builder{}
+		err := svc.ExportAccountTransactions(context.TODO(), &transactions.ExportTransactionsRequest{
+			AccountID: 1,
+			Format:    transactions.ExportFormatCSV,
+		}, output)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "transaction_id,event_date,operation_type_id,operation_type,amount,balance,currency,"+
+			"original_amount,original_currency,fx_rate,reverses_transaction_id\n"+
+			"1,2023-12-05T10:30:00Z,1,CASH PURCHASE,-52.50,-52.50,BRL,-10.50,USD,5,\n"+
+			"2,2023-12-05T11:30:00Z,4,PAYMENT & REFUND,52.50,0.00,BRL,,,,1\n", output.String())
+	})
+
+	t.Run("should export the transactions as ofx", func(t *testing.T) {
+		from := eventDate.AddDate(0, 0, -1)
+		to := eventDate.AddDate(0, 0, 1)
+		expectStream(from, to)
+
+		output := &strings.Builder{}
+		err := svc.ExportAccountTransactions(context.TODO(), &transactions.ExportTransactionsRequest{
+			AccountID: 1,
+			Format:    transactions.ExportFormatOFX,
+			From:      from,
+			To:        to,
+		}, output)
+		assert.NoError(t, err)
+
+		ofx := output.String()
+		assert.Contains(t, ofx, `<?OFX OFXHEADER="200" VERSION="220"`)
+		assert.Contains(t, ofx, "<CURDEF>BRL</CURDEF><CCACCTFROM><ACCTID>1</ACCTID></CCACCTFROM>")
+		assert.Contains(t, ofx, "<DTSTART>20231204103000.000[0:GMT]</DTSTART><DTEND>20231206103000.000[0:GMT]</DTEND>")
+		assert.Contains(t, ofx, "<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20231205103000.000[0:GMT]</DTPOSTED>"+
+			"<TRNAMT>-52.50</TRNAMT><FITID>1</FITID><NAME>CASH PURCHASE</NAME></STMTTRN>")
+		assert.Contains(t, ofx, "<TRNTYPE>CREDIT</TRNTYPE>")
+		assert.Contains(t, ofx, "<NAME>PAYMENT &amp; REFUND</NAME>")
+		assert.Contains(t, ofx, "<LEDGERBAL><BALAMT>0.00</BALAMT>")
+		assert.True(t, strings.HasSuffix(ofx, "</OFX>\n"))
+	})
+
+	t.Run("should return error if the request is invalid", func(t *testing.T) {
+		for _, req := range []*transactions.ExportTransactionsRequest{
+			{AccountID: 1},
+			{AccountID: 1, Format: "pdf"},
+			{AccountID: 1, Format: transactions.ExportFormatCSV, From: eventDate, To: eventDate.Add(-time.Hour)},
+		} {
+			err := svc.ExportAccountTransactions(context.TODO(), req, &strings.Builder{})
+			assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+		}
+	})
+
+	t.Run("should return error if the account doesn't exist", func(t *testing.T) {
+		accountsSvc.EXPECT().
+			GetAccountByID(gomock.Any(), int64(2)).
+			Return(nil, errorlib.ErrNotFound(nil))
+
+		output := &strings.Builder{}
+		err := svc.ExportAccountTransactions(context.TODO(), &transactions.ExportTransactionsRequest{
+			AccountID: 2,
+			Format:    transactions.ExportFormatCSV,
+		}, output)
+		assert.ErrorIs(t, err, errorlib.ErrNotFound(nil))
+		assert.Empty(t, output.String())
+	})
+}
+
+func TestReverseTransaction(t *testing.T) {
+	setupMocks := func(
+		t *testing.T,
+		original *transactions.Transaction,
+		reversed money.Money,
+	) (transactions.Service, *mocks.MockRepository, *accountMocks.MockService) {
+		mockCtrl := gomock.NewController(t)
+		repo := mocks.NewMockRepository(mockCtrl)
+		accountsSvc := accountMocks.NewMockService(mockCtrl)
+
+		svc := transactions.NewService(
+			repo,
+			accountsSvc,
+			newOperationTypesMock(mockCtrl),
+			fxRatesMocks.NewMockService(mockCtrl),
+			newOutboxStoreMock(mockCtrl),
+		)
+
+		repo.EXPECT().
+			GetTransactionByID(gomock.Any(), original.ID).
+			DoAndReturn(func(context.Context, int64) (*transactions.Transaction, error) {
+				transaction := *original
+				return &transaction, nil
+			}).
+			Times(2)
+
+		expectRunInTx(repo)
+
+		repo.EXPECT().
+			LockAccount(gomock.Any(), original.AccountID).
+			Return(nil)
+
+		if original.ReversesTransactionID == nil {
+			repo.EXPECT().
+				GetReversedAmount(gomock.Any(), original.ID).
+				Return(reversed, nil)
+		}
+
+		return svc, repo, accountsSvc
+	}
+
+	t.Run("should fully reverse an open purchase", func(t *testing.T) {
+		original := &transactions.Transaction{
+			ID:              10,
+			AccountID:       1,
+			OperationTypeID: operationtypes.CashPurchaseType,
+			Amount:          money.MustParse("-100"),
+			Balance:         money.MustParse("-100"),
+		}
+		svc, repo, accountsSvc := setupMocks(t, original, money.NewFromInt(0))
+
+		repo.EXPECT().
+			UpdateTransactionBalance(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, transaction *transactions.Transaction) {
+				assert.Equal(t, int64(10), transaction.ID)
+				assert.Equal(t, "0.00", transaction.Balance.String())
+			}).
+			Return(nil)
+
+		repo.EXPECT().
+			CreateTransaction(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		accountsSvc.EXPECT().
+			GetAccountByID(gomock.Any(), int64(1)).
+			Return(&accounts.Account{ID: 1}, nil)
+
+		accountsSvc.EXPECT().
+			AddAvailableCreditLimit(gomock.Any(), int64(1), gomock.Any()).
+			Do(func(_ context.Context, _ int64, amount money.Money) {
+				assert.Equal(t, "100.00", amount.String())
+			}).
+			Return(nil)
+
+		reversal, err := svc.ReverseTransaction(context.TODO(), &transactions.ReverseTransactionRequest{
+			TransactionID: 10,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, int64(1), reversal.AccountID)
+		assert.Equal(t, operationtypes.CashPurchaseType, reversal.OperationTypeID)
+		assert.Equal(t, "100.00", reversal.Amount.String())
+		assert.Equal(t, "0.00", reversal.Balance.String())
+		assert.Equal(t, int64(10), *reversal.ReversesTransactionID)
+	})
+
+	t.Run("should partially reverse a settled purchase", func(t *testing.T) {
+		original := &transactions.Transaction{
+			ID:              10,
+			AccountID:       1,
+			OperationTypeID: operationtypes.CashPurchaseType,
+			Amount:          money.MustParse("-100"),
+			Balance:         money.NewFromInt(0),
+		}
+		svc, repo, accountsSvc := setupMocks(t, original, money.MustParse("30"))
+		amount := money.MustParse("50")
+
+		repo.EXPECT().
+			ListOpenTransactions(gomock.Any(), int64(1), false).
+			Return([]*transactions.Transaction{
+				newOpenTransaction(11, operationtypes.CashPurchaseType, "-20", "-20"),
+			}, nil)
+
+		repo.EXPECT().
+			UpdateTransactionBalance(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, transaction *transactions.Transaction) {
+				assert.Equal(t, int64(11), transaction.ID)
+				assert.Equal(t, "0.00", transaction.Balance.String())
+			}).
+			Return(nil)
+
+		repo.EXPECT().
+			CreateTransaction(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		accountsSvc.EXPECT().
+			GetAccountByID(gomock.Any(), int64(1)).
+			Return(&accounts.Account{ID: 1}, nil)
+
+		// only the settled purchase restores the credit limit, the rest is kept as credit
+		accountsSvc.EXPECT().
+			AddAvailableCreditLimit(gomock.Any(), int64(1), money.MustParse("20")).
+			Return(nil)
+
+		reversal, err := svc.ReverseTransaction(context.TODO(), &transactions.ReverseTransactionRequest{
+			TransactionID: 10,
+			Amount:        &amount,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "50.00", reversal.Amount.String())
+		assert.Equal(t, "30.00", reversal.Balance.String())
+	})
+
+	t.Run("should return error if amount is above the reversible amount", func(t *testing.T) {
+		original := &transactions.Transaction{
+			ID:              10,
+			AccountID:       1,
+			OperationTypeID: operationtypes.CashPurchaseType,
+			Amount:          money.MustParse("-100"),
+			Balance:         money.MustParse("-20"),
+		}
+		svc, _, _ := setupMocks(t, original, money.MustParse("80"))
+		amount := money.MustParse("20.01")
+
+		_, err := svc.ReverseTransaction(context.TODO(), &transactions.ReverseTransactionRequest{
+			TransactionID: 10,
+			Amount:        &amount,
+		})
+		assert.ErrorIs(t, err, transactions.ErrInvalidAmount(nil))
+	})
+
+	t.Run("should return error if transaction is already reversed", func(t *testing.T) {
+		original := &transactions.Transaction{
+			ID:              10,
+			AccountID:       1,
+			OperationTypeID: operationtypes.CashPurchaseType,
+			Amount:          money.MustParse("-100"),
+			Balance:         money.NewFromInt(0),
+		}
+		svc, _, _ := setupMocks(t, original, money.MustParse("100"))
+
+		_, err := svc.ReverseTransaction(context.TODO(), &transactions.ReverseTransactionRequest{
+			TransactionID: 10,
+		})
+		assert.ErrorIs(t, err, transactions.ErrTransactionAlreadyReversed(nil))
+	})
+
+	t.Run("should return error if transaction is a reversal", func(t *testing.T) {
+		reversedID := int64(9)
+		original := &transactions.Transaction{
+			ID:                    10,
+			AccountID:             1,
+			Amount:                money.MustParse("100"),
+			Balance:               money.NewFromInt(0),
+			ReversesTransactionID: &reversedID,
+		}
+		svc, _, _ := setupMocks(t, original, money.NewFromInt(0))
+
+		_, err := svc.ReverseTransaction(context.TODO(), &transactions.ReverseTransactionRequest{
+			TransactionID: 10,
+		})
+		assert.ErrorIs(t, err, transactions.ErrReversalNotReversible(nil))
+	})
+
+	t.Run("should check the credit limit when reversing a payment", func(t *testing.T) {
+		original := &transactions.Transaction{
+			ID:              10,
+			AccountID:       1,
+			OperationTypeID: operationtypes.PaymentType,
+			Amount:          money.MustParse("200"),
+			Balance:         money.NewFromInt(0),
+		}
+		svc, repo, accountsSvc := setupMocks(t, original, money.NewFromInt(0))
+
+		repo.EXPECT().
+			ListOpenTransactions(gomock.Any(), int64(1), true).
+			Return([]*transactions.Transaction{}, nil)
+
+		accountsSvc.EXPECT().
+			GetAccountByID(gomock.Any(), int64(1)).
+			Return(&accounts.Account{ID: 1, AvailableCreditLimit: money.MustParse("100")}, nil)
+
+		_, err := svc.ReverseTransaction(context.TODO(), &transactions.ReverseTransactionRequest{
+			TransactionID: 10,
+		})
+		assert.ErrorIs(t, err, transactions.ErrInsufficientCreditLimit(nil))
+	})
+
+	for status, expectedErr := range map[accounts.Status]*errorlib.Error{
+		accounts.ClosedStatus:  transactions.ErrAccountClosed(nil),
+		accounts.BlockedStatus: transactions.ErrAccountBlocked(nil),
+	} {
+		t.Run("should check the account status when reversing a payment", func(t *testing.T) {
+			original := &transactions.Transaction{
+				ID:              10,
+				AccountID:       1,
+				OperationTypeID: operationtypes.PaymentType,
+				Amount:          money.MustParse("200"),
+				Balance:         money.NewFromInt(0),
+			}
+			svc, _, accountsSvc := setupMocks(t, original, money.NewFromInt(0))
+
+			accountsSvc.EXPECT().
+				GetAccountByID(gomock.Any(), int64(1)).
+				Return(&accounts.Account{ID: 1, Status: status, AvailableCreditLimit: money.MustParse("1000")}, nil)
+
+			_, err := svc.ReverseTransaction(context.TODO(), &transactions.ReverseTransactionRequest{
+				TransactionID: 10,
+			})
+			assert.ErrorIs(t, err, expectedErr)
+		})
+	}
+
+	t.Run("should return error if transaction is not found", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		repo := mocks.NewMockRepository(mockCtrl)
+		svc := transactions.NewService(
+			repo,
+			accountMocks.NewMockService(mockCtrl),
+			newOperationTypesMock(mockCtrl),
+			fxRatesMocks.NewMockService(mockCtrl),
+			newOutboxStoreMock(mockCtrl),
+		)
+
+		repo.EXPECT().
+			GetTransactionByID(gomock.Any(), int64(10)).
+			Return(nil, errorlib.ErrNotFound(nil))
+
+		_, err := svc.ReverseTransaction(context.TODO(), &transactions.ReverseTransactionRequest{
+			TransactionID: 10,
+		})
+		assert.ErrorIs(t, err, errorlib.ErrNotFound(nil))
+	})
+
+	t.Run("should return error if amount is invalid", func(t *testing.T) {
+		svc := transactions.NewService(nil, nil, nil, nil, nil)
+
+		for _, amount := range []money.Money{money.MustParse("-10"), money.NewFromInt(0)} {
+			amount := amount
+
+			_, err := svc.ReverseTransaction(context.TODO(), &transactions.ReverseTransactionRequest{
+				TransactionID: 10,
+				Amount:        &amount,
+			})
+			assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+		}
+
+		amount := money.MustParse("1.001")
+		_, err := svc.ReverseTransaction(context.TODO(), &transactions.ReverseTransactionRequest{
+			TransactionID: 10,
+			Amount:        &amount,
+		})
+		assert.ErrorIs(t, err, transactions.ErrInvalidAmount(nil))
+	})
+}
+
+func TestImportTransactions(t *testing.T) {
+	setupMocks := func(t *testing.T) (transactions.Service, *mocks.MockRepository) {
+		mockCtrl := gomock.NewController(t)
+		repo := mocks.NewMockRepository(mockCtrl)
+		accountsSvc := accountMocks.NewMockService(mockCtrl)
+
+		svc := transactions.NewService(
+			repo,
+			accountsSvc,
+			newOperationTypesMock(mockCtrl),
+			fxRatesMocks.NewMockService(mockCtrl),
+			newOutboxStoreMock(mockCtrl),
+		)
+
+		runInTx := func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}
+
+		repo.EXPECT().RunInTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx).AnyTimes()
+		repo.EXPECT().RunInSavepoint(gomock.Any(), gomock.Any()).DoAndReturn(runInTx).AnyTimes()
+		repo.EXPECT().LockAccount(gomock.Any(), int64(1)).Return(nil).AnyTimes()
+		repo.EXPECT().
+			ListOpenTransactions(gomock.Any(), int64(1), false).
+			Return([]*transactions.Transaction{}, nil).
+			AnyTimes()
+
+		var lastID int64
+
+		repo.EXPECT().
+			CreateTransaction(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, transaction *transactions.Transaction) {
+				lastID++
+				transaction.ID = lastID
+			}).
+			Return(nil).
+			AnyTimes()
+
+		accountsSvc.EXPECT().
+			GetAccountByID(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, id int64) (*accounts.Account, error) {
+				if id != 1 {
+					return nil, errorlib.ErrNotFound(nil)
+				}
+
+				return &accounts.Account{ID: 1, Currency: "BRL", AvailableCreditLimit: money.MustParse("1000")}, nil
+			}).
+			AnyTimes()
+
+		accountsSvc.EXPECT().
+			AddAvailableCreditLimit(gomock.Any(), int64(1), gomock.Any()).
+			Return(nil).
+			AnyTimes()
+
+		return svc, repo
+	}
+
+	assertLines := func(t *testing.T, result *transactions.BatchResult, expected map[int]string) {
+		assert.Len(t, result.Lines, len(expected))
+
+		for _, line := range result.Lines {
+			if expected[line.Line] == "" {
+				assert.Nil(t, line.Err, "line %d", line.Line)
+				assert.NotNil(t, line.Transaction, "line %d", line.Line)
+			} else {
+				assert.NotNil(t, line.Err, "line %d", line.Line)
+				assert.Equal(t, expected[line.Line], line.Err.Code, "line %d", line.Line)
+				assert.Nil(t, line.Transaction, "line %d", line.Line)
+			}
+		}
+	}
+
+	t.Run("should import the ndjson lines reporting the failed ones", func(t *testing.T) {
+		svc, _ := setupMocks(t)
+
+		body := `{"account_id":1,"operation_type_id":4,"amount":"10.00"}
+{"account_id":1,"operation_type_id":4,
+{"account_id":2,"operation_type_id":4,"amount":"10.00"}
+
+{"account_id":1,"operation_type_id":4,"amount":"-10.00"}
+{"account_id":1,"operation_type_id":4,"amount":"5.50"}
+`
+
+		result, err := svc.ImportTransactions(context.TODO(), &transactions.ImportTransactionsRequest{
+			Format: transactions.BatchFormatNDJSON,
+			Body:   strings.NewReader(body),
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, 2, result.Created)
+		assert.Equal(t, 3, result.Failed)
+		assert.False(t, result.RolledBack)
+		assertLines(t, result, map[int]string{
+			1: "",
+			2: "invalid_payload",
+			3: "account_id_not_found",
+			5: "invalid_amount",
+			6: "",
+		})
+		assert.Equal(t, money.MustParse("5.50"), result.Lines[4].Transaction.Amount)
+	})
+
+	t.Run("should list the invalid fields of the ndjson lines", func(t *testing.T) {
+		svc, _ := setupMocks(t)
+
+		result, err := svc.ImportTransactions(context.TODO(), &transactions.ImportTransactionsRequest{
+			Format: transactions.BatchFormatNDJSON,
+			Body:   strings.NewReader(`{"account_id":1,"operation_type_id":4,"amount":true}`),
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, 1, result.Failed)
+		assertLines(t, result, map[int]string{1: "invalid_payload"})
+
+		resp := transactions.NewBatchAPIResponseFromEntity(result)
+		assert.Equal(t, []validation.FieldError{
+			{Field: "amount", Rule: "type", Message: "amount must be a decimal number"},
+		}, resp.Lines[0].Error.Errors)
+	})
+
+	t.Run("should import the csv lines reporting the failed ones", func(t *testing.T) {
+		svc, _ := setupMocks(t)
+
+		body := `account_id,operation_type_id,amount,installments
+1,4,10.00,
+1,4,abc,
+1,4
+1,99,10.00,
+1,4,20.00,
+`
+
+		result, err := svc.ImportTransactions(context.TODO(), &transactions.ImportTransactionsRequest{
+			Format: transactions.BatchFormatCSV,
+			Body:   strings.NewReader(body),
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, 2, result.Created)
+		assert.Equal(t, 3, result.Failed)
+		assertLines(t, result, map[int]string{
+			2: "",
+			3: "invalid_payload",
+			4: "invalid_payload",
+			5: "invalid_operation_type_id",
+			6: "",
+		})
+	})
+
+	t.Run("should roll back all the lines of an atomic batch if one fails", func(t *testing.T) {
+		svc, _ := setupMocks(t)
+
+		body := `account_id,operation_type_id,amount
+1,4,10.00
+2,4,10.00
+1,4,20.00
+`
+
+		result, err := svc.ImportTransactions(context.TODO(), &transactions.ImportTransactionsRequest{
+			Format: transactions.BatchFormatCSV,
+			Body:   strings.NewReader(body),
+			Atomic: true,
+		})
+		assert.NoError(t, err)
+
+		assert.True(t, result.RolledBack)
+		assert.Equal(t, 0, result.Created)
+		assert.Equal(t, 1, result.Failed)
+		assert.Len(t, result.Lines, 3)
+		assert.Nil(t, result.Lines[0].Transaction)
+		assert.Nil(t, result.Lines[0].Err)
+		assert.Equal(t, "account_id_not_found", result.Lines[1].Err.Code)
+		assert.Nil(t, result.Lines[2].Transaction)
+	})
+
+	t.Run("should create all the lines of an atomic batch", func(t *testing.T) {
+		svc, _ := setupMocks(t)
+
+		result, err := svc.ImportTransactions(context.TODO(), &transactions.ImportTransactionsRequest{
+			Format: transactions.BatchFormatNDJSON,
+			Body: strings.NewReader(`{"account_id":1,"operation_type_id":4,"amount":"10.00"}
+{"account_id":1,"operation_type_id":4,"amount":"20.00"}`),
+			Atomic: true,
+		})
+		assert.NoError(t, err)
+
+		assert.False(t, result.RolledBack)
+		assert.Equal(t, 2, result.Created)
+		assertLines(t, result, map[int]string{1: "", 2: ""})
+	})
+
+	t.Run("should mark the lines of a chunk that failed to commit as failed", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		repo := mocks.NewMockRepository(mockCtrl)
+
+		svc := transactions.NewService(
+			repo,
+			accountMocks.NewMockService(mockCtrl),
+			newOperationTypesMock(mockCtrl),
+			fxRatesMocks.NewMockService(mockCtrl),
+			newOutboxStoreMock(mockCtrl),
+		)
+
+		repo.EXPECT().
+			RunInTx(gomock.Any(), gomock.Any()).
+			Return(errors.New("connection reset")) //nolint:goerr113 // test error
+
+		result, err := svc.ImportTransactions(context.TODO(), &transactions.ImportTransactionsRequest{
+			Format: transactions.BatchFormatNDJSON,
+			Body:   strings.NewReader(`{"account_id":1,"operation_type_id":4,"amount":"10.00"}`),
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, 0, result.Created)
+		assert.Equal(t, 1, result.Failed)
+		assert.Equal(t, "batch_line_failed", result.Lines[0].Err.Code)
+	})
+
+	t.Run("should return error if the batch is invalid", func(t *testing.T) {
+		svc, _ := setupMocks(t)
+
+		for _, req := range []*transactions.ImportTransactionsRequest{
+			{Format: "xml", Body: strings.NewReader("<batch />")},
+			{Format: transactions.BatchFormatNDJSON, Body: strings.NewReader("\n\n")},
+			{Format: transactions.BatchFormatCSV, Body: strings.NewReader("")},
+			{Format: transactions.BatchFormatCSV, Body: strings.NewReader("account_id,amount\n1,10.00\n")},
+			{Format: transactions.BatchFormatCSV, Body: strings.NewReader("account_id,operation_type_id,amount\n")},
+		} {
+			_, err := svc.ImportTransactions(context.TODO(), req)
+			assert.ErrorIs(t, err, transactions.ErrInvalidBatch(nil))
+		}
+	})
+
+	t.Run("should return error if the batch has too many lines", func(t *testing.T) {
+		svc, _ := setupMocks(t)
+
+		body := strings.Repeat(`{"account_id":1,"operation_type_id":4,"amount":"10.00"}`+"\n", 50001)
+
+		_, err := svc.ImportTransactions(context.TODO(), &transactions.ImportTransactionsRequest{
+			Format: transactions.BatchFormatNDJSON,
+			Body:   strings.NewReader(body),
+		})
+		assert.ErrorIs(t, err, transactions.ErrBatchTooLarge(nil))
+	})
+}
+
+func expectRunInTx(repo *mocks.MockRepository) {
+	repo.EXPECT().
+		RunInTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+}
+
+func newOperationTypesMock(mockCtrl *gomock.Controller) *opTypesMocks.MockService {
+	opTypesSvc := opTypesMocks.NewMockService(mockCtrl)
+
+	opTypesSvc.EXPECT().
+		GetOperationType(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, id operationtypes.Type) (*operationtypes.OperationType, error) {
+			switch id {
+			case operationtypes.CashPurchaseType, operationtypes.InstallmentType, operationtypes.WithdrawType:
+				return &operationtypes.OperationType{ID: id, Sign: operationtypes.NegativeSign, AffectsCreditLimit: true}, nil
+			case operationtypes.PaymentType:
+				return &operationtypes.OperationType{ID: id, Sign: operationtypes.PositiveSign, AffectsCreditLimit: true}, nil
+			case operationtypes.InterestType:
+				return &operationtypes.OperationType{ID: id, Sign: operationtypes.NegativeSign, SystemGenerated: true}, nil
+			default:
+				return nil, errorlib.ErrNotFound(nil)
+			}
+		}).
+		AnyTimes()
+
+	return opTypesSvc
+}
+
+func newOutboxStoreMock(mockCtrl *gomock.Controller) *outboxMocks.MockStore {
+	events := outboxMocks.NewMockStore(mockCtrl)
+
+	events.EXPECT().
+		AddEvent(gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	return events
+}
+
+func newOpenTransaction(id int64, opType operationtypes.Type, amount string, balance string) *transactions.Transaction {
+	return &transactions.Transaction{
+		ID:              id,
+		AccountID:       1,
+		OperationTypeID: opType,
+		Amount:          money.MustParse(amount),
+		Balance:         money.MustParse(balance),
+	}
+}
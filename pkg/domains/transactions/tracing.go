@@ -0,0 +1,119 @@
+package transactions
+
+import (
+	"context"
+	"io"
+
+	"github.com/rudineirk/pismo-challenge/pkg/infra/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type tracingService struct {
+	next Service
+}
+
+// NewTracingService wraps the service, creating a span for each call.
+func NewTracingService(service Service) Service {
+	return &tracingService{next: service}
+}
+
+func (svc *tracingService) CreateTransaction(
+	ctx context.Context,
+	req *CreateTransactionRequest,
+) (*Transaction, error) {
+	ctx, span := tracing.StartSpan(ctx, "transactions.CreateTransaction", attribute.Int64("account.id", req.AccountID))
+	transaction, err := svc.next.CreateTransaction(ctx, req)
+	tracing.EndSpan(span, err)
+
+	return transaction, err
+}
+
+func (svc *tracingService) CreateSystemTransaction(
+	ctx context.Context,
+	req *CreateTransactionRequest,
+) (*Transaction, error) {
+	ctx, span := tracing.StartSpan(
+		ctx,
+		"transactions.CreateSystemTransaction",
+		attribute.Int64("account.id", req.AccountID),
+	)
+	transaction, err := svc.next.CreateSystemTransaction(ctx, req)
+	tracing.EndSpan(span, err)
+
+	return transaction, err
+}
+
+func (svc *tracingService) ListAccountTransactions(
+	ctx context.Context,
+	req *ListTransactionsRequest,
+) (*TransactionsPage, error) {
+	ctx, span := tracing.StartSpan(
+		ctx,
+		"transactions.ListAccountTransactions",
+		attribute.Int64("account.id", req.AccountID),
+	)
+	page, err := svc.next.ListAccountTransactions(ctx, req)
+	tracing.EndSpan(span, err)
+
+	return page, err
+}
+
+func (svc *tracingService) ExportAccountTransactions(
+	ctx context.Context,
+	req *ExportTransactionsRequest,
+	output io.Writer,
+) error {
+	ctx, span := tracing.StartSpan(
+		ctx,
+		"transactions.ExportAccountTransactions",
+		attribute.Int64("account.id", req.AccountID),
+		attribute.String("export.format", string(req.Format)),
+	)
+	err := svc.next.ExportAccountTransactions(ctx, req, output)
+	tracing.EndSpan(span, err)
+
+	return err
+}
+
+func (svc *tracingService) ReverseTransaction(
+	ctx context.Context,
+	req *ReverseTransactionRequest,
+) (*Transaction, error) {
+	ctx, span := tracing.StartSpan(
+		ctx,
+		"transactions.ReverseTransaction",
+		attribute.Int64("transaction.id", req.TransactionID),
+	)
+	transaction, err := svc.next.ReverseTransaction(ctx, req)
+	tracing.EndSpan(span, err)
+
+	return transaction, err
+}
+
+func (svc *tracingService) GetInstallmentPlan(ctx context.Context, transactionID int64) (*InstallmentPlan, error) {
+	ctx, span := tracing.StartSpan(
+		ctx,
+		"transactions.GetInstallmentPlan",
+		attribute.Int64("transaction.id", transactionID),
+	)
+	plan, err := svc.next.GetInstallmentPlan(ctx, transactionID)
+	tracing.EndSpan(span, err)
+
+	return plan, err
+}
+
+func (svc *tracingService) ImportTransactions(
+	ctx context.Context,
+	req *ImportTransactionsRequest,
+) (*BatchResult, error) {
+	ctx, span := tracing.StartSpan(
+		ctx,
+		"transactions.ImportTransactions",
+		attribute.String("batch.format", string(req.Format)),
+		attribute.Bool("batch.atomic", req.Atomic),
+	)
+	result, err := svc.next.ImportTransactions(ctx, req)
+	tracing.EndSpan(span, err)
+
+	return result, err
+}
@@ -0,0 +1,240 @@
+package webhooks
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/httprouter"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+)
+
+type httpHandler struct {
+	service Service
+}
+
+func SetupHTTPRoutes(router *gin.Engine, service Service, middlewares ...gin.HandlerFunc) {
+	handler := httpHandler{
+		service: service,
+	}
+
+	routeGroup := router.Group("/webhooks", middlewares...)
+	routeGroup.POST("", handler.CreateSubscription)
+	routeGroup.GET("", handler.ListSubscriptions)
+	routeGroup.GET("/:webhook_id", handler.GetSubscription)
+	routeGroup.DELETE("/:webhook_id", handler.DeleteSubscription)
+	routeGroup.GET("/:webhook_id/deliveries", handler.ListDeliveries)
+	routeGroup.POST("/:webhook_id/deliveries/:delivery_id/redeliver", handler.Redeliver)
+}
+
+func (handler *httpHandler) CreateSubscription(ctx *gin.Context) {
+	req := CreateSubscriptionRequest{}
+	if err := httprouter.BindJSON(ctx, &req); err != nil {
+		return
+	}
+
+	subscription, err := handler.service.CreateSubscription(ctx, &req)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	resp := NewSubscriptionAPIResponseFromEntity(subscription)
+	resp.Secret = subscription.Secret
+
+	ctx.JSON(http.StatusCreated, resp)
+}
+
+func (handler *httpHandler) ListSubscriptions(ctx *gin.Context) {
+	subscriptions, err := handler.service.ListSubscriptions(ctx)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	resp := make([]*SubscriptionAPIResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		resp = append(resp, NewSubscriptionAPIResponseFromEntity(subscription))
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (handler *httpHandler) GetSubscription(ctx *gin.Context) {
+	subscriptionID, err := strconv.ParseInt(ctx.Param("webhook_id"), 10, 64)
+	if err != nil {
+		httprouter.AbortWithError(ctx, errorlib.ErrNotFound(err))
+		return
+	}
+
+	subscription, err := handler.service.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, NewSubscriptionAPIResponseFromEntity(subscription))
+}
+
+func (handler *httpHandler) DeleteSubscription(ctx *gin.Context) {
+	subscriptionID, err := strconv.ParseInt(ctx.Param("webhook_id"), 10, 64)
+	if err != nil {
+		httprouter.AbortWithError(ctx, errorlib.ErrNotFound(err))
+		return
+	}
+
+	if err := handler.service.DeleteSubscription(ctx, subscriptionID); err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (handler *httpHandler) ListDeliveries(ctx *gin.Context) {
+	subscriptionID, err := strconv.ParseInt(ctx.Param("webhook_id"), 10, 64)
+	if err != nil {
+		httprouter.AbortWithError(ctx, errorlib.ErrNotFound(err))
+		return
+	}
+
+	req := ListDeliveriesRequest{}
+	if err := httprouter.BindQuery(ctx, &req); err != nil {
+		return
+	}
+
+	req.SubscriptionID = subscriptionID
+
+	page, err := handler.service.ListDeliveries(ctx, &req)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, NewDeliveriesPageAPIResponseFromEntity(page))
+}
+
+func (handler *httpHandler) Redeliver(ctx *gin.Context) {
+	subscriptionID, err := strconv.ParseInt(ctx.Param("webhook_id"), 10, 64)
+	if err != nil {
+		httprouter.AbortWithError(ctx, errorlib.ErrNotFound(err))
+		return
+	}
+
+	deliveryID, err := strconv.ParseInt(ctx.Param("delivery_id"), 10, 64)
+	if err != nil {
+		httprouter.AbortWithError(ctx, errorlib.ErrNotFound(err))
+		return
+	}
+
+	delivery, err := handler.service.Redeliver(ctx, subscriptionID, deliveryID)
+	if err != nil {
+		httprouter.AbortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, NewDeliveryAPIResponseFromEntity(delivery))
+}
+
+type SubscriptionAPIResponse struct {
+	WebhookID  int64     `json:"webhook_id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	AccountID  *int64    `json:"account_id"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NewSubscriptionAPIResponseFromEntity omits the secret, it's only returned when the subscription is created.
+func NewSubscriptionAPIResponseFromEntity(subscription *Subscription) *SubscriptionAPIResponse {
+	return &SubscriptionAPIResponse{
+		WebhookID:  subscription.ID,
+		URL:        subscription.URL,
+		EventTypes: subscription.EventTypes,
+		AccountID:  subscription.AccountID,
+		CreatedAt:  subscription.CreatedAt,
+	}
+}
+
+type DeliveryAPIResponse struct {
+	DeliveryID     int64                         `json:"delivery_id"`
+	WebhookID      int64                         `json:"webhook_id"`
+	EventID        int64                         `json:"event_id"`
+	EventType      string                        `json:"event_type"`
+	Status         DeliveryStatus                `json:"status"`
+	Attempts       int                           `json:"attempts"`
+	NextAttemptAt  *time.Time                    `json:"next_attempt_at"`
+	LastStatusCode *int                          `json:"last_status_code"`
+	LastError      *string                       `json:"last_error"`
+	DeliveredAt    *time.Time                    `json:"delivered_at"`
+	CreatedAt      time.Time                     `json:"created_at"`
+	AttemptLog     []*DeliveryAttemptAPIResponse `json:"attempt_log"`
+}
+
+type DeliveryAttemptAPIResponse struct {
+	StatusCode  *int      `json:"status_code"`
+	Error       *string   `json:"error"`
+	DurationMs  int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+func NewDeliveryAPIResponseFromEntity(delivery *Delivery) *DeliveryAPIResponse {
+	resp := &DeliveryAPIResponse{
+		DeliveryID:     delivery.ID,
+		WebhookID:      delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+		AttemptLog:     make([]*DeliveryAttemptAPIResponse, 0, len(delivery.AttemptLog)),
+	}
+
+	if delivery.Status == PendingStatus || delivery.Status == RetryingStatus {
+		resp.NextAttemptAt = &delivery.NextAttemptAt
+	}
+
+	if delivery.LastError != "" {
+		resp.LastError = &delivery.LastError
+	}
+
+	for _, attempt := range delivery.AttemptLog {
+		attemptResp := &DeliveryAttemptAPIResponse{
+			StatusCode:  attempt.StatusCode,
+			DurationMs:  attempt.Duration.Milliseconds(),
+			AttemptedAt: attempt.AttemptedAt,
+		}
+
+		if attempt.Error != "" {
+			attemptResp.Error = &attempt.Error
+		}
+
+		resp.AttemptLog = append(resp.AttemptLog, attemptResp)
+	}
+
+	return resp
+}
+
+type DeliveriesPageAPIResponse struct {
+	Items      []*DeliveryAPIResponse `json:"items"`
+	NextCursor *string                `json:"next_cursor"`
+}
+
+func NewDeliveriesPageAPIResponseFromEntity(page *DeliveriesPage) *DeliveriesPageAPIResponse {
+	resp := &DeliveriesPageAPIResponse{
+		Items: make([]*DeliveryAPIResponse, 0, len(page.Items)),
+	}
+
+	for _, delivery := range page.Items {
+		resp.Items = append(resp.Items, NewDeliveryAPIResponseFromEntity(delivery))
+	}
+
+	if page.NextCursor != "" {
+		resp.NextCursor = &page.NextCursor
+	}
+
+	return resp
+}
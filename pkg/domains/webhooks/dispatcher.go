@@ -0,0 +1,45 @@
+package webhooks
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Dispatcher sends the due webhook deliveries until the context is canceled.
+type Dispatcher struct {
+	service  Service
+	logger   *zerolog.Logger
+	interval time.Duration
+}
+
+func NewDispatcher(service Service, logger *zerolog.Logger, interval time.Duration) *Dispatcher {
+	return &Dispatcher{
+		service:  service,
+		logger:   logger,
+		interval: interval,
+	}
+}
+
+func (dispatcher *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(dispatcher.interval)
+	defer ticker.Stop()
+
+	for {
+		attempted, err := dispatcher.service.DeliverPending(ctx)
+		if err != nil && ctx.Err() == nil {
+			dispatcher.logger.Err(err).Msg("Failed to send webhook deliveries")
+		}
+
+		if attempted == dueDeliveriesBatchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type DeliveryStatus string
+
+const (
+	PendingStatus   DeliveryStatus = "pending"
+	RetryingStatus  DeliveryStatus = "retrying"
+	DeliveredStatus DeliveryStatus = "delivered"
+	DeadStatus      DeliveryStatus = "dead"
+)
+
+type Subscription struct {
+	ID         int64
+	URL        string
+	Secret     string
+	EventTypes []string
+	AccountID  *int64
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Delivery is a single event sent to a subscription, it is retried until it's
+// delivered or the max attempts are reached, moving it to the dead status.
+type Delivery struct {
+	ID             int64
+	SubscriptionID int64
+	EventID        int64
+	EventType      string
+	Payload        json.RawMessage
+	Status         DeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode *int
+	LastError      string
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	Subscription *Subscription
+	AttemptLog   []*DeliveryAttempt
+}
+
+type DeliveryAttempt struct {
+	ID          int64
+	DeliveryID  int64
+	StatusCode  *int
+	Error       string
+	Duration    time.Duration
+	AttemptedAt time.Time
+}
+
+type DeliveriesCursor struct {
+	ID int64 `json:"i"`
+}
+
+type DeliveriesFilter struct {
+	SubscriptionID int64
+	Status         DeliveryStatus
+	After          *DeliveriesCursor
+	Limit          int
+}
+
+type DeliveriesPage struct {
+	Items      []*Delivery
+	NextCursor string
+}
+
+// RetryPolicy defines the exponential backoff of the failed deliveries.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+func (policy RetryPolicy) Backoff(attempts int) time.Duration {
+	backoff := policy.BaseBackoff
+	for i := 1; i < attempts && backoff < policy.MaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > policy.MaxBackoff {
+		return policy.MaxBackoff
+	}
+
+	return backoff
+}
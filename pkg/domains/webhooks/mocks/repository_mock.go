@@ -0,0 +1,229 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/domains/webhooks/repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source ./pkg/domains/webhooks/repository.go -destination ./pkg/domains/webhooks/mocks/repository_mock.go
+//
+// Package mock_webhooks is a generated GoMock package.
+package mock_webhooks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	webhooks "github.com/rudineirk/pismo-challenge/pkg/domains/webhooks"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateDeliveries mocks base method.
+func (m *MockRepository) CreateDeliveries(arg0 context.Context, arg1 []*webhooks.Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeliveries", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDeliveries indicates an expected call of CreateDeliveries.
+func (mr *MockRepositoryMockRecorder) CreateDeliveries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeliveries", reflect.TypeOf((*MockRepository)(nil).CreateDeliveries), arg0, arg1)
+}
+
+// CreateDeliveryAttempt mocks base method.
+func (m *MockRepository) CreateDeliveryAttempt(arg0 context.Context, arg1 *webhooks.DeliveryAttempt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeliveryAttempt", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDeliveryAttempt indicates an expected call of CreateDeliveryAttempt.
+func (mr *MockRepositoryMockRecorder) CreateDeliveryAttempt(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeliveryAttempt", reflect.TypeOf((*MockRepository)(nil).CreateDeliveryAttempt), arg0, arg1)
+}
+
+// CreateSubscription mocks base method.
+func (m *MockRepository) CreateSubscription(arg0 context.Context, arg1 *webhooks.Subscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscription", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSubscription indicates an expected call of CreateSubscription.
+func (mr *MockRepositoryMockRecorder) CreateSubscription(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockRepository)(nil).CreateSubscription), arg0, arg1)
+}
+
+// DeleteSubscription mocks base method.
+func (m *MockRepository) DeleteSubscription(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubscription", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSubscription indicates an expected call of DeleteSubscription.
+func (mr *MockRepositoryMockRecorder) DeleteSubscription(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscription", reflect.TypeOf((*MockRepository)(nil).DeleteSubscription), arg0, arg1)
+}
+
+// GetDeliveryByID mocks base method.
+func (m *MockRepository) GetDeliveryByID(arg0 context.Context, arg1 int64) (*webhooks.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveryByID", arg0, arg1)
+	ret0, _ := ret[0].(*webhooks.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveryByID indicates an expected call of GetDeliveryByID.
+func (mr *MockRepositoryMockRecorder) GetDeliveryByID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveryByID", reflect.TypeOf((*MockRepository)(nil).GetDeliveryByID), arg0, arg1)
+}
+
+// GetSubscriptionByID mocks base method.
+func (m *MockRepository) GetSubscriptionByID(arg0 context.Context, arg1 int64) (*webhooks.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptionByID", arg0, arg1)
+	ret0, _ := ret[0].(*webhooks.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptionByID indicates an expected call of GetSubscriptionByID.
+func (mr *MockRepositoryMockRecorder) GetSubscriptionByID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionByID", reflect.TypeOf((*MockRepository)(nil).GetSubscriptionByID), arg0, arg1)
+}
+
+// LeaseDeliveries mocks base method.
+func (m *MockRepository) LeaseDeliveries(ctx context.Context, ids []int64, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LeaseDeliveries", ctx, ids, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LeaseDeliveries indicates an expected call of LeaseDeliveries.
+func (mr *MockRepositoryMockRecorder) LeaseDeliveries(ctx, ids, until any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeaseDeliveries", reflect.TypeOf((*MockRepository)(nil).LeaseDeliveries), ctx, ids, until)
+}
+
+// ListDeliveries mocks base method.
+func (m *MockRepository) ListDeliveries(arg0 context.Context, arg1 *webhooks.DeliveriesFilter) ([]*webhooks.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]*webhooks.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeliveries indicates an expected call of ListDeliveries.
+func (mr *MockRepositoryMockRecorder) ListDeliveries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeliveries", reflect.TypeOf((*MockRepository)(nil).ListDeliveries), arg0, arg1)
+}
+
+// ListDueDeliveries mocks base method.
+func (m *MockRepository) ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*webhooks.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDueDeliveries", ctx, now, limit)
+	ret0, _ := ret[0].([]*webhooks.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDueDeliveries indicates an expected call of ListDueDeliveries.
+func (mr *MockRepositoryMockRecorder) ListDueDeliveries(ctx, now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDueDeliveries", reflect.TypeOf((*MockRepository)(nil).ListDueDeliveries), ctx, now, limit)
+}
+
+// ListSubscriptions mocks base method.
+func (m *MockRepository) ListSubscriptions(arg0 context.Context) ([]*webhooks.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscriptions", arg0)
+	ret0, _ := ret[0].([]*webhooks.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubscriptions indicates an expected call of ListSubscriptions.
+func (mr *MockRepositoryMockRecorder) ListSubscriptions(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptions", reflect.TypeOf((*MockRepository)(nil).ListSubscriptions), arg0)
+}
+
+// ListSubscriptionsForEvent mocks base method.
+func (m *MockRepository) ListSubscriptionsForEvent(ctx context.Context, eventType string, accountID int64) ([]*webhooks.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscriptionsForEvent", ctx, eventType, accountID)
+	ret0, _ := ret[0].([]*webhooks.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubscriptionsForEvent indicates an expected call of ListSubscriptionsForEvent.
+func (mr *MockRepositoryMockRecorder) ListSubscriptionsForEvent(ctx, eventType, accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptionsForEvent", reflect.TypeOf((*MockRepository)(nil).ListSubscriptionsForEvent), ctx, eventType, accountID)
+}
+
+// RunInTx mocks base method.
+func (m *MockRepository) RunInTx(arg0 context.Context, arg1 func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunInTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunInTx indicates an expected call of RunInTx.
+func (mr *MockRepositoryMockRecorder) RunInTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunInTx", reflect.TypeOf((*MockRepository)(nil).RunInTx), arg0, arg1)
+}
+
+// UpdateDelivery mocks base method.
+func (m *MockRepository) UpdateDelivery(arg0 context.Context, arg1 *webhooks.Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDelivery", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery.
+func (mr *MockRepositoryMockRecorder) UpdateDelivery(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockRepository)(nil).UpdateDelivery), arg0, arg1)
+}
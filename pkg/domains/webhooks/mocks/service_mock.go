@@ -0,0 +1,159 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/domains/webhooks/service.go
+//
+// Generated by this command:
+//
+//	mockgen -source ./pkg/domains/webhooks/service.go -destination ./pkg/domains/webhooks/mocks/service_mock.go
+//
+// Package mock_webhooks is a generated GoMock package.
+package mock_webhooks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	webhooks "github.com/rudineirk/pismo-challenge/pkg/domains/webhooks"
+	outbox "github.com/rudineirk/pismo-challenge/pkg/infra/outbox"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateSubscription mocks base method.
+func (m *MockService) CreateSubscription(arg0 context.Context, arg1 *webhooks.CreateSubscriptionRequest) (*webhooks.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscription", arg0, arg1)
+	ret0, _ := ret[0].(*webhooks.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubscription indicates an expected call of CreateSubscription.
+func (mr *MockServiceMockRecorder) CreateSubscription(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockService)(nil).CreateSubscription), arg0, arg1)
+}
+
+// DeleteSubscription mocks base method.
+func (m *MockService) DeleteSubscription(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubscription", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSubscription indicates an expected call of DeleteSubscription.
+func (mr *MockServiceMockRecorder) DeleteSubscription(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscription", reflect.TypeOf((*MockService)(nil).DeleteSubscription), arg0, arg1)
+}
+
+// DeliverPending mocks base method.
+func (m *MockService) DeliverPending(arg0 context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeliverPending", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeliverPending indicates an expected call of DeliverPending.
+func (mr *MockServiceMockRecorder) DeliverPending(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeliverPending", reflect.TypeOf((*MockService)(nil).DeliverPending), arg0)
+}
+
+// EnqueueDeliveries mocks base method.
+func (m *MockService) EnqueueDeliveries(arg0 context.Context, arg1 *outbox.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueDeliveries", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnqueueDeliveries indicates an expected call of EnqueueDeliveries.
+func (mr *MockServiceMockRecorder) EnqueueDeliveries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueDeliveries", reflect.TypeOf((*MockService)(nil).EnqueueDeliveries), arg0, arg1)
+}
+
+// GetSubscription mocks base method.
+func (m *MockService) GetSubscription(arg0 context.Context, arg1 int64) (*webhooks.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscription", arg0, arg1)
+	ret0, _ := ret[0].(*webhooks.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscription indicates an expected call of GetSubscription.
+func (mr *MockServiceMockRecorder) GetSubscription(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscription", reflect.TypeOf((*MockService)(nil).GetSubscription), arg0, arg1)
+}
+
+// ListDeliveries mocks base method.
+func (m *MockService) ListDeliveries(arg0 context.Context, arg1 *webhooks.ListDeliveriesRequest) (*webhooks.DeliveriesPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeliveries", arg0, arg1)
+	ret0, _ := ret[0].(*webhooks.DeliveriesPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeliveries indicates an expected call of ListDeliveries.
+func (mr *MockServiceMockRecorder) ListDeliveries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeliveries", reflect.TypeOf((*MockService)(nil).ListDeliveries), arg0, arg1)
+}
+
+// ListSubscriptions mocks base method.
+func (m *MockService) ListSubscriptions(arg0 context.Context) ([]*webhooks.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscriptions", arg0)
+	ret0, _ := ret[0].([]*webhooks.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubscriptions indicates an expected call of ListSubscriptions.
+func (mr *MockServiceMockRecorder) ListSubscriptions(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptions", reflect.TypeOf((*MockService)(nil).ListSubscriptions), arg0)
+}
+
+// Redeliver mocks base method.
+func (m *MockService) Redeliver(ctx context.Context, subscriptionID, deliveryID int64) (*webhooks.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeliver", ctx, subscriptionID, deliveryID)
+	ret0, _ := ret[0].(*webhooks.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Redeliver indicates an expected call of Redeliver.
+func (mr *MockServiceMockRecorder) Redeliver(ctx, subscriptionID, deliveryID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeliver", reflect.TypeOf((*MockService)(nil).Redeliver), ctx, subscriptionID, deliveryID)
+}
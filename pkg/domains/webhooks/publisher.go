@@ -0,0 +1,26 @@
+package webhooks
+
+import (
+	"context"
+
+	"github.com/rudineirk/pismo-challenge/pkg/infra/outbox"
+)
+
+// eventPublisher receives the outbox events and enqueues their webhook deliveries. The relay
+// publishes an event again when it fails to mark it as published, the deliveries of the
+// re-published event are ignored by the ON CONFLICT (subscription_id, event_id) clause.
+type eventPublisher struct {
+	service Service
+}
+
+func NewEventPublisher(service Service) outbox.Publisher {
+	return &eventPublisher{service}
+}
+
+func (publisher *eventPublisher) Publish(ctx context.Context, event *outbox.Event) error {
+	return publisher.service.EnqueueDeliveries(ctx, event)
+}
+
+func (publisher *eventPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,420 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/rudineirk/pismo-challenge/pkg/infra/database"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/uptrace/bun"
+)
+
+type Repository interface {
+	RunInTx(context.Context, func(context.Context) error) error
+	CreateSubscription(context.Context, *Subscription) error
+	GetSubscriptionByID(context.Context, int64) (*Subscription, error)
+	ListSubscriptions(context.Context) ([]*Subscription, error)
+	ListSubscriptionsForEvent(ctx context.Context, eventType string, accountID int64) ([]*Subscription, error)
+	DeleteSubscription(context.Context, int64) error
+	CreateDeliveries(context.Context, []*Delivery) error
+	GetDeliveryByID(context.Context, int64) (*Delivery, error)
+	ListDeliveries(context.Context, *DeliveriesFilter) ([]*Delivery, error)
+	ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*Delivery, error)
+	LeaseDeliveries(ctx context.Context, ids []int64, until time.Time) error
+	UpdateDelivery(context.Context, *Delivery) error
+	CreateDeliveryAttempt(context.Context, *DeliveryAttempt) error
+}
+
+type SubscriptionModel struct {
+	bun.BaseModel `bun:"table:webhook_subscriptions,alias:subscription"`
+	ID            int64     `bun:"id,pk,autoincrement"`
+	URL           string    `bun:"url"`
+	Secret        string    `bun:"secret"`
+	EventTypes    []string  `bun:"event_types,array"`
+	AccountID     *int64    `bun:"account_id"`
+	CreatedAt     time.Time `bun:"created_at"`
+	UpdatedAt     time.Time `bun:"updated_at"`
+}
+
+func NewSubscriptionModelFromEntity(subscription *Subscription) *SubscriptionModel {
+	return &SubscriptionModel{
+		ID:         subscription.ID,
+		URL:        subscription.URL,
+		Secret:     subscription.Secret,
+		EventTypes: subscription.EventTypes,
+		AccountID:  subscription.AccountID,
+		CreatedAt:  subscription.CreatedAt,
+		UpdatedAt:  subscription.UpdatedAt,
+	}
+}
+
+func (model *SubscriptionModel) ToEntity() *Subscription {
+	return &Subscription{
+		ID:         model.ID,
+		URL:        model.URL,
+		Secret:     model.Secret,
+		EventTypes: model.EventTypes,
+		AccountID:  model.AccountID,
+		CreatedAt:  model.CreatedAt,
+		UpdatedAt:  model.UpdatedAt,
+	}
+}
+
+type DeliveryModel struct {
+	bun.BaseModel  `bun:"table:webhook_deliveries,alias:delivery"`
+	ID             int64                   `bun:"id,pk,autoincrement"`
+	SubscriptionID int64                   `bun:"subscription_id"`
+	EventID        int64                   `bun:"event_id"`
+	EventType      string                  `bun:"event_type"`
+	Payload        json.RawMessage         `bun:"payload,type:jsonb"`
+	Status         DeliveryStatus          `bun:"status"`
+	Attempts       int                     `bun:"attempts"`
+	NextAttemptAt  time.Time               `bun:"next_attempt_at"`
+	LastStatusCode *int                    `bun:"last_status_code"`
+	LastError      string                  `bun:"last_error,nullzero"`
+	DeliveredAt    *time.Time              `bun:"delivered_at"`
+	CreatedAt      time.Time               `bun:"created_at"`
+	UpdatedAt      time.Time               `bun:"updated_at"`
+	Subscription   *SubscriptionModel      `bun:"rel:belongs-to,join:subscription_id=id"`
+	AttemptLog     []*DeliveryAttemptModel `bun:"rel:has-many,join:id=delivery_id"`
+}
+
+func NewDeliveryModelFromEntity(delivery *Delivery) *DeliveryModel {
+	return &DeliveryModel{
+		ID:             delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+		UpdatedAt:      delivery.UpdatedAt,
+	}
+}
+
+func (model *DeliveryModel) ToEntity() *Delivery {
+	delivery := &Delivery{
+		ID:             model.ID,
+		SubscriptionID: model.SubscriptionID,
+		EventID:        model.EventID,
+		EventType:      model.EventType,
+		Payload:        model.Payload,
+		Status:         model.Status,
+		Attempts:       model.Attempts,
+		NextAttemptAt:  model.NextAttemptAt,
+		LastStatusCode: model.LastStatusCode,
+		LastError:      model.LastError,
+		DeliveredAt:    model.DeliveredAt,
+		CreatedAt:      model.CreatedAt,
+		UpdatedAt:      model.UpdatedAt,
+	}
+
+	if model.Subscription != nil {
+		delivery.Subscription = model.Subscription.ToEntity()
+	}
+
+	if model.AttemptLog != nil {
+		delivery.AttemptLog = make([]*DeliveryAttempt, 0, len(model.AttemptLog))
+		for _, attempt := range model.AttemptLog {
+			delivery.AttemptLog = append(delivery.AttemptLog, attempt.ToEntity())
+		}
+	}
+
+	return delivery
+}
+
+type DeliveryAttemptModel struct {
+	bun.BaseModel `bun:"table:webhook_delivery_attempts,alias:attempt"`
+	ID            int64     `bun:"id,pk,autoincrement"`
+	DeliveryID    int64     `bun:"delivery_id"`
+	StatusCode    *int      `bun:"status_code"`
+	Error         string    `bun:"error,nullzero"`
+	DurationMs    int64     `bun:"duration_ms"`
+	AttemptedAt   time.Time `bun:"attempted_at"`
+}
+
+func (model *DeliveryAttemptModel) ToEntity() *DeliveryAttempt {
+	return &DeliveryAttempt{
+		ID:          model.ID,
+		DeliveryID:  model.DeliveryID,
+		StatusCode:  model.StatusCode,
+		Error:       model.Error,
+		Duration:    time.Duration(model.DurationMs) * time.Millisecond,
+		AttemptedAt: model.AttemptedAt,
+	}
+}
+
+type dbRepository struct {
+	bunDB *bun.DB
+}
+
+func NewRepository(bunDB *bun.DB) Repository {
+	return &dbRepository{bunDB}
+}
+
+func (repo *dbRepository) RunInTx(ctx context.Context, fn func(context.Context) error) error {
+	return database.RunInTx(ctx, repo.bunDB, fn)
+}
+
+func (repo *dbRepository) CreateSubscription(ctx context.Context, subscription *Subscription) error {
+	subscriptionModel := NewSubscriptionModelFromEntity(subscription)
+
+	_, err := database.Conn(ctx, repo.bunDB).NewInsert().
+		Model(subscriptionModel).
+		Exec(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	subscription.ID = subscriptionModel.ID
+
+	return nil
+}
+
+func (repo *dbRepository) GetSubscriptionByID(ctx context.Context, id int64) (*Subscription, error) {
+	subscriptionModel := SubscriptionModel{}
+
+	err := database.Conn(ctx, repo.bunDB).NewSelect().
+		Model(&subscriptionModel).
+		Where("id = ?", id).
+		Scan(ctx)
+
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return nil, errorlib.ErrNotFound(err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return subscriptionModel.ToEntity(), nil
+}
+
+func (repo *dbRepository) ListSubscriptions(ctx context.Context) ([]*Subscription, error) {
+	return repo.listSubscriptions(ctx, func(query *bun.SelectQuery) *bun.SelectQuery {
+		return query
+	})
+}
+
+func (repo *dbRepository) ListSubscriptionsForEvent(
+	ctx context.Context,
+	eventType string,
+	accountID int64,
+) ([]*Subscription, error) {
+	return repo.listSubscriptions(ctx, func(query *bun.SelectQuery) *bun.SelectQuery {
+		return query.
+			Where("? = ANY(event_types)", eventType).
+			Where("(account_id IS NULL OR account_id = ?)", accountID)
+	})
+}
+
+func (repo *dbRepository) listSubscriptions(
+	ctx context.Context,
+	filter func(*bun.SelectQuery) *bun.SelectQuery,
+) ([]*Subscription, error) {
+	subscriptionModels := []*SubscriptionModel{}
+
+	query := database.Conn(ctx, repo.bunDB).NewSelect().
+		Model(&subscriptionModels).
+		Order("id")
+
+	if err := filter(query).Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	subscriptions := make([]*Subscription, 0, len(subscriptionModels))
+	for _, model := range subscriptionModels {
+		subscriptions = append(subscriptions, model.ToEntity())
+	}
+
+	return subscriptions, nil
+}
+
+func (repo *dbRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	result, err := database.Conn(ctx, repo.bunDB).NewDelete().
+		Model((*SubscriptionModel)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	} else if rows == 0 {
+		return errorlib.ErrNotFound(nil)
+	}
+
+	return nil
+}
+
+// CreateDeliveries ignores the deliveries already created for the same event,
+// as the outbox events can be published more than once.
+func (repo *dbRepository) CreateDeliveries(ctx context.Context, deliveries []*Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	deliveryModels := make([]*DeliveryModel, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		deliveryModels = append(deliveryModels, NewDeliveryModelFromEntity(delivery))
+	}
+
+	_, err := database.Conn(ctx, repo.bunDB).NewInsert().
+		Model(&deliveryModels).
+		On("CONFLICT (subscription_id, event_id) DO NOTHING").
+		Exec(ctx)
+
+	return err
+}
+
+func (repo *dbRepository) GetDeliveryByID(ctx context.Context, id int64) (*Delivery, error) {
+	deliveryModel := DeliveryModel{}
+
+	err := database.Conn(ctx, repo.bunDB).NewSelect().
+		Model(&deliveryModel).
+		Relation("AttemptLog", func(query *bun.SelectQuery) *bun.SelectQuery {
+			return query.Order("id")
+		}).
+		Where("delivery.id = ?", id).
+		Scan(ctx)
+
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return nil, errorlib.ErrNotFound(err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return deliveryModel.ToEntity(), nil
+}
+
+func (repo *dbRepository) ListDeliveries(ctx context.Context, filter *DeliveriesFilter) ([]*Delivery, error) {
+	deliveryModels := []*DeliveryModel{}
+
+	query := database.Conn(ctx, repo.bunDB).NewSelect().
+		Model(&deliveryModels).
+		Relation("AttemptLog", func(query *bun.SelectQuery) *bun.SelectQuery {
+			return query.Order("id")
+		}).
+		Where("delivery.subscription_id = ?", filter.SubscriptionID).
+		Order("delivery.id DESC").
+		Limit(filter.Limit)
+
+	if filter.Status != "" {
+		query = query.Where("delivery.status = ?", filter.Status)
+	}
+
+	if filter.After != nil {
+		query = query.Where("delivery.id < ?", filter.After.ID)
+	}
+
+	if err := query.Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]*Delivery, 0, len(deliveryModels))
+	for _, model := range deliveryModels {
+		deliveries = append(deliveries, model.ToEntity())
+	}
+
+	return deliveries, nil
+}
+
+// ListDueDeliveries locks the returned deliveries, skipping the ones locked by other
+// dispatchers, it must be called inside RunInTx.
+func (repo *dbRepository) ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*Delivery, error) {
+	deliveryModels := []*DeliveryModel{}
+
+	err := database.Conn(ctx, repo.bunDB).NewSelect().
+		Model(&deliveryModels).
+		Relation("Subscription").
+		Where("delivery.status IN (?)", bun.In([]DeliveryStatus{PendingStatus, RetryingStatus})).
+		Where("delivery.next_attempt_at <= ?", now).
+		Order("delivery.next_attempt_at", "delivery.id").
+		Limit(limit).
+		For("UPDATE OF delivery SKIP LOCKED").
+		Scan(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]*Delivery, 0, len(deliveryModels))
+	for _, model := range deliveryModels {
+		deliveries = append(deliveries, model.ToEntity())
+	}
+
+	return deliveries, nil
+}
+
+// LeaseDeliveries moves the next attempt of the deliveries to the end of the lease, so they
+// aren't listed as due again while they are sent.
+func (repo *dbRepository) LeaseDeliveries(ctx context.Context, ids []int64, until time.Time) error {
+	_, err := database.Conn(ctx, repo.bunDB).NewUpdate().
+		Model((*DeliveryModel)(nil)).
+		Set("next_attempt_at = ?", until).
+		Where("id IN (?)", bun.In(ids)).
+		Exec(ctx)
+
+	return err
+}
+
+func (repo *dbRepository) UpdateDelivery(ctx context.Context, delivery *Delivery) error {
+	result, err := database.Conn(ctx, repo.bunDB).NewUpdate().
+		Model(NewDeliveryModelFromEntity(delivery)).
+		Column(
+			"status",
+			"attempts",
+			"next_attempt_at",
+			"last_status_code",
+			"last_error",
+			"delivered_at",
+			"updated_at",
+		).
+		WherePK().
+		Exec(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	} else if rows == 0 {
+		return errorlib.ErrNotFound(nil)
+	}
+
+	return nil
+}
+
+func (repo *dbRepository) CreateDeliveryAttempt(ctx context.Context, attempt *DeliveryAttempt) error {
+	attemptModel := &DeliveryAttemptModel{
+		DeliveryID:  attempt.DeliveryID,
+		StatusCode:  attempt.StatusCode,
+		Error:       attempt.Error,
+		DurationMs:  attempt.Duration.Milliseconds(),
+		AttemptedAt: attempt.AttemptedAt,
+	}
+
+	_, err := database.Conn(ctx, repo.bunDB).NewInsert().
+		Model(attemptModel).
+		Exec(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	attempt.ID = attemptModel.ID
+
+	return nil
+}
@@ -0,0 +1,419 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/transactions"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/outbox"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/pagination"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/validation"
+)
+
+const (
+	DeliveryIDHeader = "X-Webhook-Delivery-ID"
+	EventTypeHeader  = "X-Webhook-Event"
+	TimestampHeader  = "X-Webhook-Timestamp"
+	SignatureHeader  = "X-Webhook-Signature"
+	signaturePrefix  = "sha256="
+
+	secretLength           = 32
+	dueDeliveriesBatchSize = 50
+	maxResponseBodySize    = 64 * 1024
+	defaultDeliveryTimeout = 30 * time.Second
+)
+
+var SupportedEventTypes = []string{ //nolint:gochecknoglobals // supported events list
+	accounts.AccountCreatedEvent,
+	accounts.AccountStatusChangedEvent,
+	transactions.TransactionCreatedEvent,
+}
+
+var ErrInvalidEventType = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"invalid_event_type",
+	"invalid event type",
+	http.StatusBadRequest,
+)
+var ErrAccountIDNotFound = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"account_id_not_found",
+	"account_id not found",
+	http.StatusBadRequest,
+)
+var ErrInvalidCursor = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"invalid_cursor",
+	"invalid cursor",
+	http.StatusBadRequest,
+)
+
+type Service interface {
+	CreateSubscription(context.Context, *CreateSubscriptionRequest) (*Subscription, error)
+	GetSubscription(context.Context, int64) (*Subscription, error)
+	ListSubscriptions(context.Context) ([]*Subscription, error)
+	DeleteSubscription(context.Context, int64) error
+	ListDeliveries(context.Context, *ListDeliveriesRequest) (*DeliveriesPage, error)
+	Redeliver(ctx context.Context, subscriptionID int64, deliveryID int64) (*Delivery, error)
+	EnqueueDeliveries(context.Context, *outbox.Event) error
+	DeliverPending(context.Context) (int, error)
+}
+
+type CreateSubscriptionRequest struct {
+	URL        string   `json:"url"         validate:"required,http_url,max=2048"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,required"`
+	AccountID  *int64   `json:"account_id"  validate:"omitempty,gt=0"`
+	Secret     string   `json:"secret"      validate:"omitempty,min=16,max=128"`
+}
+
+type ListDeliveriesRequest struct {
+	SubscriptionID int64          `form:"-"      validate:"required"`
+	Status         DeliveryStatus `form:"status" validate:"omitempty,oneof=pending retrying delivered dead"`
+	Cursor         string         `form:"cursor"`
+	Limit          int            `form:"limit"  validate:"omitempty,min=1,max=100"`
+}
+
+type webhooksService struct {
+	repo        Repository
+	accountsSvc accounts.Service
+	client      *http.Client
+	retry       RetryPolicy
+	validate    *validator.Validate
+}
+
+func NewService(
+	repo Repository,
+	accountsSvc accounts.Service,
+	client *http.Client,
+	retry RetryPolicy,
+) Service {
+	return &webhooksService{
+		repo:        repo,
+		accountsSvc: accountsSvc,
+		client:      client,
+		retry:       retry,
+		validate:    validation.New(),
+	}
+}
+
+func (svc *webhooksService) CreateSubscription(
+	ctx context.Context,
+	req *CreateSubscriptionRequest,
+) (*Subscription, error) {
+	req.URL = strings.TrimSpace(req.URL)
+
+	if err := svc.validate.Struct(req); err != nil {
+		return nil, errorlib.ErrInvalidPayload(err)
+	}
+
+	eventTypes := []string{}
+
+	for _, eventType := range req.EventTypes {
+		if !slices.Contains(SupportedEventTypes, eventType) {
+			return nil, ErrInvalidEventType(nil)
+		} else if !slices.Contains(eventTypes, eventType) {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+
+	if req.AccountID != nil {
+		if _, err := svc.accountsSvc.GetAccountByID(ctx, *req.AccountID); err != nil {
+			return nil, ErrAccountIDNotFound(err)
+		}
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = generateSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	subscription := &Subscription{
+		URL:        req.URL,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		AccountID:  req.AccountID,
+		CreatedAt:  time.Now(),
+	}
+
+	subscription.UpdatedAt = subscription.CreatedAt
+
+	if err := svc.repo.CreateSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (svc *webhooksService) GetSubscription(ctx context.Context, id int64) (*Subscription, error) {
+	return svc.repo.GetSubscriptionByID(ctx, id)
+}
+
+func (svc *webhooksService) ListSubscriptions(ctx context.Context) ([]*Subscription, error) {
+	return svc.repo.ListSubscriptions(ctx)
+}
+
+func (svc *webhooksService) DeleteSubscription(ctx context.Context, id int64) error {
+	return svc.repo.DeleteSubscription(ctx, id)
+}
+
+func (svc *webhooksService) ListDeliveries(ctx context.Context, req *ListDeliveriesRequest) (*DeliveriesPage, error) {
+	if err := svc.validate.Struct(req); err != nil {
+		return nil, errorlib.ErrInvalidPayload(err)
+	}
+
+	filter := &DeliveriesFilter{
+		SubscriptionID: req.SubscriptionID,
+		Status:         req.Status,
+	}
+
+	if req.Cursor != "" {
+		filter.After = &DeliveriesCursor{}
+		if err := pagination.DecodeCursor(req.Cursor, filter.After); err != nil {
+			return nil, ErrInvalidCursor(err)
+		}
+	}
+
+	if _, err := svc.repo.GetSubscriptionByID(ctx, req.SubscriptionID); err != nil {
+		return nil, err
+	}
+
+	limit := pagination.NormalizeLimit(req.Limit)
+	filter.Limit = limit + 1
+
+	deliveries, err := svc.repo.ListDeliveries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &DeliveriesPage{Items: deliveries}
+	if len(deliveries) > limit {
+		page.Items = deliveries[:limit]
+
+		page.NextCursor, err = pagination.EncodeCursor(DeliveriesCursor{ID: page.Items[limit-1].ID})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+// Redeliver schedules the delivery to be sent again, restarting its retries.
+func (svc *webhooksService) Redeliver(ctx context.Context, subscriptionID int64, deliveryID int64) (*Delivery, error) {
+	delivery, err := svc.repo.GetDeliveryByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	} else if delivery.SubscriptionID != subscriptionID {
+		return nil, errorlib.ErrNotFound(nil)
+	}
+
+	now := time.Now()
+	delivery.Status = PendingStatus
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = now
+	delivery.UpdatedAt = now
+
+	if err := svc.repo.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// EnqueueDeliveries creates a delivery of the event for each matching subscription.
+func (svc *webhooksService) EnqueueDeliveries(ctx context.Context, event *outbox.Event) error {
+	subscriptions, err := svc.repo.ListSubscriptionsForEvent(ctx, event.Type, event.AccountID)
+	if err != nil || len(subscriptions) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	deliveries := make([]*Delivery, 0, len(subscriptions))
+
+	for _, subscription := range subscriptions {
+		deliveries = append(deliveries, &Delivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         PendingStatus,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+	}
+
+	return svc.repo.CreateDeliveries(ctx, deliveries)
+}
+
+// DeliverPending sends a batch of due deliveries, returning how many were attempted. The batch is
+// claimed with a lease in a short transaction, and each attempt is recorded in its own transaction,
+// so the deliveries not recorded are sent again after the lease expires.
+func (svc *webhooksService) DeliverPending(ctx context.Context) (int, error) {
+	deliveries, err := svc.claimDueDeliveries(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	attempted := 0
+	errs := []error{}
+
+	for _, delivery := range deliveries {
+		attempt := svc.send(ctx, delivery)
+		svc.applyAttempt(delivery, attempt)
+
+		err := svc.repo.RunInTx(ctx, func(ctx context.Context) error {
+			if err := svc.repo.CreateDeliveryAttempt(ctx, attempt); err != nil {
+				return err
+			}
+
+			return svc.repo.UpdateDelivery(ctx, delivery)
+		})
+
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		attempted++
+	}
+
+	return attempted, errors.Join(errs...)
+}
+
+func (svc *webhooksService) claimDueDeliveries(ctx context.Context) ([]*Delivery, error) {
+	var deliveries []*Delivery
+
+	err := svc.repo.RunInTx(ctx, func(ctx context.Context) error {
+		now := time.Now()
+
+		var err error
+
+		deliveries, err = svc.repo.ListDueDeliveries(ctx, now, dueDeliveriesBatchSize)
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]int64, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+		}
+
+		return svc.repo.LeaseDeliveries(ctx, ids, now.Add(svc.deliveryLease()))
+	})
+
+	return deliveries, err
+}
+
+// deliveryLease covers the sending of the whole batch, with the timeout of each request.
+func (svc *webhooksService) deliveryLease() time.Duration {
+	timeout := svc.client.Timeout
+	if timeout == 0 {
+		timeout = defaultDeliveryTimeout
+	}
+
+	return timeout * (dueDeliveriesBatchSize + 1)
+}
+
+func (svc *webhooksService) send(ctx context.Context, delivery *Delivery) *DeliveryAttempt {
+	attempt := &DeliveryAttempt{
+		DeliveryID:  delivery.ID,
+		AttemptedAt: time.Now(),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+
+	timestamp := strconv.FormatInt(attempt.AttemptedAt.Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryIDHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(EventTypeHeader, delivery.EventType)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(delivery.Subscription.Secret, timestamp, delivery.Payload))
+
+	resp, err := svc.client.Do(req)
+	attempt.Duration = time.Since(attempt.AttemptedAt)
+
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBodySize))
+
+	attempt.StatusCode = &resp.StatusCode
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		attempt.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+
+	return attempt
+}
+
+func (svc *webhooksService) applyAttempt(delivery *Delivery, attempt *DeliveryAttempt) {
+	now := time.Now()
+
+	delivery.Attempts++
+	delivery.LastStatusCode = attempt.StatusCode
+	delivery.LastError = attempt.Error
+	delivery.UpdatedAt = now
+
+	switch {
+	case attempt.Error == "":
+		delivery.Status = DeliveredStatus
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= svc.retry.MaxAttempts:
+		delivery.Status = DeadStatus
+	default:
+		delivery.Status = RetryingStatus
+		delivery.NextAttemptAt = now.Add(svc.retry.Backoff(delivery.Attempts))
+	}
+}
+
+// Sign returns the HMAC-SHA256 signature of the delivery, computed over "<timestamp>.<body>".
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature allows the receivers to check the signature of a delivery.
+func VerifySignature(secret string, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+func generateSecret() (string, error) {
+	data := make([]byte, secretLength)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(data), nil
+}
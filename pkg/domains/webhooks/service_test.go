@@ -0,0 +1,387 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
+	accountMocks "github.com/rudineirk/pismo-challenge/pkg/domains/accounts/mocks"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/transactions"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/webhooks"
+	mocks "github.com/rudineirk/pismo-challenge/pkg/domains/webhooks/mocks"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/outbox"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	assert "github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+)
+
+var retryPolicy = webhooks.RetryPolicy{ //nolint:gochecknoglobals // test config
+	MaxAttempts: 3,
+	BaseBackoff: time.Minute,
+	MaxBackoff:  time.Hour,
+}
+
+func TestCreateSubscription(t *testing.T) {
+	setupMocks := func(t *testing.T) (webhooks.Service, *mocks.MockRepository, *accountMocks.MockService) {
+		mockCtrl := gomock.NewController(t)
+		repo := mocks.NewMockRepository(mockCtrl)
+		accountsSvc := accountMocks.NewMockService(mockCtrl)
+
+		return webhooks.NewService(repo, accountsSvc, http.DefaultClient, retryPolicy), repo, accountsSvc
+	}
+
+	t.Run("should create a subscription with a generated secret", func(t *testing.T) {
+		svc, repo, accountsSvc := setupMocks(t)
+		ctx := context.TODO()
+		accountID := int64(1)
+
+		accountsSvc.EXPECT().GetAccountByID(ctx, accountID).
+			Return(&accounts.Account{ID: accountID}, nil)
+		repo.EXPECT().CreateSubscription(ctx, gomock.Any()).
+			Do(func(_ context.Context, subscription *webhooks.Subscription) {
+				subscription.ID = 1
+			}).
+			Return(nil)
+
+		subscription, err := svc.CreateSubscription(ctx, &webhooks.CreateSubscriptionRequest{
+			URL: " https://partner.example.com/hooks ",
+			EventTypes: []string{
+				transactions.TransactionCreatedEvent,
+				accounts.AccountCreatedEvent,
+				transactions.TransactionCreatedEvent,
+			},
+			AccountID: &accountID,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, int64(1), subscription.ID)
+		assert.Equal(t, "https://partner.example.com/hooks", subscription.URL)
+		assert.Equal(t, []string{transactions.TransactionCreatedEvent, accounts.AccountCreatedEvent}, subscription.EventTypes)
+		assert.Len(t, subscription.Secret, 64)
+	})
+
+	t.Run("should keep the given secret", func(t *testing.T) {
+		svc, repo, _ := setupMocks(t)
+		ctx := context.TODO()
+
+		repo.EXPECT().CreateSubscription(ctx, gomock.Any()).Return(nil)
+
+		subscription, err := svc.CreateSubscription(ctx, &webhooks.CreateSubscriptionRequest{
+			URL:        "http://localhost:8080/hooks",
+			EventTypes: []string{accounts.AccountCreatedEvent},
+			Secret:     "my-partner-secret-value",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "my-partner-secret-value", subscription.Secret)
+	})
+
+	t.Run("should return error if the request is invalid", func(t *testing.T) {
+		svc, _, accountsSvc := setupMocks(t)
+		ctx := context.TODO()
+		accountID := int64(2)
+
+		_, err := svc.CreateSubscription(ctx, &webhooks.CreateSubscriptionRequest{
+			URL:        "ftp://partner.example.com",
+			EventTypes: []string{accounts.AccountCreatedEvent},
+		})
+		assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+
+		_, err = svc.CreateSubscription(ctx, &webhooks.CreateSubscriptionRequest{
+			URL: "https://partner.example.com",
+		})
+		assert.ErrorIs(t, err, errorlib.ErrInvalidPayload(nil))
+
+		_, err = svc.CreateSubscription(ctx, &webhooks.CreateSubscriptionRequest{
+			URL:        "https://partner.example.com",
+			EventTypes: []string{"AccountDeleted"},
+		})
+		assert.ErrorIs(t, err, webhooks.ErrInvalidEventType(nil))
+
+		accountsSvc.EXPECT().GetAccountByID(ctx, accountID).
+			Return(nil, errorlib.ErrNotFound(nil))
+
+		_, err = svc.CreateSubscription(ctx, &webhooks.CreateSubscriptionRequest{
+			URL:        "https://partner.example.com",
+			EventTypes: []string{accounts.AccountCreatedEvent},
+			AccountID:  &accountID,
+		})
+		assert.ErrorIs(t, err, webhooks.ErrAccountIDNotFound(nil))
+	})
+}
+
+func TestEnqueueDeliveries(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	svc := webhooks.NewService(repo, accountMocks.NewMockService(mockCtrl), http.DefaultClient, retryPolicy)
+
+	event := &outbox.Event{
+		ID:        10,
+		Type:      transactions.TransactionCreatedEvent,
+		AccountID: 1,
+		Payload:   json.RawMessage(`{"transaction_id":5}`),
+		CreatedAt: time.Now(),
+	}
+
+	t.Run("should create a delivery for each subscription", func(t *testing.T) {
+		ctx := context.TODO()
+
+		repo.EXPECT().ListSubscriptionsForEvent(ctx, event.Type, event.AccountID).
+			Return([]*webhooks.Subscription{{ID: 1}, {ID: 2}}, nil)
+		repo.EXPECT().CreateDeliveries(ctx, gomock.Any()).
+			Do(func(_ context.Context, deliveries []*webhooks.Delivery) {
+				assert.Len(t, deliveries, 2)
+				assert.Equal(t, int64(1), deliveries[0].SubscriptionID)
+				assert.Equal(t, int64(2), deliveries[1].SubscriptionID)
+
+				for _, delivery := range deliveries {
+					assert.Equal(t, event.ID, delivery.EventID)
+					assert.Equal(t, webhooks.PendingStatus, delivery.Status)
+
+					sent := outbox.Event{}
+					assert.NoError(t, json.Unmarshal(delivery.Payload, &sent))
+					assert.Equal(t, event.ID, sent.ID)
+					assert.JSONEq(t, string(event.Payload), string(sent.Payload))
+				}
+			}).
+			Return(nil)
+
+		err := svc.EnqueueDeliveries(ctx, event)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should not create deliveries without subscriptions", func(t *testing.T) {
+		ctx := context.TODO()
+
+		repo.EXPECT().ListSubscriptionsForEvent(ctx, event.Type, event.AccountID).
+			Return([]*webhooks.Subscription{}, nil)
+
+		err := svc.EnqueueDeliveries(ctx, event)
+		assert.NoError(t, err)
+	})
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func TestDeliverPending(t *testing.T) {
+	setupReceiver := func(t *testing.T, statusCode int) (*httptest.Server, chan *receivedRequest) {
+		requests := make(chan *receivedRequest, 1)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			requests <- &receivedRequest{header: r.Header, body: body}
+			w.WriteHeader(statusCode)
+		}))
+		t.Cleanup(server.Close)
+
+		return server, requests
+	}
+
+	setupMocks := func(t *testing.T, server *httptest.Server) (webhooks.Service, *mocks.MockRepository) {
+		mockCtrl := gomock.NewController(t)
+		repo := mocks.NewMockRepository(mockCtrl)
+
+		repo.EXPECT().
+			RunInTx(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			}).
+			AnyTimes()
+
+		return webhooks.NewService(repo, accountMocks.NewMockService(mockCtrl), server.Client(), retryPolicy), repo
+	}
+
+	expectLease := func(t *testing.T, repo *mocks.MockRepository, ids ...int64) {
+		repo.EXPECT().
+			LeaseDeliveries(gomock.Any(), ids, gomock.Any()).
+			Do(func(_ context.Context, _ []int64, until time.Time) {
+				assert.True(t, until.After(time.Now().Add(time.Minute)))
+			}).
+			Return(nil)
+	}
+
+	newDelivery := func(url string, attempts int) *webhooks.Delivery {
+		return &webhooks.Delivery{
+			ID:             7,
+			SubscriptionID: 1,
+			EventID:        10,
+			EventType:      accounts.AccountCreatedEvent,
+			Payload:        json.RawMessage(`{"id":10,"type":"AccountCreated"}`),
+			Status:         webhooks.PendingStatus,
+			Attempts:       attempts,
+			Subscription:   &webhooks.Subscription{ID: 1, URL: url, Secret: "secret"},
+		}
+	}
+
+	t.Run("should send the signed delivery", func(t *testing.T) {
+		server, requests := setupReceiver(t, http.StatusNoContent)
+		svc, repo := setupMocks(t, server)
+		ctx := context.TODO()
+		delivery := newDelivery(server.URL, 0)
+
+		repo.EXPECT().ListDueDeliveries(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]*webhooks.Delivery{delivery}, nil)
+		expectLease(t, repo, delivery.ID)
+		repo.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, attempt *webhooks.DeliveryAttempt) {
+				assert.Equal(t, delivery.ID, attempt.DeliveryID)
+				assert.Equal(t, http.StatusNoContent, *attempt.StatusCode)
+				assert.Empty(t, attempt.Error)
+			}).
+			Return(nil)
+		repo.EXPECT().UpdateDelivery(gomock.Any(), delivery).Return(nil)
+
+		attempted, err := svc.DeliverPending(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, attempted)
+
+		req := <-requests
+		assert.Equal(t, "7", req.header.Get(webhooks.DeliveryIDHeader))
+		assert.Equal(t, accounts.AccountCreatedEvent, req.header.Get(webhooks.EventTypeHeader))
+		assert.JSONEq(t, string(delivery.Payload), string(req.body))
+		assert.True(t, webhooks.VerifySignature(
+			"secret",
+			req.header.Get(webhooks.TimestampHeader),
+			req.body,
+			req.header.Get(webhooks.SignatureHeader),
+		))
+
+		assert.Equal(t, webhooks.DeliveredStatus, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.NotNil(t, delivery.DeliveredAt)
+	})
+
+	t.Run("should schedule a retry with exponential backoff if the delivery fails", func(t *testing.T) {
+		server, requests := setupReceiver(t, http.StatusInternalServerError)
+		svc, repo := setupMocks(t, server)
+		ctx := context.TODO()
+		delivery := newDelivery(server.URL, 1)
+
+		repo.EXPECT().ListDueDeliveries(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]*webhooks.Delivery{delivery}, nil)
+		expectLease(t, repo, delivery.ID)
+		repo.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Return(nil)
+		repo.EXPECT().UpdateDelivery(gomock.Any(), delivery).Return(nil)
+
+		now := time.Now()
+
+		_, err := svc.DeliverPending(ctx)
+		assert.NoError(t, err)
+		<-requests
+
+		assert.Equal(t, webhooks.RetryingStatus, delivery.Status)
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.Equal(t, http.StatusInternalServerError, *delivery.LastStatusCode)
+		assert.Equal(t, "unexpected status 500", delivery.LastError)
+		assert.WithinDuration(t, now.Add(2*time.Minute), delivery.NextAttemptAt, time.Second)
+	})
+
+	t.Run("should move the delivery to dead after the max attempts", func(t *testing.T) {
+		server, requests := setupReceiver(t, http.StatusBadGateway)
+		svc, repo := setupMocks(t, server)
+		ctx := context.TODO()
+		delivery := newDelivery(server.URL, retryPolicy.MaxAttempts-1)
+
+		repo.EXPECT().ListDueDeliveries(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]*webhooks.Delivery{delivery}, nil)
+		expectLease(t, repo, delivery.ID)
+		repo.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Return(nil)
+		repo.EXPECT().UpdateDelivery(gomock.Any(), delivery).Return(nil)
+
+		_, err := svc.DeliverPending(ctx)
+		assert.NoError(t, err)
+		<-requests
+
+		assert.Equal(t, webhooks.DeadStatus, delivery.Status)
+		assert.Equal(t, retryPolicy.MaxAttempts, delivery.Attempts)
+	})
+
+	t.Run("should record each attempt in its own transaction", func(t *testing.T) {
+		server, requests := setupReceiver(t, http.StatusOK)
+		svc, repo := setupMocks(t, server)
+		ctx := context.TODO()
+		dbErr := errors.New("db error")
+		failed, delivery := newDelivery(server.URL, 0), newDelivery(server.URL, 0)
+		delivery.ID = 8
+
+		repo.EXPECT().ListDueDeliveries(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]*webhooks.Delivery{failed, delivery}, nil)
+		expectLease(t, repo, failed.ID, delivery.ID)
+		repo.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Return(dbErr)
+		repo.EXPECT().CreateDeliveryAttempt(gomock.Any(), gomock.Any()).Return(nil)
+		repo.EXPECT().UpdateDelivery(gomock.Any(), delivery).Return(nil)
+
+		go func() {
+			<-requests
+			<-requests
+		}()
+
+		attempted, err := svc.DeliverPending(ctx)
+		assert.ErrorIs(t, err, dbErr)
+		assert.Equal(t, 1, attempted)
+	})
+}
+
+func TestRedeliver(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	repo := mocks.NewMockRepository(mockCtrl)
+	svc := webhooks.NewService(repo, accountMocks.NewMockService(mockCtrl), http.DefaultClient, retryPolicy)
+
+	t.Run("should schedule the delivery again", func(t *testing.T) {
+		ctx := context.TODO()
+		delivery := &webhooks.Delivery{ID: 7, SubscriptionID: 1, Status: webhooks.DeadStatus, Attempts: 3}
+
+		repo.EXPECT().GetDeliveryByID(ctx, int64(7)).Return(delivery, nil)
+		repo.EXPECT().UpdateDelivery(ctx, delivery).Return(nil)
+
+		result, err := svc.Redeliver(ctx, 1, 7)
+		assert.NoError(t, err)
+
+		assert.Equal(t, webhooks.PendingStatus, result.Status)
+		assert.Equal(t, 0, result.Attempts)
+		assert.WithinDuration(t, time.Now(), result.NextAttemptAt, time.Second)
+	})
+
+	t.Run("should return error if the delivery is from another subscription", func(t *testing.T) {
+		ctx := context.TODO()
+
+		repo.EXPECT().GetDeliveryByID(ctx, int64(8)).
+			Return(&webhooks.Delivery{ID: 8, SubscriptionID: 2}, nil)
+
+		_, err := svc.Redeliver(ctx, 1, 8)
+		assert.ErrorIs(t, err, errorlib.ErrNotFound(nil))
+	})
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := webhooks.RetryPolicy{MaxAttempts: 10, BaseBackoff: 30 * time.Second, MaxBackoff: 5 * time.Minute}
+
+	assert.Equal(t, 30*time.Second, policy.Backoff(1))
+	assert.Equal(t, time.Minute, policy.Backoff(2))
+	assert.Equal(t, 4*time.Minute, policy.Backoff(4))
+	assert.Equal(t, 5*time.Minute, policy.Backoff(5))
+	assert.Equal(t, 5*time.Minute, policy.Backoff(100))
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	signature := webhooks.Sign("secret", "1700000000", body)
+
+	assert.Regexp(t, "^sha256=[0-9a-f]{64}$", signature)
+	assert.True(t, webhooks.VerifySignature("secret", "1700000000", body, signature))
+	assert.False(t, webhooks.VerifySignature("other", "1700000000", body, signature))
+	assert.False(t, webhooks.VerifySignature("secret", "1700000001", body, signature))
+}
@@ -5,8 +5,10 @@ import (
 
 	_ "github.com/lib/pq"
 
+	"github.com/rudineirk/pismo-challenge/pkg/infra/metrics"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/extra/bunotel"
 )
 
 func NewDatabase(databaseURL string) (*sql.DB, *bun.DB, error) {
@@ -21,5 +23,10 @@ func NewDatabase(databaseURL string) (*sql.DB, *bun.DB, error) {
 		return nil, nil, err
 	}
 
-	return sqlDB, bun.NewDB(sqlDB, pgdialect.New(), bun.WithDiscardUnknownColumns()), nil
+	metrics.RegisterDB(sqlDB, "main")
+
+	bunDB := bun.NewDB(sqlDB, pgdialect.New(), bun.WithDiscardUnknownColumns())
+	bunDB.AddQueryHook(bunotel.NewQueryHook(bunotel.WithDBName("main")))
+
+	return sqlDB, bunDB, nil
 }
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+)
+
+type txContextKey struct{}
+
+// RunInTx stores the transaction in the context, nested calls reuse the same transaction.
+func RunInTx(ctx context.Context, bunDB *bun.DB, fn func(context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey{}).(bun.Tx); ok {
+		return fn(ctx)
+	}
+
+	return bunDB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return fn(context.WithValue(ctx, txContextKey{}, tx))
+	})
+}
+
+func Conn(ctx context.Context, bunDB *bun.DB) bun.IDB {
+	if tx, ok := ctx.Value(txContextKey{}).(bun.Tx); ok {
+		return tx
+	}
+
+	return bunDB
+}
+
+// RunInSavepoint runs the function inside a savepoint of the transaction stored in the context,
+// an error only rolls back the changes made by the function. Without a transaction it behaves as RunInTx.
+func RunInSavepoint(ctx context.Context, bunDB *bun.DB, fn func(context.Context) error) error {
+	tx, ok := ctx.Value(txContextKey{}).(bun.Tx)
+	if !ok {
+		return RunInTx(ctx, bunDB, fn)
+	}
+
+	return tx.RunInTx(ctx, nil, func(ctx context.Context, savepoint bun.Tx) error {
+		return fn(context.WithValue(ctx, txContextKey{}, savepoint))
+	})
+}
+
+// WithTx stores a transaction opened outside of RunInTx in the context, so the RunInTx
+// calls using the context join it.
+func WithTx(ctx context.Context, tx bun.Tx) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
@@ -0,0 +1,191 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	keySize       = 32
+	nonceSize     = 12
+	envelopeV1    = byte(1)
+	wrappedDEKLen = nonceSize + keySize + 16
+)
+
+var ErrUnknownKeyID = errors.New("unknown encryption key id") //nolint:gochecknoglobals // constant error
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")   //nolint:gochecknoglobals // constant error
+
+// Keyring has the key encryption keys (KEK), identified by their ids so they can be rotated,
+// and the key used to compute the blind indexes.
+type Keyring struct {
+	activeKeyID string
+	keys        map[string][]byte
+	indexKey    []byte
+}
+
+type keysFile struct {
+	ActiveKeyID string            `json:"active_key_id"`
+	Keys        map[string]string `json:"keys"`
+	IndexKey    string            `json:"index_key"`
+}
+
+func NewKeyring(activeKeyID string, keys map[string][]byte, indexKey []byte) (*Keyring, error) {
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("active encryption key %q not found", activeKeyID)
+	}
+
+	for keyID, key := range keys {
+		if len(key) != keySize {
+			return nil, fmt.Errorf("encryption key %q must have %d bytes", keyID, keySize)
+		}
+	}
+
+	if len(indexKey) < keySize {
+		return nil, fmt.Errorf("blind index key must have at least %d bytes", keySize)
+	}
+
+	return &Keyring{activeKeyID: activeKeyID, keys: keys, indexKey: indexKey}, nil
+}
+
+// LoadKeyring reads the base64 keys from the keys file, when set, or from the env config.
+func LoadKeyring(filePath string, activeKeyID string, keys map[string]string, indexKey string) (*Keyring, error) {
+	if filePath != "" {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		file := keysFile{}
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("invalid encryption keys file: %w", err)
+		}
+
+		activeKeyID, keys, indexKey = file.ActiveKeyID, file.Keys, file.IndexKey
+	}
+
+	decodedKeys := make(map[string][]byte, len(keys))
+
+	for keyID, key := range keys {
+		decoded, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key %q: %w", keyID, err)
+		}
+
+		decodedKeys[keyID] = decoded
+	}
+
+	decodedIndexKey, err := base64.StdEncoding.DecodeString(indexKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid blind index key: %w", err)
+	}
+
+	return NewKeyring(activeKeyID, decodedKeys, decodedIndexKey)
+}
+
+func (keyring *Keyring) ActiveKeyID() string {
+	return keyring.activeKeyID
+}
+
+// Encrypt uses envelope encryption: the value is encrypted with a random data key (DEK),
+// which is wrapped by the active key and saved together with the ciphertext.
+func (keyring *Keyring) Encrypt(plaintext []byte) (string, []byte, error) {
+	dek := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+		return "", nil, err
+	}
+
+	keyID := keyring.activeKeyID
+
+	wrappedDEK, err := seal(keyring.keys[keyID], dek, []byte(keyID))
+	if err != nil {
+		return "", nil, err
+	}
+
+	ciphertext, err := seal(dek, plaintext, nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	envelope := make([]byte, 0, 1+len(wrappedDEK)+len(ciphertext))
+	envelope = append(envelope, envelopeV1)
+	envelope = append(envelope, wrappedDEK...)
+	envelope = append(envelope, ciphertext...)
+
+	return keyID, envelope, nil
+}
+
+func (keyring *Keyring) Decrypt(keyID string, envelope []byte) ([]byte, error) {
+	kek, ok := keyring.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKeyID, keyID)
+	} else if len(envelope) < 1+wrappedDEKLen || envelope[0] != envelopeV1 {
+		return nil, ErrInvalidCiphertext
+	}
+
+	dek, err := open(kek, envelope[1:1+wrappedDEKLen], []byte(keyID))
+	if err != nil {
+		return nil, err
+	}
+
+	return open(dek, envelope[1+wrappedDEKLen:], nil)
+}
+
+// BlindIndex returns a keyed hash of the value, allowing exact lookups and unique
+// constraints without storing the plaintext.
+func (keyring *Keyring) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, keyring.indexKey)
+	mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func seal(key []byte, plaintext []byte, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, nonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(key []byte, data []byte, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < nonceSize {
+		return nil, ErrInvalidCiphertext
+	}
+
+	plaintext, err := aead.Open(nil, data[:nonceSize], data[nonceSize:], additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCiphertext, err)
+	}
+
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,127 @@
+package encryption_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rudineirk/pismo-challenge/pkg/infra/encryption"
+	assert "github.com/stretchr/testify/require"
+)
+
+func newKey(value byte) []byte {
+	return bytes.Repeat([]byte{value}, 32)
+}
+
+func TestKeyring(t *testing.T) {
+	keyring, err := encryption.NewKeyring("k1", map[string][]byte{"k1": newKey(1)}, newKey(9))
+	assert.NoError(t, err)
+
+	t.Run("should encrypt and decrypt a value", func(t *testing.T) {
+		keyID, ciphertext, err := keyring.Encrypt([]byte("23383829006"))
+		assert.NoError(t, err)
+		assert.Equal(t, "k1", keyID)
+		assert.NotContains(t, string(ciphertext), "23383829006")
+
+		plaintext, err := keyring.Decrypt(keyID, ciphertext)
+		assert.NoError(t, err)
+		assert.Equal(t, "23383829006", string(plaintext))
+	})
+
+	t.Run("should use a different data key for each value", func(t *testing.T) {
+		_, first, err := keyring.Encrypt([]byte("23383829006"))
+		assert.NoError(t, err)
+
+		_, second, err := keyring.Encrypt([]byte("23383829006"))
+		assert.NoError(t, err)
+
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("should decrypt values of rotated keys", func(t *testing.T) {
+		keyID, ciphertext, err := keyring.Encrypt([]byte("23383829006"))
+		assert.NoError(t, err)
+
+		rotated, err := encryption.NewKeyring(
+			"k2",
+			map[string][]byte{"k1": newKey(1), "k2": newKey(2)},
+			newKey(9),
+		)
+		assert.NoError(t, err)
+
+		plaintext, err := rotated.Decrypt(keyID, ciphertext)
+		assert.NoError(t, err)
+		assert.Equal(t, "23383829006", string(plaintext))
+
+		keyID, _, err = rotated.Encrypt(plaintext)
+		assert.NoError(t, err)
+		assert.Equal(t, "k2", keyID)
+
+		assert.Equal(t, keyring.BlindIndex("23383829006"), rotated.BlindIndex("23383829006"))
+	})
+
+	t.Run("should return error if the key or ciphertext are invalid", func(t *testing.T) {
+		_, ciphertext, err := keyring.Encrypt([]byte("23383829006"))
+		assert.NoError(t, err)
+
+		_, err = keyring.Decrypt("k2", ciphertext)
+		assert.ErrorIs(t, err, encryption.ErrUnknownKeyID)
+
+		ciphertext[len(ciphertext)-1] ^= 1
+		_, err = keyring.Decrypt("k1", ciphertext)
+		assert.ErrorIs(t, err, encryption.ErrInvalidCiphertext)
+
+		_, err = keyring.Decrypt("k1", []byte("short"))
+		assert.ErrorIs(t, err, encryption.ErrInvalidCiphertext)
+	})
+
+	t.Run("should compute a deterministic blind index", func(t *testing.T) {
+		index := keyring.BlindIndex("23383829006")
+		assert.Len(t, index, 64)
+		assert.Equal(t, index, keyring.BlindIndex("23383829006"))
+		assert.NotEqual(t, index, keyring.BlindIndex("05677940000133"))
+	})
+}
+
+func TestNewKeyringInvalid(t *testing.T) {
+	_, err := encryption.NewKeyring("k2", map[string][]byte{"k1": newKey(1)}, newKey(9))
+	assert.Error(t, err)
+
+	_, err = encryption.NewKeyring("k1", map[string][]byte{"k1": []byte("short")}, newKey(9))
+	assert.Error(t, err)
+
+	_, err = encryption.NewKeyring("k1", map[string][]byte{"k1": newKey(1)}, []byte("short"))
+	assert.Error(t, err)
+}
+
+func TestLoadKeyring(t *testing.T) {
+	encodedKey := base64.StdEncoding.EncodeToString(newKey(1))
+	encodedIndexKey := base64.StdEncoding.EncodeToString(newKey(9))
+
+	t.Run("should load the keys from the env config", func(t *testing.T) {
+		keyring, err := encryption.LoadKeyring("", "k1", map[string]string{"k1": encodedKey}, encodedIndexKey)
+		assert.NoError(t, err)
+		assert.Equal(t, "k1", keyring.ActiveKeyID())
+	})
+
+	t.Run("should load the keys from the keys file", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "keys.json")
+		err := os.WriteFile(filePath, []byte(`{"active_key_id":"file","keys":{"file":"`+encodedKey+
+			`"},"index_key":"`+encodedIndexKey+`"}`), 0o600)
+		assert.NoError(t, err)
+
+		keyring, err := encryption.LoadKeyring(filePath, "k1", map[string]string{"k1": encodedKey}, encodedIndexKey)
+		assert.NoError(t, err)
+		assert.Equal(t, "file", keyring.ActiveKeyID())
+	})
+
+	t.Run("should return error if the keys are invalid", func(t *testing.T) {
+		_, err := encryption.LoadKeyring("", "k1", map[string]string{"k1": "not base64!"}, encodedIndexKey)
+		assert.Error(t, err)
+
+		_, err = encryption.LoadKeyring(filepath.Join(t.TempDir(), "missing.json"), "", nil, "")
+		assert.Error(t, err)
+	})
+}
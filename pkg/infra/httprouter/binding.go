@@ -0,0 +1,40 @@
+package httprouter
+
+import (
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/validation"
+)
+
+// ShouldBindJSON decodes the JSON body into obj, the errors have the invalid field name,
+// and an empty body returns io.EOF.
+func ShouldBindJSON(ctx *gin.Context, obj any) error {
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		return err
+	}
+
+	return validation.DecodeJSON(body, obj)
+}
+
+// BindJSON decodes the JSON body into obj, the errors are sent as invalid_payload problems.
+func BindJSON(ctx *gin.Context, obj any) error {
+	if err := ShouldBindJSON(ctx, obj); err != nil {
+		AbortWithError(ctx, errorlib.ErrInvalidPayload(err))
+		return err
+	}
+
+	return nil
+}
+
+// BindQuery decodes the query string into obj, the errors are sent as invalid_payload problems.
+func BindQuery(ctx *gin.Context, obj any) error {
+	if err := ctx.ShouldBindQuery(obj); err != nil {
+		AbortWithError(ctx, errorlib.ErrInvalidPayload(err))
+		return err
+	}
+
+	return nil
+}
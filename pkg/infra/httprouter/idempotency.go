@@ -0,0 +1,206 @@
+package httprouter
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/database"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/uptrace/bun"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+var idempotencyKeyTTL = 24 * time.Hour //nolint:gochecknoglobals // default value
+
+var ErrInvalidIdempotencyKey = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"invalid_idempotency_key",
+	"invalid Idempotency-Key header",
+	http.StatusBadRequest,
+)
+var ErrIdempotencyKeyReused = errorlib.NewError( //nolint:gochecknoglobals // error maker
+	"idempotency_key_reused",
+	"Idempotency-Key was already used with a different request",
+	http.StatusConflict,
+)
+
+type IdempotencyKeyModel struct {
+	bun.BaseModel `bun:"table:idempotency_keys"`
+	Key           string    `bun:"key,pk"`
+	Fingerprint   string    `bun:"fingerprint"`
+	StatusCode    int       `bun:"status_code"`
+	ContentType   string    `bun:"content_type"`
+	ResponseBody  []byte    `bun:"response_body"`
+	CreatedAt     time.Time `bun:"created_at"`
+}
+
+// bodyRecorder buffers the response until the transaction of the idempotency key is committed.
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (recorder *bodyRecorder) Write(data []byte) (int, error) {
+	return recorder.body.Write(data)
+}
+
+func (recorder *bodyRecorder) WriteString(data string) (int, error) {
+	return recorder.body.WriteString(data)
+}
+
+func (recorder *bodyRecorder) WriteHeaderNow() {}
+
+func (recorder *bodyRecorder) Written() bool {
+	return recorder.body.Len() > 0
+}
+
+func (recorder *bodyRecorder) flush() {
+	recorder.ResponseWriter.WriteHeaderNow()
+	_, _ = recorder.ResponseWriter.Write(recorder.body.Bytes())
+}
+
+// Idempotency stores the response of requests with an Idempotency-Key header and replays it
+// on retries. Requests with the same key are serialized with a postgres advisory lock, and the
+// handler runs in the transaction of the key, so its changes are committed with the response.
+func Idempotency(bunDB *bun.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(IdempotencyKeyHeader)
+		if key == "" || isSafeMethod(ctx.Request.Method) {
+			ctx.Next()
+			return
+		} else if len(key) > maxIdempotencyKeyLength {
+			AbortWithError(ctx, ErrInvalidIdempotencyKey(nil))
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			AbortWithError(ctx, errorlib.ErrInvalidPayload(err))
+			return
+		}
+
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := requestFingerprint(ctx.Request, body)
+
+		writer := ctx.Writer
+		recorder := &bodyRecorder{ResponseWriter: writer, body: &bytes.Buffer{}}
+
+		defer func() { ctx.Writer = writer }()
+
+		replayed := false
+		err = bunDB.RunInTx(ctx, nil, func(txCtx context.Context, tx bun.Tx) error {
+			if _, err := tx.ExecContext(txCtx, "SELECT pg_advisory_xact_lock(hashtextextended(?, 0))", key); err != nil {
+				return err
+			}
+
+			record := IdempotencyKeyModel{}
+
+			err := tx.NewSelect().
+				Model(&record).
+				Where("key = ?", key).
+				Where("created_at > ?", time.Now().Add(-idempotencyKeyTTL)).
+				Scan(txCtx)
+
+			if err == nil {
+				replayed = true
+				replayResponse(ctx, &record, fingerprint)
+				return nil
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+
+			ctx.Writer = recorder
+			if err := runHandler(ctx, tx, recorder); err != nil && !errors.Is(err, errHandlerFailed) {
+				return err
+			} else if recorder.Status() >= http.StatusInternalServerError {
+				return nil
+			}
+
+			record = IdempotencyKeyModel{
+				Key:          key,
+				Fingerprint:  fingerprint,
+				StatusCode:   recorder.Status(),
+				ContentType:  recorder.Header().Get("Content-Type"),
+				ResponseBody: recorder.body.Bytes(),
+				CreatedAt:    time.Now(),
+			}
+
+			_, err = tx.NewInsert().
+				Model(&record).
+				On("CONFLICT (key) DO UPDATE").
+				Set("fingerprint = EXCLUDED.fingerprint").
+				Set("status_code = EXCLUDED.status_code").
+				Set("content_type = EXCLUDED.content_type").
+				Set("response_body = EXCLUDED.response_body").
+				Set("created_at = EXCLUDED.created_at").
+				Exec(txCtx)
+
+			return err
+		})
+
+		switch {
+		case err != nil:
+			// nothing was committed, so the client can retry with the same key
+			AbortWithError(ctx, err)
+		case !replayed:
+			recorder.flush()
+		}
+	}
+}
+
+var errHandlerFailed = errors.New("idempotent request failed") //nolint:gochecknoglobals // constant error
+
+// runHandler runs the next handlers in a savepoint of the key transaction, error responses
+// roll back the changes made by the handler.
+func runHandler(ctx *gin.Context, tx bun.Tx, recorder *bodyRecorder) error {
+	req := ctx.Request
+	defer func() { ctx.Request = req }()
+
+	return tx.RunInTx(req.Context(), nil, func(spCtx context.Context, savepoint bun.Tx) error {
+		ctx.Request = req.WithContext(database.WithTx(spCtx, savepoint))
+		ctx.Next()
+		// the error responses are rendered here, so they are stored with the key
+		WriteProblem(ctx)
+
+		if recorder.Status() >= http.StatusBadRequest {
+			return errHandlerFailed
+		}
+
+		return nil
+	})
+}
+
+func replayResponse(ctx *gin.Context, record *IdempotencyKeyModel, fingerprint string) {
+	if record.Fingerprint != fingerprint {
+		AbortWithError(ctx, ErrIdempotencyKeyReused(nil))
+		return
+	}
+
+	ctx.Header(IdempotentReplayedHeader, "true")
+	ctx.Data(record.StatusCode, record.ContentType, record.ResponseBody)
+	ctx.Abort()
+}
+
+func requestFingerprint(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + req.URL.RequestURI() + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -0,0 +1,124 @@
+package httprouter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/validation"
+)
+
+const (
+	ContentTypeProblemJSON = "application/problem+json"
+	problemTypePrefix      = "urn:pismo-challenge:error:"
+)
+
+// Problem is the RFC 7807 body of the error responses, the errors list the invalid fields
+// of the invalid_payload problems.
+type Problem struct {
+	Type      string                  `json:"type"`
+	Title     string                  `json:"title"`
+	Status    int                     `json:"status"`
+	Code      string                  `json:"code"`
+	Detail    string                  `json:"detail"`
+	RequestID string                  `json:"request_id,omitempty"`
+	Errors    []validation.FieldError `json:"errors,omitempty"`
+}
+
+// NewProblem creates the response of the error with the status declared by its errorlib maker,
+// other errors are internal errors, and their messages aren't sent to the clients.
+func NewProblem(err error, requestID string) *Problem {
+	libErr := asLibError(err)
+
+	problem := &Problem{
+		Type:      problemTypePrefix + libErr.Code,
+		Title:     http.StatusText(libErr.Status),
+		Status:    libErr.Status,
+		Code:      libErr.Code,
+		Detail:    libErr.Message,
+		RequestID: requestID,
+	}
+
+	if libErr.Status < http.StatusInternalServerError {
+		problem.Errors = validation.Fields(libErr)
+	}
+
+	return problem
+}
+
+// AbortWithError stops the request, its response is rendered by the ErrorHandler middleware.
+func AbortWithError(ctx *gin.Context, err error) {
+	_ = ctx.Error(err)
+	ctx.Abort()
+}
+
+// WriteProblem sends the last error of the request as a problem response, unless the response
+// was already written.
+func WriteProblem(ctx *gin.Context) {
+	lastErr := ctx.Errors.Last()
+	if lastErr == nil || ctx.Writer.Written() {
+		return
+	}
+
+	problem := NewProblem(lastErr.Err, RequestIDFromContext(ctx.Request.Context()))
+
+	ctx.Header("Content-Type", ContentTypeProblemJSON)
+	ctx.JSON(problem.Status, problem)
+}
+
+// ErrorHandler renders the errors of the handlers as problem responses, and logs the stack
+// of the internal errors.
+func ErrorHandler(logger *zerolog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		lastErr := ctx.Errors.Last()
+		if lastErr == nil {
+			return
+		}
+
+		WriteProblem(ctx)
+
+		libErr := asLibError(lastErr.Err)
+		if libErr.Status < http.StatusInternalServerError {
+			return
+		}
+
+		logger.Error().
+			Ctx(ctx.Request.Context()).
+			Str("request_id", RequestIDFromContext(ctx.Request.Context())).
+			Err(lastErr.Err).
+			Strs("stack", formatStack(libErr.GetStack())).
+			Msg("Internal error")
+	}
+}
+
+// Recovery turns the panics of the handlers into internal errors, so they're rendered as problem
+// responses and logged with their stack by the ErrorHandler middleware, registered before it.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(ctx *gin.Context, recovered any) {
+		AbortWithError(ctx, errorlib.ErrInternal(fmt.Errorf("panic: %v", recovered)))
+	})
+}
+
+func asLibError(err error) *errorlib.Error {
+	var libErr *errorlib.Error
+	if errors.As(err, &libErr) {
+		return libErr
+	}
+
+	return errorlib.ErrInternal(err)
+}
+
+func formatStack(stack []runtime.Frame) []string {
+	lines := make([]string, 0, len(stack))
+	for _, frame := range stack {
+		lines = append(lines, fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line))
+	}
+
+	return lines
+}
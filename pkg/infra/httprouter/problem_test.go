@@ -0,0 +1,125 @@
+package httprouter_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/httprouter"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/validation"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestErrorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logOutput := &bytes.Buffer{}
+	logger := zerolog.New(logOutput)
+
+	router := gin.New()
+	router.Use(httprouter.RequestID(&logger), httprouter.ErrorHandler(&logger), httprouter.Recovery())
+	router.GET("/not-found", func(ctx *gin.Context) {
+		httprouter.AbortWithError(ctx, errorlib.ErrNotFound(errors.New("sql: no rows in result set")))
+	})
+	router.GET("/internal", func(ctx *gin.Context) {
+		httprouter.AbortWithError(ctx, errors.New("connection refused"))
+	})
+	router.POST("/bind", func(ctx *gin.Context) {
+		req := struct {
+			AccountID int64 `json:"account_id"`
+		}{}
+
+		if err := httprouter.BindJSON(ctx, &req); err != nil {
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	})
+	router.GET("/panic", func(_ *gin.Context) {
+		panic("nil map")
+	})
+	router.GET("/written", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "partial")
+		httprouter.AbortWithError(ctx, errorlib.ErrInvalidPayload(nil))
+	})
+
+	doRequest := func(path string) (*httptest.ResponseRecorder, *httprouter.Problem) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(httprouter.RequestIDHeader, "req-123")
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		problem := &httprouter.Problem{}
+		_ = json.Unmarshal(recorder.Body.Bytes(), problem)
+
+		return recorder, problem
+	}
+
+	t.Run("should render the error with the status of its maker", func(t *testing.T) {
+		recorder, problem := doRequest("/not-found")
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		assert.Equal(t, httprouter.ContentTypeProblemJSON, recorder.Header().Get("Content-Type"))
+		assert.Equal(t, &httprouter.Problem{
+			Type:      "urn:pismo-challenge:error:not_found",
+			Title:     "Not Found",
+			Status:    http.StatusNotFound,
+			Code:      "not_found",
+			Detail:    "entity not found",
+			RequestID: "req-123",
+		}, problem)
+	})
+
+	t.Run("should list the invalid fields of the payload", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/bind", strings.NewReader(`{"account_id":"abc"}`))
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+		problem := &httprouter.Problem{}
+		assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), problem))
+		assert.Equal(t, "invalid_payload", problem.Code)
+		assert.Equal(t, []validation.FieldError{
+			{Field: "account_id", Rule: "type", Message: "account_id must be an integer"},
+		}, problem.Errors)
+	})
+
+	t.Run("should hide and log the internal errors", func(t *testing.T) {
+		recorder, problem := doRequest("/internal")
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+		assert.Equal(t, "internal_error", problem.Code)
+		assert.NotContains(t, recorder.Body.String(), "connection refused")
+		assert.Contains(t, logOutput.String(), "connection refused")
+		assert.Contains(t, logOutput.String(), `"stack":[`)
+	})
+
+	t.Run("should render and log the panics as internal errors", func(t *testing.T) {
+		logOutput.Reset()
+		recorder, problem := doRequest("/panic")
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+		assert.Equal(t, httprouter.ContentTypeProblemJSON, recorder.Header().Get("Content-Type"))
+		assert.Equal(t, "internal_error", problem.Code)
+		assert.Equal(t, "req-123", problem.RequestID)
+		assert.NotContains(t, recorder.Body.String(), "nil map")
+		assert.Contains(t, logOutput.String(), "panic: nil map")
+		assert.Contains(t, logOutput.String(), "httprouter_test.TestErrorHandler")
+	})
+
+	t.Run("should keep the response already written", func(t *testing.T) {
+		recorder, _ := doRequest("/written")
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "partial", recorder.Body.String())
+	})
+}
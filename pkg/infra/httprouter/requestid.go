@@ -0,0 +1,41 @@
+package httprouter
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	uuid "github.com/satori/go.uuid"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// only ids that are safe to be logged and echoed are accepted from the clients
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`) //nolint:gochecknoglobals // constant regexp
+
+type requestIDContextKey struct{}
+
+// RequestID accepts the X-Request-ID header, or generates a new id, and echoes it in the response.
+// The request context receives the id and a logger with it, used by zerolog.Ctx.
+func RequestID(logger *zerolog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewV4().String()
+		}
+
+		ctx.Header(RequestIDHeader, requestID)
+
+		requestLogger := logger.With().Str("request_id", requestID).Logger()
+		requestCtx := context.WithValue(ctx.Request.Context(), requestIDContextKey{}, requestID)
+		ctx.Request = ctx.Request.WithContext(requestLogger.WithContext(requestCtx))
+
+		ctx.Next()
+	}
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
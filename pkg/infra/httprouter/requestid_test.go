@@ -0,0 +1,55 @@
+package httprouter_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/httprouter"
+	uuid "github.com/satori/go.uuid"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logOutput := &bytes.Buffer{}
+	logger := zerolog.New(logOutput)
+
+	router := gin.New()
+	router.Use(httprouter.RequestID(&logger))
+	router.GET("/log", func(ctx *gin.Context) {
+		zerolog.Ctx(ctx.Request.Context()).Info().Msg("request log")
+		ctx.String(http.StatusOK, httprouter.RequestIDFromContext(ctx.Request.Context()))
+	})
+
+	t.Run("should echo the request id header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/log", nil)
+		req.Header.Set(httprouter.RequestIDHeader, "req-123")
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		assert.Equal(t, "req-123", recorder.Header().Get(httprouter.RequestIDHeader))
+		assert.Equal(t, "req-123", recorder.Body.String())
+		assert.Contains(t, logOutput.String(), `"request_id":"req-123"`)
+	})
+
+	t.Run("should generate the request id when it isn't sent or is invalid", func(t *testing.T) {
+		for _, requestID := range []string{"", "invalid request id\n"} {
+			req := httptest.NewRequest(http.MethodGet, "/log", nil)
+			req.Header.Set(httprouter.RequestIDHeader, requestID)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			generated := recorder.Header().Get(httprouter.RequestIDHeader)
+			_, err := uuid.FromString(generated)
+			assert.Nil(t, err)
+			assert.Equal(t, generated, recorder.Body.String())
+		}
+	})
+}
@@ -7,6 +7,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/metrics"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 var defaultTimeout = 60 * time.Second    //nolint:gochecknoglobals // default value
@@ -31,7 +34,9 @@ func StructuredLogger(logger *zerolog.Logger) gin.HandlerFunc {
 			logEvent = logger.Info() //nolint:zerologlint // it's being used bellow
 		}
 
-		logEvent.Str("client_id", ctx.ClientIP()).
+		logEvent.Ctx(ctx.Request.Context()).
+			Str("request_id", RequestIDFromContext(ctx.Request.Context())).
+			Str("client_id", ctx.ClientIP()).
 			Str("method", ctx.Request.Method).
 			Int("status_code", ctx.Writer.Status()).
 			Int("body_size", ctx.Writer.Size()).
@@ -47,9 +52,21 @@ func NewRouter(logger *zerolog.Logger, isProduction bool) *gin.Engine {
 	}
 
 	router := gin.New()
-	router.Use(StructuredLogger(logger), gin.Recovery())
+	// the handlers pass the gin context to the services, the fallback keeps the request
+	// context values, like the tracing span
+	router.ContextWithFallback = true
+	router.Use(
+		otelgin.Middleware(tracing.ServiceName),
+		RequestID(logger),
+		StructuredLogger(logger),
+		metrics.HTTPMetrics(),
+		ErrorHandler(logger),
+		Recovery(),
+	)
 	_ = router.SetTrustedProxies([]string{})
 
+	router.GET("/metrics", metrics.Handler())
+
 	return router
 }
 
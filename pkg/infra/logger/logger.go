@@ -27,7 +27,7 @@ func NewLogger(logFormat string, logLevel string) *zerolog.Logger {
 		level = zerolog.InfoLevel
 	}
 
-	logger := zerolog.New(output).With().Timestamp().Logger().Level(level)
+	logger := zerolog.New(output).With().Timestamp().Logger().Level(level).Hook(TracingHook{})
 	if level == zerolog.DebugLevel {
 		logger = logger.With().Caller().Logger()
 	}
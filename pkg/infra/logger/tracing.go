@@ -0,0 +1,20 @@
+package logger
+
+import (
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingHook adds the trace and span ids of the event context, set with Event.Ctx,
+// so the log lines can be correlated with the traces.
+type TracingHook struct{}
+
+func (TracingHook) Run(event *zerolog.Event, _ zerolog.Level, _ string) {
+	spanContext := trace.SpanContextFromContext(event.GetCtx())
+	if !spanContext.IsValid() {
+		return
+	}
+
+	event.Str("trace_id", spanContext.TraceID().String()).
+		Str("span_id", spanContext.SpanID().String())
+}
@@ -0,0 +1,57 @@
+package metrics_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/metrics"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestHTTPMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(metrics.HTTPMetrics())
+	router.GET("/metrics", metrics.Handler())
+	router.GET("/accounts/:account_id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	t.Run("should label the requests with the route template", func(t *testing.T) {
+		for _, path := range []string{"/accounts/1", "/accounts/2", "/unknown/path"} {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+
+		assert.Equal(t, float64(2), testutil.ToFloat64(
+			metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/accounts/:account_id", "200"),
+		))
+		assert.Equal(t, float64(1), testutil.ToFloat64(
+			metrics.HTTPRequests.WithLabelValues(http.MethodGet, "unmatched", "404"),
+		))
+	})
+
+	t.Run("should expose the metrics", func(t *testing.T) {
+		metrics.AccountsCreated.Inc()
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		body := recorder.Body.String()
+		assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/accounts/:account_id"} 2`)
+		assert.Contains(t, body, "accounts_created_total 1")
+		assert.Contains(t, body, "go_goroutines")
+	})
+}
+
+func TestOutcome(t *testing.T) {
+	assert.Equal(t, metrics.OutcomeSuccess, metrics.Outcome(nil))
+	assert.Equal(t, "not_found", metrics.Outcome(errorlib.ErrNotFound(nil)))
+	assert.Equal(t, metrics.OutcomeError, metrics.Outcome(errors.New("connection reset")))
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Registry has the app metrics, exposed by the /metrics route.
+var Registry = prometheus.NewRegistry() //nolint:gochecknoglobals // metrics registry
+
+var (
+	HTTPRequests = promauto.With(Registry).NewCounterVec( //nolint:gochecknoglobals // metric
+		prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by method, route template and status code",
+		},
+		[]string{"method", "route", "status"},
+	)
+	HTTPRequestDuration = promauto.With(Registry).NewHistogramVec( //nolint:gochecknoglobals // metric
+		prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP requests latency by method and route template",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"method", "route"},
+	)
+	AccountsCreated = promauto.With(Registry).NewCounter( //nolint:gochecknoglobals // metric
+		prometheus.CounterOpts{
+			Name: "accounts_created_total",
+			Help: "Accounts created",
+		},
+	)
+	Transactions = promauto.With(Registry).NewCounterVec( //nolint:gochecknoglobals // metric
+		prometheus.CounterOpts{
+			Name: "transactions_total",
+			Help: "Transactions by operation type and outcome, the outcome is success or the error code",
+		},
+		[]string{"operation_type", "outcome"},
+	)
+)
+
+func init() { //nolint:gochecknoinits // registers the runtime collectors in the app registry
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// RegisterDB adds the connection pool gauges of the database, only the first
+// database opened with the same name is registered.
+func RegisterDB(sqlDB *sql.DB, name string) {
+	_ = Registry.Register(collectors.NewDBStatsCollector(sqlDB, name))
+}
+
+// Outcome returns the errorlib code of the error, to avoid high cardinality labels
+// with the error messages.
+func Outcome(err error) string {
+	if err == nil {
+		return OutcomeSuccess
+	}
+
+	var libErr *errorlib.Error
+	if errors.As(err, &libErr) {
+		return libErr.Code
+	}
+
+	return OutcomeError
+}
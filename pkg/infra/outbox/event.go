@@ -0,0 +1,30 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event is the envelope sent to the publishers, events are ordered per account.
+type Event struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	AccountID int64           `json:"account_id"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+	Attempts  int             `json:"-"`
+}
+
+func NewEvent(eventType string, accountID int64, payload any) (*Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Event{
+		Type:      eventType,
+		AccountID: accountID,
+		Payload:   data,
+		CreatedAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,128 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/infra/outbox/store.go
+//
+// Generated by this command:
+//
+//	mockgen -source ./pkg/infra/outbox/store.go -destination ./pkg/infra/outbox/mocks/store_mock.go
+//
+// Package mock_outbox is a generated GoMock package.
+package mock_outbox
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	outbox "github.com/rudineirk/pismo-challenge/pkg/infra/outbox"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStore is a mock of Store interface.
+type MockStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockStoreMockRecorder
+}
+
+// MockStoreMockRecorder is the mock recorder for MockStore.
+type MockStoreMockRecorder struct {
+	mock *MockStore
+}
+
+// NewMockStore creates a new mock instance.
+func NewMockStore(ctrl *gomock.Controller) *MockStore {
+	mock := &MockStore{ctrl: ctrl}
+	mock.recorder = &MockStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStore) EXPECT() *MockStoreMockRecorder {
+	return m.recorder
+}
+
+// AddEvent mocks base method.
+func (m *MockStore) AddEvent(arg0 context.Context, arg1 *outbox.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddEvent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddEvent indicates an expected call of AddEvent.
+func (mr *MockStoreMockRecorder) AddEvent(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddEvent", reflect.TypeOf((*MockStore)(nil).AddEvent), arg0, arg1)
+}
+
+// ListPendingEvents mocks base method.
+func (m *MockStore) ListPendingEvents(ctx context.Context, limit int) ([]*outbox.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingEvents", ctx, limit)
+	ret0, _ := ret[0].([]*outbox.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingEvents indicates an expected call of ListPendingEvents.
+func (mr *MockStoreMockRecorder) ListPendingEvents(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingEvents", reflect.TypeOf((*MockStore)(nil).ListPendingEvents), ctx, limit)
+}
+
+// MarkEventDead mocks base method.
+func (m *MockStore) MarkEventDead(ctx context.Context, id int64, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEventDead", ctx, id, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkEventDead indicates an expected call of MarkEventDead.
+func (mr *MockStoreMockRecorder) MarkEventDead(ctx, id, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEventDead", reflect.TypeOf((*MockStore)(nil).MarkEventDead), ctx, id, reason)
+}
+
+// MarkEventFailed mocks base method.
+func (m *MockStore) MarkEventFailed(ctx context.Context, id int64, reason string, retryAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEventFailed", ctx, id, reason, retryAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkEventFailed indicates an expected call of MarkEventFailed.
+func (mr *MockStoreMockRecorder) MarkEventFailed(ctx, id, reason, retryAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEventFailed", reflect.TypeOf((*MockStore)(nil).MarkEventFailed), ctx, id, reason, retryAt)
+}
+
+// MarkEventPublished mocks base method.
+func (m *MockStore) MarkEventPublished(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEventPublished", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkEventPublished indicates an expected call of MarkEventPublished.
+func (mr *MockStoreMockRecorder) MarkEventPublished(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEventPublished", reflect.TypeOf((*MockStore)(nil).MarkEventPublished), ctx, id)
+}
+
+// TryLockRelay mocks base method.
+func (m *MockStore) TryLockRelay(arg0 context.Context) (func(), bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TryLockRelay", arg0)
+	ret0, _ := ret[0].(func())
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// TryLockRelay indicates an expected call of TryLockRelay.
+func (mr *MockStoreMockRecorder) TryLockRelay(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TryLockRelay", reflect.TypeOf((*MockStore)(nil).TryLockRelay), arg0)
+}
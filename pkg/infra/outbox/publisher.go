@@ -0,0 +1,192 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const (
+	MemoryPublisherType = "memory"
+	FilePublisherType   = "file"
+	HTTPPublisherType   = "http"
+	NonePublisherType   = "none"
+
+	EventIDHeader   = "X-Event-ID"
+	EventTypeHeader = "X-Event-Type"
+
+	httpPublisherTimeout = 10 * time.Second
+)
+
+type Publisher interface {
+	Publish(context.Context, *Event) error
+	Close() error
+}
+
+// NewPublisher builds the publisher from its type name, the "none" type returns a nil publisher.
+func NewPublisher(publisherType string, filePath string, httpURL string) (Publisher, error) {
+	switch publisherType {
+	case MemoryPublisherType:
+		return NewMemoryPublisher(), nil
+	case FilePublisherType:
+		return NewFilePublisher(filePath)
+	case HTTPPublisherType:
+		if httpURL == "" {
+			return nil, fmt.Errorf("outbox http publisher requires an URL")
+		}
+
+		return NewHTTPPublisher(httpURL, &http.Client{
+			Timeout:   httpPublisherTimeout,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		}), nil
+	case NonePublisherType, "":
+		return nil, nil //nolint:nilnil // publishing disabled
+	default:
+		return nil, fmt.Errorf("unknown outbox publisher: %s", publisherType)
+	}
+}
+
+// MultiPublisher publishes each event to all the publishers in order, when one of them
+// fails the event is retried on all of them, so they must handle duplicated events.
+type MultiPublisher struct {
+	publishers []Publisher
+}
+
+func NewMultiPublisher(publishers ...Publisher) *MultiPublisher {
+	return &MultiPublisher{publishers: publishers}
+}
+
+func (publisher *MultiPublisher) Publish(ctx context.Context, event *Event) error {
+	for _, item := range publisher.publishers {
+		if err := item.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (publisher *MultiPublisher) Close() error {
+	errs := []error{}
+	for _, item := range publisher.publishers {
+		errs = append(errs, item.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+type MemoryPublisher struct {
+	mutex  sync.Mutex
+	events []*Event
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (publisher *MemoryPublisher) Publish(_ context.Context, event *Event) error {
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+
+	publisher.events = append(publisher.events, event)
+
+	return nil
+}
+
+func (publisher *MemoryPublisher) Events() []*Event {
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+
+	return append([]*Event{}, publisher.events...)
+}
+
+func (publisher *MemoryPublisher) Close() error {
+	return nil
+}
+
+// FilePublisher appends the events to a newline delimited JSON file.
+type FilePublisher struct {
+	mutex sync.Mutex
+	file  *os.File
+}
+
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644) //nolint:gomnd // file mode
+	if err != nil {
+		return nil, err
+	}
+
+	return &FilePublisher{file: file}, nil
+}
+
+func (publisher *FilePublisher) Publish(_ context.Context, event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+
+	if _, err := publisher.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	return publisher.file.Sync()
+}
+
+func (publisher *FilePublisher) Close() error {
+	return publisher.file.Close()
+}
+
+// HTTPPublisher sends each event as a JSON POST request, any non 2xx response is a failure.
+type HTTPPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPPublisher(url string, client *http.Client) *HTTPPublisher {
+	return &HTTPPublisher{url: url, client: client}
+}
+
+func (publisher *HTTPPublisher) Publish(ctx context.Context, event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, publisher.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventIDHeader, strconv.FormatInt(event.ID, 10))
+	req.Header.Set(EventTypeHeader, event.Type)
+
+	resp, err := publisher.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("outbox http publisher: unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (publisher *HTTPPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,143 @@
+package outbox_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rudineirk/pismo-challenge/pkg/infra/outbox"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestNewPublisher(t *testing.T) {
+	t.Run("should create the publisher by type", func(t *testing.T) {
+		publisher, err := outbox.NewPublisher(outbox.MemoryPublisherType, "", "")
+		assert.NoError(t, err)
+		assert.IsType(t, &outbox.MemoryPublisher{}, publisher)
+
+		publisher, err = outbox.NewPublisher(outbox.HTTPPublisherType, "", "http://localhost")
+		assert.NoError(t, err)
+		assert.IsType(t, &outbox.HTTPPublisher{}, publisher)
+
+		publisher, err = outbox.NewPublisher(outbox.NonePublisherType, "", "")
+		assert.NoError(t, err)
+		assert.Nil(t, publisher)
+	})
+
+	t.Run("should return error if the publisher config is invalid", func(t *testing.T) {
+		_, err := outbox.NewPublisher("kafka", "", "")
+		assert.Error(t, err)
+
+		_, err = outbox.NewPublisher(outbox.HTTPPublisherType, "", "")
+		assert.Error(t, err)
+	})
+}
+
+func TestMemoryPublisher(t *testing.T) {
+	publisher := outbox.NewMemoryPublisher()
+	event := newEvent(t, 1, 1)
+
+	assert.NoError(t, publisher.Publish(context.TODO(), event))
+	assert.Equal(t, []*outbox.Event{event}, publisher.Events())
+}
+
+func TestMultiPublisher(t *testing.T) {
+	t.Run("should publish to all the publishers", func(t *testing.T) {
+		first := outbox.NewMemoryPublisher()
+		second := outbox.NewMemoryPublisher()
+		publisher := outbox.NewMultiPublisher(first, second)
+		event := newEvent(t, 1, 1)
+
+		assert.NoError(t, publisher.Publish(context.TODO(), event))
+		assert.Equal(t, []*outbox.Event{event}, first.Events())
+		assert.Equal(t, []*outbox.Event{event}, second.Events())
+		assert.NoError(t, publisher.Close())
+	})
+
+	t.Run("should return error if a publisher fails", func(t *testing.T) {
+		second := outbox.NewMemoryPublisher()
+		publisher := outbox.NewMultiPublisher(&failingPublisher{failIDs: map[int64]bool{1: true}}, second)
+
+		assert.Error(t, publisher.Publish(context.TODO(), newEvent(t, 1, 1)))
+		assert.Empty(t, second.Events())
+	})
+}
+
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+
+	publisher, err := outbox.NewFilePublisher(path)
+	assert.NoError(t, err)
+
+	assert.NoError(t, publisher.Publish(context.TODO(), newEvent(t, 1, 1)))
+	assert.NoError(t, publisher.Publish(context.TODO(), newEvent(t, 2, 1)))
+	assert.NoError(t, publisher.Close())
+
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+
+	defer file.Close()
+
+	ids := []int64{}
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		event := outbox.Event{}
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		ids = append(ids, event.ID)
+	}
+
+	assert.Equal(t, []int64{1, 2}, ids)
+}
+
+func TestHTTPPublisher(t *testing.T) {
+	t.Run("should post the event as JSON", func(t *testing.T) {
+		var received outbox.Event
+		var headers http.Header
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers = r.Header
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &received)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		publisher := outbox.NewHTTPPublisher(server.URL, server.Client())
+		event := newEvent(t, 5, 1)
+
+		assert.NoError(t, publisher.Publish(context.TODO(), event))
+		assert.Equal(t, int64(5), received.ID)
+		assert.Equal(t, "5", headers.Get(outbox.EventIDHeader))
+		assert.Equal(t, event.Type, headers.Get(outbox.EventTypeHeader))
+		assert.JSONEq(t, string(event.Payload), string(received.Payload))
+	})
+
+	t.Run("should return error if the response is not successful", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		publisher := outbox.NewHTTPPublisher(server.URL, server.Client())
+
+		assert.Error(t, publisher.Publish(context.TODO(), newEvent(t, 1, 1)))
+	})
+}
+
+func newEvent(t *testing.T, id int64, accountID int64) *outbox.Event {
+	t.Helper()
+
+	event, err := outbox.NewEvent("TestEvent", accountID, map[string]any{"id": id})
+	assert.NoError(t, err)
+
+	event.ID = id
+
+	return event
+}
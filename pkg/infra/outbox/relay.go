@@ -0,0 +1,138 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultRelayBatchSize   = 100
+	defaultRelayMaxAttempts = 10
+	relayBaseBackoff        = time.Second
+	relayMaxBackoff         = 10 * time.Minute
+)
+
+// Relay publishes the pending outbox events with at-least-once delivery: an
+// event is only marked as published after the publisher accepts it.
+type Relay struct {
+	store       Store
+	publisher   Publisher
+	logger      *zerolog.Logger
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+}
+
+func NewRelay(store Store, publisher Publisher, logger *zerolog.Logger, interval time.Duration) *Relay {
+	return &Relay{
+		store:       store,
+		publisher:   publisher,
+		logger:      logger,
+		interval:    interval,
+		batchSize:   defaultRelayBatchSize,
+		maxAttempts: defaultRelayMaxAttempts,
+	}
+}
+
+// Run polls the outbox until the context is canceled.
+func (relay *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(relay.interval)
+	defer ticker.Stop()
+
+	for {
+		published, err := relay.PublishPending(ctx)
+		if err != nil && ctx.Err() == nil {
+			relay.logger.Err(err).Msg("Failed to publish outbox events")
+		}
+
+		if published == relay.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PublishPending publishes a batch of pending events in order. When an event
+// fails the following events of the same account are held until its retry,
+// so the events of an account are never published out of order. The events of
+// an account are written while holding the account row lock, so their ids
+// follow the commit order. Each event is marked right after it's published,
+// outside of a transaction, so a failure doesn't undo the marks of the others.
+func (relay *Relay) PublishPending(ctx context.Context) (int, error) {
+	release, locked, err := relay.store.TryLockRelay(ctx)
+	if err != nil || !locked {
+		return 0, err
+	}
+
+	defer release()
+
+	events, err := relay.store.ListPendingEvents(ctx, relay.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	heldAccounts := map[int64]bool{}
+	errs := []error{}
+
+	for _, event := range events {
+		if heldAccounts[event.AccountID] {
+			continue
+		}
+
+		if err := relay.publisher.Publish(ctx, event); err != nil {
+			if err := relay.markEventFailed(ctx, event, err, heldAccounts); err != nil {
+				errs = append(errs, err)
+			}
+
+			continue
+		}
+
+		if err := relay.store.MarkEventPublished(ctx, event.ID); err != nil {
+			// the event is published again in the next run, before the following events of the account
+			heldAccounts[event.AccountID] = true
+			errs = append(errs, err)
+
+			continue
+		}
+
+		published++
+	}
+
+	return published, errors.Join(errs...)
+}
+
+// markEventFailed schedules the retry of the event with an exponential backoff, holding the
+// following events of the account. After the max attempts the event is dead, so it doesn't
+// block the account forever.
+func (relay *Relay) markEventFailed(ctx context.Context, event *Event, err error, heldAccounts map[int64]bool) error {
+	attempts := event.Attempts + 1
+	if attempts >= relay.maxAttempts {
+		relay.logger.Error().Err(err).Int64("event_id", event.ID).Msg("Outbox event dead after the max attempts")
+
+		return relay.store.MarkEventDead(ctx, event.ID, err.Error())
+	}
+
+	heldAccounts[event.AccountID] = true
+
+	relay.logger.Warn().Err(err).Int64("event_id", event.ID).Msg("Failed to publish outbox event")
+
+	return relay.store.MarkEventFailed(ctx, event.ID, err.Error(), time.Now().Add(retryBackoff(attempts)))
+}
+
+func retryBackoff(attempts int) time.Duration {
+	backoff := relayBaseBackoff
+	for i := 1; i < attempts && backoff < relayMaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, relayMaxBackoff)
+}
@@ -0,0 +1,144 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rudineirk/pismo-challenge/pkg/infra/logger"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/outbox"
+	mocks "github.com/rudineirk/pismo-challenge/pkg/infra/outbox/mocks"
+	assert "github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+)
+
+type failingPublisher struct {
+	outbox.MemoryPublisher
+	failIDs map[int64]bool
+}
+
+func (publisher *failingPublisher) Publish(ctx context.Context, event *outbox.Event) error {
+	if publisher.failIDs[event.ID] {
+		return errors.New("publish error")
+	}
+
+	return publisher.MemoryPublisher.Publish(ctx, event)
+}
+
+func TestRelayPublishPending(t *testing.T) {
+	setupMocks := func(t *testing.T) *mocks.MockStore {
+		mockCtrl := gomock.NewController(t)
+
+		return mocks.NewMockStore(mockCtrl)
+	}
+
+	expectLock := func(t *testing.T, store *mocks.MockStore) {
+		released := false
+
+		store.EXPECT().TryLockRelay(gomock.Any()).Return(func() { released = true }, true, nil)
+		t.Cleanup(func() { assert.True(t, released) })
+	}
+
+	t.Run("should publish the pending events in order", func(t *testing.T) {
+		store := setupMocks(t)
+		publisher := outbox.NewMemoryPublisher()
+		relay := outbox.NewRelay(store, publisher, logger.NewStubLogger(), time.Second)
+		events := []*outbox.Event{newEvent(t, 1, 1), newEvent(t, 2, 2), newEvent(t, 3, 1)}
+
+		expectLock(t, store)
+		store.EXPECT().ListPendingEvents(gomock.Any(), gomock.Any()).Return(events, nil)
+		for _, event := range events {
+			store.EXPECT().MarkEventPublished(gomock.Any(), event.ID).Return(nil)
+		}
+
+		published, err := relay.PublishPending(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, 3, published)
+		assert.Equal(t, events, publisher.Events())
+	})
+
+	t.Run("should hold the following events of an account after a failure", func(t *testing.T) {
+		store := setupMocks(t)
+		publisher := &failingPublisher{failIDs: map[int64]bool{1: true}}
+		relay := outbox.NewRelay(store, publisher, logger.NewStubLogger(), time.Second)
+		events := []*outbox.Event{newEvent(t, 1, 1), newEvent(t, 2, 2), newEvent(t, 3, 1)}
+
+		expectLock(t, store)
+		store.EXPECT().ListPendingEvents(gomock.Any(), gomock.Any()).Return(events, nil)
+		store.EXPECT().MarkEventFailed(gomock.Any(), int64(1), "publish error", gomock.Any()).Return(nil)
+		store.EXPECT().MarkEventPublished(gomock.Any(), int64(2)).Return(nil)
+
+		published, err := relay.PublishPending(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, published)
+		assert.Equal(t, []*outbox.Event{events[1]}, publisher.Events())
+	})
+
+	t.Run("should retry the failed events with backoff", func(t *testing.T) {
+		store := setupMocks(t)
+		publisher := &failingPublisher{failIDs: map[int64]bool{1: true}}
+		relay := outbox.NewRelay(store, publisher, logger.NewStubLogger(), time.Second)
+		event := newEvent(t, 1, 1)
+		event.Attempts = 3
+
+		expectLock(t, store)
+		store.EXPECT().ListPendingEvents(gomock.Any(), gomock.Any()).Return([]*outbox.Event{event}, nil)
+		store.EXPECT().
+			MarkEventFailed(gomock.Any(), int64(1), "publish error", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ int64, _ string, retryAt time.Time) error {
+				assert.WithinDuration(t, time.Now().Add(8*time.Second), retryAt, time.Second)
+				return nil
+			})
+
+		_, err := relay.PublishPending(context.TODO())
+		assert.NoError(t, err)
+	})
+
+	t.Run("should stop retrying an event after the max attempts", func(t *testing.T) {
+		store := setupMocks(t)
+		publisher := &failingPublisher{failIDs: map[int64]bool{1: true}}
+		relay := outbox.NewRelay(store, publisher, logger.NewStubLogger(), time.Second)
+		events := []*outbox.Event{newEvent(t, 1, 1), newEvent(t, 2, 1)}
+		events[0].Attempts = 9
+
+		expectLock(t, store)
+		store.EXPECT().ListPendingEvents(gomock.Any(), gomock.Any()).Return(events, nil)
+		store.EXPECT().MarkEventDead(gomock.Any(), int64(1), "publish error").Return(nil)
+		store.EXPECT().MarkEventPublished(gomock.Any(), int64(2)).Return(nil)
+
+		published, err := relay.PublishPending(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, published)
+		assert.Equal(t, []*outbox.Event{events[1]}, publisher.Events())
+	})
+
+	t.Run("should keep the published events if an event can't be marked", func(t *testing.T) {
+		store := setupMocks(t)
+		publisher := outbox.NewMemoryPublisher()
+		relay := outbox.NewRelay(store, publisher, logger.NewStubLogger(), time.Second)
+		events := []*outbox.Event{newEvent(t, 1, 1), newEvent(t, 2, 2), newEvent(t, 3, 1)}
+
+		expectLock(t, store)
+		store.EXPECT().ListPendingEvents(gomock.Any(), gomock.Any()).Return(events, nil)
+		store.EXPECT().MarkEventPublished(gomock.Any(), int64(1)).Return(errors.New("db error"))
+		store.EXPECT().MarkEventPublished(gomock.Any(), int64(2)).Return(nil)
+
+		published, err := relay.PublishPending(context.TODO())
+		assert.ErrorContains(t, err, "db error")
+		assert.Equal(t, 1, published)
+		assert.Equal(t, events[:2], publisher.Events())
+	})
+
+	t.Run("should skip the batch if another relay holds the lock", func(t *testing.T) {
+		store := setupMocks(t)
+		relay := outbox.NewRelay(store, outbox.NewMemoryPublisher(), logger.NewStubLogger(), time.Second)
+
+		store.EXPECT().TryLockRelay(gomock.Any()).Return(nil, false, nil)
+
+		published, err := relay.PublishPending(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, published)
+	})
+}
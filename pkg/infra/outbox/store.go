@@ -0,0 +1,182 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/rudineirk/pismo-challenge/pkg/infra/database"
+	"github.com/uptrace/bun"
+)
+
+// relayLockKey is the advisory lock key held by the active relay, only one
+// relay publishes at a time so the events order is kept between instances.
+const relayLockKey = 72110101
+
+type Store interface {
+	AddEvent(context.Context, *Event) error
+	TryLockRelay(context.Context) (release func(), locked bool, err error)
+	ListPendingEvents(ctx context.Context, limit int) ([]*Event, error)
+	MarkEventPublished(ctx context.Context, id int64) error
+	MarkEventFailed(ctx context.Context, id int64, reason string, retryAt time.Time) error
+	MarkEventDead(ctx context.Context, id int64, reason string) error
+}
+
+type EventModel struct {
+	bun.BaseModel `bun:"table:outbox_events"`
+	ID            int64           `bun:"id,pk,autoincrement"`
+	EventType     string          `bun:"event_type"`
+	AccountID     int64           `bun:"account_id"`
+	Payload       json.RawMessage `bun:"payload,type:jsonb"`
+	Attempts      int             `bun:"attempts"`
+	LastError     string          `bun:"last_error,nullzero"`
+	CreatedAt     time.Time       `bun:"created_at"`
+	PublishedAt   bun.NullTime    `bun:"published_at"`
+	NextAttemptAt bun.NullTime    `bun:"next_attempt_at"`
+	DeadAt        bun.NullTime    `bun:"dead_at"`
+}
+
+func NewModelFromEntity(event *Event) *EventModel {
+	return &EventModel{
+		ID:        event.ID,
+		EventType: event.Type,
+		AccountID: event.AccountID,
+		Payload:   event.Payload,
+		CreatedAt: event.CreatedAt,
+	}
+}
+
+func (model *EventModel) ToEntity() *Event {
+	return &Event{
+		ID:        model.ID,
+		Type:      model.EventType,
+		AccountID: model.AccountID,
+		Payload:   model.Payload,
+		CreatedAt: model.CreatedAt,
+		Attempts:  model.Attempts,
+	}
+}
+
+type dbStore struct {
+	bunDB *bun.DB
+}
+
+func NewStore(bunDB *bun.DB) Store {
+	return &dbStore{bunDB}
+}
+
+// AddEvent must be called inside the same transaction of the change that generated the event.
+func (store *dbStore) AddEvent(ctx context.Context, event *Event) error {
+	eventModel := NewModelFromEntity(event)
+
+	_, err := database.Conn(ctx, store.bunDB).NewInsert().
+		Model(eventModel).
+		Exec(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	event.ID = eventModel.ID
+
+	return nil
+}
+
+// TryLockRelay takes a session advisory lock on a dedicated connection, so the events can be
+// published and marked outside of a transaction while the lock is held. The release function
+// must be called to unlock and return the connection to the pool.
+func (store *dbStore) TryLockRelay(ctx context.Context) (func(), bool, error) {
+	conn, err := store.bunDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var locked bool
+
+	err = conn.NewSelect().
+		ColumnExpr("pg_try_advisory_lock(?)", relayLockKey).
+		Scan(ctx, &locked)
+
+	if err != nil || !locked {
+		conn.Close()
+		return nil, false, err
+	}
+
+	release := func() {
+		// the lock is released even if the context was canceled
+		_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(?)", relayLockKey)
+		conn.Close()
+	}
+
+	return release, true, nil
+}
+
+// ListPendingEvents skips the accounts waiting to retry a failed event, so their following
+// events don't fill the batches while the other accounts wait.
+func (store *dbStore) ListPendingEvents(ctx context.Context, limit int) ([]*Event, error) {
+	eventModels := []*EventModel{}
+	now := time.Now()
+
+	err := database.Conn(ctx, store.bunDB).NewSelect().
+		Model(&eventModels).
+		Where("published_at IS NULL").
+		Where("dead_at IS NULL").
+		Where(`NOT EXISTS (
+			SELECT 1 FROM outbox_events AS failed
+			WHERE failed.account_id = "event_model".account_id
+				AND failed.id <= "event_model".id
+				AND failed.published_at IS NULL
+				AND failed.dead_at IS NULL
+				AND failed.next_attempt_at > ?
+		)`, now).
+		Order("id").
+		Limit(limit).
+		Scan(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*Event, 0, len(eventModels))
+	for _, model := range eventModels {
+		events = append(events, model.ToEntity())
+	}
+
+	return events, nil
+}
+
+func (store *dbStore) MarkEventPublished(ctx context.Context, id int64) error {
+	_, err := database.Conn(ctx, store.bunDB).NewUpdate().
+		Model((*EventModel)(nil)).
+		Set("published_at = ?", time.Now()).
+		Set("attempts = attempts + 1").
+		Where("id = ?", id).
+		Exec(ctx)
+
+	return err
+}
+
+func (store *dbStore) MarkEventFailed(ctx context.Context, id int64, reason string, retryAt time.Time) error {
+	_, err := database.Conn(ctx, store.bunDB).NewUpdate().
+		Model((*EventModel)(nil)).
+		Set("last_error = ?", reason).
+		Set("attempts = attempts + 1").
+		Set("next_attempt_at = ?", retryAt).
+		Where("id = ?", id).
+		Exec(ctx)
+
+	return err
+}
+
+// MarkEventDead stops retrying the event, the following events of the account are published.
+func (store *dbStore) MarkEventDead(ctx context.Context, id int64, reason string) error {
+	_, err := database.Conn(ctx, store.bunDB).NewUpdate().
+		Model((*EventModel)(nil)).
+		Set("last_error = ?", reason).
+		Set("attempts = attempts + 1").
+		Set("dead_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+
+	return err
+}
@@ -0,0 +1,77 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/httprouter"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/logger"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/tracing"
+	assert "github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestSetup(t *testing.T) {
+	t.Run("should setup the exporters", func(t *testing.T) {
+		for _, exporter := range []string{tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP} {
+			shutdown, err := tracing.Setup(context.Background(), exporter)
+			assert.NoError(t, err)
+			assert.NoError(t, shutdown(context.Background()))
+		}
+	})
+
+	t.Run("should return error if the exporter is invalid", func(t *testing.T) {
+		_, err := tracing.Setup(context.Background(), "zipkin")
+		assert.ErrorIs(t, err, tracing.ErrInvalidExporter)
+	})
+}
+
+func TestTracing(t *testing.T) {
+	_, err := tracing.Setup(context.Background(), tracing.ExporterNone)
+	assert.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	t.Run("should continue the incoming trace in the request spans and logs", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		log := zerolog.New(output).Hook(logger.TracingHook{})
+
+		router := httprouter.NewRouter(&log, true)
+		router.GET("/accounts/:account_id", func(ctx *gin.Context) {
+			_, span := tracing.StartSpan(ctx, "accounts.GetAccountByID")
+			tracing.EndSpan(span, errors.New("not found"))
+
+			ctx.Status(http.StatusNotFound)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/accounts/1", nil)
+		req.Header.Set("traceparent", traceparent)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		spans := recorder.Ended()
+		assert.Len(t, spans, 2)
+
+		serviceSpan, requestSpan := spans[0], spans[1]
+		assert.Equal(t, "accounts.GetAccountByID", serviceSpan.Name())
+		assert.Equal(t, codes.Error, serviceSpan.Status().Code)
+		assert.Equal(t, requestSpan.SpanContext().SpanID(), serviceSpan.Parent().SpanID())
+
+		assert.Equal(t, "/accounts/:account_id", requestSpan.Name())
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", requestSpan.SpanContext().TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", requestSpan.Parent().SpanID().String())
+
+		assert.Contains(t, output.String(), `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`)
+	})
+}
@@ -1,31 +1,36 @@
 package errorlib
 
 import (
+	"net/http"
 	"runtime"
 	"strings"
 
 	"errors"
 )
 
-var (
-	ErrNotFound       = NewError("not_found", "entity not found")      //nolint:gochecknoglobals // error maker
-	ErrDuplicated     = NewError("duplicated", "duplicated entity")    //nolint:gochecknoglobals // error maker
-	ErrInvalidPayload = NewError("invalid_payload", "invalid payload") //nolint:gochecknoglobals // error maker
+var ( //nolint:gochecknoglobals // error makers
+	ErrNotFound       = NewError("not_found", "entity not found", http.StatusNotFound)
+	ErrDuplicated     = NewError("duplicated", "duplicated entity", http.StatusConflict)
+	ErrInvalidPayload = NewError("invalid_payload", "invalid payload", http.StatusBadRequest)
+	ErrInternal       = NewError("internal_error", "internal error", http.StatusInternalServerError)
 )
 
 type Error struct {
 	Code       string          `json:"code"`
 	Message    string          `json:"message"`
+	Status     int             `json:"-"`
 	err        error           `json:"-"`
 	wrappedErr error           `json:"-"`
 	stack      []runtime.Frame `json:"-"`
 }
 
-func NewError(code string, msg string) func(err error) *Error {
+// NewError creates an error maker, the status is the HTTP status code of the error responses.
+func NewError(code string, msg string, status int) func(err error) *Error {
 	return func(err error) *Error {
 		return &Error{
 			Code:       code,
 			Message:    msg,
+			Status:     status,
 			wrappedErr: err,
 			err:        errors.Join(errors.New(msg), err), //nolint:goerr113 // used to make constant/global errors
 			stack:      getStack(),
@@ -3,6 +3,7 @@ package errorlib_test
 import (
 	"database/sql"
 	"errors"
+	"net/http"
 	"strings"
 	"testing"
 
@@ -12,8 +13,8 @@ import (
 
 func TestError(t *testing.T) {
 	t.Run("should create a custom errors", func(t *testing.T) {
-		customErr := errorlib.NewError("new_error_code", "new error code")
-		otherErr := errorlib.NewError("other_error_code", "other error code")
+		customErr := errorlib.NewError("new_error_code", "new error code", http.StatusBadRequest)
+		otherErr := errorlib.NewError("other_error_code", "other error code", http.StatusConflict)
 
 		err := customErr(errors.New("wrapped error"))
 		err2 := otherErr(nil)
@@ -21,6 +22,8 @@ func TestError(t *testing.T) {
 		assert.True(t, errors.Is(err, customErr(nil)))
 		assert.False(t, errors.Is(customErr(nil), errors.New("new error code")))
 		assert.False(t, errors.Is(err2, customErr(nil)))
+		assert.Equal(t, http.StatusBadRequest, err.Status)
+		assert.Equal(t, http.StatusConflict, err2.Status)
 	})
 
 	t.Run("should return full error string", func(t *testing.T) {
@@ -0,0 +1,10 @@
+package money
+
+import "strings"
+
+// DefaultCurrency is the ISO 4217 currency used when an account doesn't set one.
+const DefaultCurrency = "BRL"
+
+func NormalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
@@ -0,0 +1,189 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
+)
+
+const decimalPlaces = 2
+
+var ErrInvalidMoney = errors.New("invalid money value") //nolint:gochecknoglobals // constant error
+
+var hundred = decimal.NewFromInt(100) //nolint:gochecknoglobals // constant value
+
+type Money struct {
+	value decimal.Decimal
+}
+
+func New(value decimal.Decimal) Money {
+	return Money{value: value}
+}
+
+func NewFromInt(value int64) Money {
+	return Money{value: decimal.NewFromInt(value)}
+}
+
+func NewFromFloat(value float64) Money {
+	return Money{value: decimal.NewFromFloat(value)}
+}
+
+func NewFromString(value string) (Money, error) {
+	parsed, err := decimal.NewFromString(value)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %w", ErrInvalidMoney, err)
+	}
+
+	return Money{value: parsed}, nil
+}
+
+func MustParse(value string) Money {
+	parsed, err := NewFromString(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return parsed
+}
+
+func Min(first Money, second Money) Money {
+	return Money{value: decimal.Min(first.value, second.value)}
+}
+
+func (m Money) Add(other Money) Money {
+	return Money{value: m.value.Add(other.value)}
+}
+
+func (m Money) Sub(other Money) Money {
+	return Money{value: m.value.Sub(other.value)}
+}
+
+func (m Money) Neg() Money {
+	return Money{value: m.value.Neg()}
+}
+
+func (m Money) Abs() Money {
+	return Money{value: m.value.Abs()}
+}
+
+// Convert multiplies the value by an exchange rate, rounding it half away from zero to cents.
+func (m Money) Convert(rate decimal.Decimal) Money {
+	return Money{value: m.value.Mul(rate).Round(decimalPlaces)}
+}
+
+// Split divides the value in parts with cents precision, the rounding remainder goes to the first part.
+func (m Money) Split(parts int) []Money {
+	cents := m.value.Mul(hundred).Round(0).IntPart()
+	base := cents / int64(parts)
+	remainder := cents - base*int64(parts)
+
+	result := make([]Money, 0, parts)
+	for i := 0; i < parts; i++ {
+		part := base
+		if i == 0 {
+			part += remainder
+		}
+
+		result = append(result, Money{value: decimal.New(part, -decimalPlaces)})
+	}
+
+	return result
+}
+
+func (m Money) Cmp(other Money) int {
+	return m.value.Cmp(other.value)
+}
+
+func (m Money) Equal(other Money) bool {
+	return m.value.Equal(other.value)
+}
+
+func (m Money) GreaterThan(other Money) bool {
+	return m.value.GreaterThan(other.value)
+}
+
+func (m Money) LessThan(other Money) bool {
+	return m.value.LessThan(other.value)
+}
+
+func (m Money) IsZero() bool {
+	return m.value.IsZero()
+}
+
+func (m Money) IsPositive() bool {
+	return m.value.IsPositive()
+}
+
+func (m Money) IsNegative() bool {
+	return m.value.IsNegative()
+}
+
+func (m Money) Sign() int {
+	return m.value.Sign()
+}
+
+// HasCentsPrecision reports if the value has at most two decimal places.
+func (m Money) HasCentsPrecision() bool {
+	return m.value.Mul(hundred).Mod(decimal.NewFromInt(1)).IsZero()
+}
+
+func (m Money) Decimal() decimal.Decimal {
+	return m.value
+}
+
+func (m Money) Float64() float64 {
+	return m.value.InexactFloat64()
+}
+
+func (m Money) String() string {
+	return m.value.StringFixed(decimalPlaces)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts both JSON numbers and strings, like 10.5 or "10.50".
+func (m *Money) UnmarshalJSON(data []byte) error {
+	raw := string(data)
+	if raw == "null" {
+		return nil
+	}
+
+	if len(raw) >= 2 && strings.HasPrefix(raw, `"`) && strings.HasSuffix(raw, `"`) {
+		raw = raw[1 : len(raw)-1]
+	}
+
+	parsed, err := NewFromString(raw)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+
+	return nil
+}
+
+func (m *Money) Scan(src any) error {
+	return m.value.Scan(src)
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return m.value.String(), nil
+}
+
+// RegisterValidatorType allows using validator tags like required and gte on Money fields.
+func RegisterValidatorType(validate *validator.Validate) {
+	validate.RegisterCustomTypeFunc(func(field reflect.Value) any {
+		if value, ok := field.Interface().(Money); ok {
+			return value.Float64()
+		}
+
+		return nil
+	}, Money{})
+}
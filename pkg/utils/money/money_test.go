@@ -0,0 +1,125 @@
+package money_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/shopspring/decimal"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestMoneyJSON(t *testing.T) {
+	t.Run("should decode numbers and strings", func(t *testing.T) {
+		for _, data := range [][]string{
+			{`10.5`, "10.50"},
+			{`"10.5"`, "10.50"},
+			{`-0.01`, "-0.01"},
+			{`"-1000"`, "-1000.00"},
+			{`0.1`, "0.10"},
+		} {
+			value := money.Money{}
+			err := json.Unmarshal([]byte(data[0]), &value)
+			assert.NoError(t, err)
+			assert.Equal(t, data[1], value.String())
+		}
+	})
+
+	t.Run("should return error if value is invalid", func(t *testing.T) {
+		for _, data := range []string{`"abc"`, `true`, `""`, `{}`} {
+			value := money.Money{}
+			err := json.Unmarshal([]byte(data), &value)
+			assert.Error(t, err)
+		}
+	})
+
+	t.Run("should encode with two decimal places", func(t *testing.T) {
+		data, err := json.Marshal(map[string]money.Money{
+			"a": money.MustParse("10.5"),
+			"b": money.MustParse("-0.3"),
+			"c": money.NewFromInt(7),
+		})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"a":10.50,"b":-0.30,"c":7.00}`, string(data))
+		assert.Contains(t, string(data), `"a":10.50`)
+	})
+
+	t.Run("should keep exact values", func(t *testing.T) {
+		sum := money.MustParse("0.1").Add(money.MustParse("0.2"))
+		assert.True(t, sum.Equal(money.MustParse("0.3")))
+	})
+}
+
+func TestMoneySQL(t *testing.T) {
+	t.Run("should scan numeric values", func(t *testing.T) {
+		value := money.Money{}
+
+		err := value.Scan([]byte("-1234.56"))
+		assert.NoError(t, err)
+		assert.True(t, value.Equal(money.MustParse("-1234.56")))
+
+		dbValue, err := value.Value()
+		assert.NoError(t, err)
+		assert.Equal(t, "-1234.56", dbValue)
+	})
+}
+
+func TestMoneyPrecision(t *testing.T) {
+	t.Run("should check cents precision", func(t *testing.T) {
+		assert.True(t, money.MustParse("10").HasCentsPrecision())
+		assert.True(t, money.MustParse("-10.01").HasCentsPrecision())
+		assert.False(t, money.MustParse("10.001").HasCentsPrecision())
+		assert.False(t, money.MustParse("-1.019").HasCentsPrecision())
+	})
+}
+
+func TestMoneyConvert(t *testing.T) {
+	t.Run("should convert and round to cents", func(t *testing.T) {
+		assert.Equal(t, "51.20", money.MustParse("10").Convert(decimal.RequireFromString("5.12")).String())
+		assert.Equal(t, "-5.37", money.MustParse("-1.07").Convert(decimal.RequireFromString("5.0231")).String())
+		assert.Equal(t, "0.01", money.MustParse("0.01").Convert(decimal.RequireFromString("0.5")).String())
+	})
+}
+
+func TestMoneySplit(t *testing.T) {
+	split := func(value string, parts int) []string {
+		result := []string{}
+		for _, part := range money.MustParse(value).Split(parts) {
+			result = append(result, part.String())
+		}
+
+		return result
+	}
+
+	t.Run("should split the value in equal parts", func(t *testing.T) {
+		assert.Equal(t, []string{"25.00", "25.00", "25.00", "25.00"}, split("100", 4))
+		assert.Equal(t, []string{"-10.50"}, split("-10.5", 1))
+	})
+
+	t.Run("should add the remainder to the first part", func(t *testing.T) {
+		assert.Equal(t, []string{"33.34", "33.33", "33.33"}, split("100", 3))
+		assert.Equal(t, []string{"-0.04", "-0.03", "-0.03"}, split("-0.10", 3))
+		assert.Equal(t, []string{"0.01", "0.00"}, split("0.01", 2))
+	})
+}
+
+func TestMoneyValidator(t *testing.T) {
+	type request struct {
+		Amount money.Money  `validate:"required"`
+		Limit  *money.Money `validate:"required,gte=0"`
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	money.RegisterValidatorType(validate)
+
+	zero := money.NewFromInt(0)
+	negative := money.NewFromInt(-1)
+
+	t.Run("should validate money fields", func(t *testing.T) {
+		assert.NoError(t, validate.Struct(&request{Amount: money.NewFromInt(-1), Limit: &zero}))
+		assert.Error(t, validate.Struct(&request{Amount: zero, Limit: &zero}))
+		assert.Error(t, validate.Struct(&request{Amount: money.NewFromInt(1)}))
+		assert.Error(t, validate.Struct(&request{Amount: money.NewFromInt(1), Limit: &negative}))
+	})
+}
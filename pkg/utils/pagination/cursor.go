@@ -0,0 +1,39 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 100
+)
+
+func EncodeCursor(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func DecodeCursor(cursor string, value any) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, value)
+}
+
+func NormalizeLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	} else if limit > MaxLimit {
+		return MaxLimit
+	}
+
+	return limit
+}
@@ -0,0 +1,50 @@
+package pagination_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rudineirk/pismo-challenge/pkg/utils/pagination"
+	assert "github.com/stretchr/testify/require"
+)
+
+type testCursor struct {
+	EventDate time.Time `json:"d"`
+	ID        int64     `json:"i"`
+}
+
+func TestCursor(t *testing.T) {
+	t.Run("should encode and decode a cursor", func(t *testing.T) {
+		cursor := testCursor{EventDate: time.Now().UTC(), ID: 15}
+
+		encoded, err := pagination.EncodeCursor(cursor)
+		assert.NoError(t, err)
+		assert.NotContains(t, encoded, "=")
+
+		decoded := testCursor{}
+		err = pagination.DecodeCursor(encoded, &decoded)
+		assert.NoError(t, err)
+
+		assert.Equal(t, cursor.ID, decoded.ID)
+		assert.True(t, cursor.EventDate.Equal(decoded.EventDate))
+	})
+
+	t.Run("should return error if cursor is invalid", func(t *testing.T) {
+		decoded := testCursor{}
+
+		assert.Error(t, pagination.DecodeCursor("not a cursor!", &decoded))
+		assert.Error(t, pagination.DecodeCursor("bm90LWpzb24", &decoded))
+	})
+}
+
+func TestNormalizeLimit(t *testing.T) {
+	t.Run("should use default limit if not set", func(t *testing.T) {
+		assert.Equal(t, pagination.DefaultLimit, pagination.NormalizeLimit(0))
+		assert.Equal(t, pagination.DefaultLimit, pagination.NormalizeLimit(-1))
+	})
+
+	t.Run("should cap limit to the max value", func(t *testing.T) {
+		assert.Equal(t, pagination.MaxLimit, pagination.NormalizeLimit(pagination.MaxLimit+1))
+		assert.Equal(t, 10, pagination.NormalizeLimit(10))
+	})
+}
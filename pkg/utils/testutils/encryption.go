@@ -0,0 +1,28 @@
+package testutils
+
+import (
+	"crypto/rand"
+
+	"github.com/rudineirk/pismo-challenge/pkg/infra/encryption"
+)
+
+// NewTestKeyring creates a keyring with random keys.
+func NewTestKeyring() *encryption.Keyring {
+	key := make([]byte, 32)
+	indexKey := make([]byte, 32)
+
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+
+	if _, err := rand.Read(indexKey); err != nil {
+		panic(err)
+	}
+
+	keyring, err := encryption.NewKeyring("test", map[string][]byte{"test": key}, indexKey)
+	if err != nil {
+		panic(err)
+	}
+
+	return keyring
+}
@@ -0,0 +1,114 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DecodeJSON decodes the JSON object into obj, an empty data returns io.EOF. encoding/json
+// doesn't add the field name to the errors of custom decoders, like money.Money, so the
+// fields are decoded one by one to find the invalid field.
+func DecodeJSON(data []byte, obj any) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return io.EOF
+	}
+
+	err := json.Unmarshal(data, obj)
+
+	var (
+		typeErr   *json.UnmarshalTypeError
+		syntaxErr *json.SyntaxError
+	)
+
+	if err == nil || errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return err
+	}
+
+	if fieldErr := findInvalidField(data, reflect.TypeOf(obj)); fieldErr != nil {
+		return fieldErr
+	}
+
+	return err
+}
+
+func findInvalidField(data []byte, objType reflect.Type) *json.UnmarshalTypeError {
+	if objType.Kind() != reflect.Pointer || objType.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fieldData, err := json.Marshal(map[string]json.RawMessage{name: fields[name]})
+		if err != nil {
+			return nil
+		}
+
+		if err := json.Unmarshal(fieldData, reflect.New(objType.Elem()).Interface()); err == nil {
+			continue
+		}
+
+		fieldType, ok := jsonFieldType(objType.Elem(), name)
+		if !ok {
+			return nil
+		}
+
+		return &json.UnmarshalTypeError{
+			Value: jsonValueName(fields[name]),
+			Type:  fieldType,
+			Field: name,
+		}
+	}
+
+	return nil
+}
+
+func jsonFieldType(structType reflect.Type, name string) (reflect.Type, bool) {
+	for idx := 0; idx < structType.NumField(); idx++ {
+		field := structType.Field(idx)
+		tagName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+		if tagName == "" {
+			tagName = field.Name
+		}
+
+		// encoding/json matches the keys without case sensitivity
+		if tagName != "-" && strings.EqualFold(tagName, name) {
+			return field.Type, true
+		}
+	}
+
+	return nil, false
+}
+
+func jsonValueName(value json.RawMessage) string {
+	switch value[0] {
+	case '"':
+		return "string"
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case 't', 'f':
+		return "bool"
+	case 'n':
+		return "null"
+	default:
+		return "number"
+	}
+}
@@ -0,0 +1,179 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+)
+
+const (
+	jsonRule = "json"
+	typeRule = "type"
+)
+
+// FieldError describes why a field of the request is invalid, the field is the name
+// used in the JSON payload or in the query string.
+type FieldError struct {
+	Field   string `json:"field,omitempty"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// New creates a validator that reports the fields with their json/form names, and
+// supports money.Money fields.
+func New() *validator.Validate {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate.RegisterTagNameFunc(fieldName)
+	money.RegisterValidatorType(validate)
+
+	return validate
+}
+
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// Fields returns the invalid fields of validation and JSON decoding errors, or nil for
+// other errors.
+func Fields(err error) []FieldError {
+	var (
+		validationErrs validator.ValidationErrors
+		typeErr        *json.UnmarshalTypeError
+		syntaxErr      *json.SyntaxError
+	)
+
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			fields = append(fields, newFieldError(fieldErr))
+		}
+
+		return fields
+	case errors.As(err, &typeErr) && typeErr.Field == "":
+		return []FieldError{{Rule: typeRule, Message: "the request body must be a JSON object"}}
+	case errors.As(err, &typeErr):
+		return []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    typeRule,
+			Message: fmt.Sprintf("%s must be %s", typeErr.Field, jsonTypeName(typeErr.Type)),
+		}}
+	case errors.As(err, &syntaxErr):
+		return []FieldError{{Rule: jsonRule, Message: fmt.Sprintf("malformed JSON at offset %d", syntaxErr.Offset)}}
+	case errors.Is(err, io.EOF):
+		return []FieldError{{Rule: jsonRule, Message: "the request body is empty"}}
+	}
+
+	return nil
+}
+
+func newFieldError(fieldErr validator.FieldError) FieldError {
+	// the namespace starts with the struct name, which isn't part of the payload
+	_, name, found := strings.Cut(fieldErr.Namespace(), ".")
+	if !found {
+		name = fieldErr.Field()
+	}
+
+	return FieldError{
+		Field:   name,
+		Rule:    fieldErr.Tag(),
+		Message: fieldMessage(name, fieldErr),
+	}
+}
+
+func fieldMessage(name string, fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+
+	unit := ""
+	switch fieldErr.Kind() { //nolint:exhaustive // the other kinds are compared by their values
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		unit = " items"
+	}
+
+	switch fieldErr.Tag() {
+	case "required":
+		return name + " is required"
+	case "min", "gte":
+		if unit != "" {
+			return fmt.Sprintf("%s must have at least %s%s", name, param, unit)
+		}
+
+		return fmt.Sprintf("%s must be greater than or equal to %s", name, param)
+	case "max", "lte":
+		if unit != "" {
+			return fmt.Sprintf("%s must have at most %s%s", name, param, unit)
+		}
+
+		return fmt.Sprintf("%s must be less than or equal to %s", name, param)
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", name, param)
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s", name, param)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", name, strings.ReplaceAll(param, " ", ", "))
+	case "email":
+		return name + " must be a valid email address"
+	case "e164":
+		return name + " must be a phone number in the E.164 format, like +5511987654321"
+	case "iso4217":
+		return name + " must be an ISO 4217 currency code, like BRL"
+	case "datetime":
+		return fmt.Sprintf("%s must be a date in the %s format", name, param)
+	case "http_url":
+		return name + " must be an HTTP or HTTPS URL"
+	case "nefield":
+		return fmt.Sprintf("%s must be different from %s", name, param)
+	}
+
+	return fmt.Sprintf("%s failed the %s validation", name, fieldErr.Tag())
+}
+
+var ( //nolint:gochecknoglobals // constant types
+	moneyType = reflect.TypeOf(money.Money{})
+	timeType  = reflect.TypeOf(time.Time{})
+)
+
+func jsonTypeName(fieldType reflect.Type) string {
+	for fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+
+	switch fieldType {
+	case moneyType:
+		return "a decimal number"
+	case timeType:
+		return "an RFC 3339 date"
+	}
+
+	switch fieldType.Kind() { //nolint:exhaustive // the other kinds aren't used in payloads
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
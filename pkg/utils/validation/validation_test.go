@@ -0,0 +1,79 @@
+package validation_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rudineirk/pismo-challenge/pkg/utils/errorlib"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/validation"
+	assert "github.com/stretchr/testify/require"
+)
+
+type testItem struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type testRequest struct {
+	AccountID int64        `json:"-"            validate:"required"`
+	Document  string       `json:"document"     validate:"required,max=5"`
+	Limit     *money.Money `json:"credit_limit" validate:"required,gte=0"`
+	Currency  string       `json:"currency"     validate:"omitempty,iso4217"`
+	Status    string       `form:"status"       validate:"omitempty,oneof=active closed"`
+	Items     []*testItem  `json:"items"        validate:"omitempty,dive"`
+	Count     int          `json:"count"`
+}
+
+func TestFields(t *testing.T) {
+	validate := validation.New()
+
+	t.Run("should list the fields that failed the validation", func(t *testing.T) {
+		limit := money.NewFromInt(-1)
+		req := &testRequest{
+			AccountID: 1,
+			Document:  "123456",
+			Limit:     &limit,
+			Currency:  "ABC",
+			Status:    "blocked",
+			Items:     []*testItem{{Name: "ok"}, {}},
+		}
+
+		err := errorlib.ErrInvalidPayload(validate.Struct(req))
+
+		assert.Equal(t, []validation.FieldError{
+			{Field: "document", Rule: "max", Message: "document must have at most 5 characters"},
+			{Field: "credit_limit", Rule: "gte", Message: "credit_limit must be greater than or equal to 0"},
+			{Field: "currency", Rule: "iso4217", Message: "currency must be an ISO 4217 currency code, like BRL"},
+			{Field: "status", Rule: "oneof", Message: "status must be one of: active, closed"},
+			{Field: "items[1].name", Rule: "required", Message: "items[1].name is required"},
+		}, validation.Fields(err))
+	})
+
+	t.Run("should use the struct field name for fields without json/form names", func(t *testing.T) {
+		limit := money.NewFromInt(1)
+		err := validate.Struct(&testRequest{Document: "123", Limit: &limit})
+
+		assert.Equal(t, []validation.FieldError{
+			{Field: "AccountID", Rule: "required", Message: "AccountID is required"},
+		}, validation.Fields(err))
+	})
+
+	t.Run("should report the JSON decoding errors", func(t *testing.T) {
+		for body, expected := range map[string]validation.FieldError{
+			`{"count":"10"}`:        {Field: "count", Rule: "type", Message: "count must be an integer"},
+			`{"credit_limit":true}`: {Field: "credit_limit", Rule: "type", Message: "credit_limit must be a decimal number"},
+			`{"count":1,`:           {Rule: "json", Message: "malformed JSON at offset 11"},
+			`{"count":1}}`:          {Rule: "json", Message: "malformed JSON at offset 12"},
+			`[]`:                    {Rule: "type", Message: "the request body must be a JSON object"},
+			` `:                     {Rule: "json", Message: "the request body is empty"},
+		} {
+			err := validation.DecodeJSON([]byte(body), &testRequest{})
+			assert.Equal(t, []validation.FieldError{expected}, validation.Fields(err), body)
+		}
+	})
+
+	t.Run("should ignore other errors", func(t *testing.T) {
+		assert.Nil(t, validation.Fields(errors.New("connection refused")))
+		assert.Nil(t, validation.Fields(errorlib.ErrInvalidPayload(nil)))
+	})
+}
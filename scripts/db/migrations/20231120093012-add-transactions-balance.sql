@@ -0,0 +1,13 @@
+-- +migrate Up
+ALTER TABLE public.transactions
+  ADD COLUMN balance numeric(20,2) NOT NULL DEFAULT 0;
+
+UPDATE public.transactions SET balance = amount;
+
+CREATE INDEX transactions_open_balance_idx
+  ON public.transactions USING btree (account_id, event_date, id)
+  WHERE balance <> 0;
+
+-- +migrate Down
+DROP INDEX public.transactions_open_balance_idx;
+ALTER TABLE public.transactions DROP COLUMN balance;
@@ -0,0 +1,17 @@
+-- +migrate Up
+ALTER TABLE public.accounts
+  ADD COLUMN available_credit_limit numeric(20,2) NOT NULL DEFAULT 0;
+
+-- the existing accounts receive the ACCOUNT_DEFAULT_CREDIT_LIMIT default (1000.00),
+-- less the debits not settled yet
+UPDATE public.accounts AS a
+  SET available_credit_limit = GREATEST(1000.00 + COALESCE((
+    SELECT SUM(t.balance) FROM public.transactions AS t
+    WHERE t.account_id = a.id AND t.balance < 0
+  ), 0), 0);
+
+ALTER TABLE public.accounts
+  ADD CONSTRAINT accounts_available_credit_limit_check CHECK (available_credit_limit >= 0);
+
+-- +migrate Down
+ALTER TABLE public.accounts DROP COLUMN available_credit_limit;
@@ -0,0 +1,18 @@
+-- +migrate Up
+CREATE TABLE public.idempotency_keys (
+  key character varying(255) NOT NULL,
+  fingerprint character varying(64) NOT NULL,
+  status_code int NOT NULL,
+  content_type character varying(255) NOT NULL,
+  response_body bytea NOT NULL,
+  created_at timestamp with time zone NOT NULL
+);
+
+ALTER TABLE public.idempotency_keys
+  ADD CONSTRAINT idempotency_keys_pkey PRIMARY KEY (key);
+
+CREATE INDEX idempotency_keys_created_at_idx
+  ON public.idempotency_keys USING btree (created_at);
+
+-- +migrate Down
+DROP TABLE public.idempotency_keys;
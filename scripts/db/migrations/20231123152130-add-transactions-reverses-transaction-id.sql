@@ -0,0 +1,11 @@
+-- +migrate Up
+ALTER TABLE public.transactions
+  ADD COLUMN reverses_transaction_id bigint REFERENCES public.transactions (id);
+
+CREATE INDEX transactions_reverses_transaction_id_idx
+  ON public.transactions USING btree (reverses_transaction_id)
+  WHERE reverses_transaction_id IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX public.transactions_reverses_transaction_id_idx;
+ALTER TABLE public.transactions DROP COLUMN reverses_transaction_id;
@@ -0,0 +1,14 @@
+-- +migrate Up
+ALTER TABLE public.operation_types
+  ADD COLUMN sign smallint NOT NULL DEFAULT -1,
+  ADD COLUMN affects_credit_limit boolean NOT NULL DEFAULT true;
+
+ALTER TABLE public.operation_types
+  ADD CONSTRAINT operation_types_sign_check CHECK (sign IN (-1, 1));
+
+UPDATE public.operation_types SET sign = 1 WHERE id = 4;
+
+-- +migrate Down
+ALTER TABLE public.operation_types DROP CONSTRAINT operation_types_sign_check;
+ALTER TABLE public.operation_types DROP COLUMN affects_credit_limit;
+ALTER TABLE public.operation_types DROP COLUMN sign;
@@ -0,0 +1,33 @@
+-- +migrate Up
+CREATE TABLE public.fx_rates (
+  from_currency character(3) NOT NULL,
+  to_currency character(3) NOT NULL,
+  rate numeric(20,8) NOT NULL,
+  effective_at timestamp with time zone NOT NULL,
+  created_at timestamp with time zone NOT NULL
+);
+ALTER TABLE public.fx_rates
+  ADD CONSTRAINT fx_rates_pkey PRIMARY KEY (from_currency, to_currency, effective_at);
+ALTER TABLE public.fx_rates
+  ADD CONSTRAINT fx_rates_rate_check CHECK (rate > 0);
+
+ALTER TABLE public.accounts
+  ADD COLUMN currency character(3) NOT NULL DEFAULT 'BRL';
+
+ALTER TABLE public.transactions
+  ADD COLUMN currency character(3),
+  ADD COLUMN original_currency character(3),
+  ADD COLUMN original_amount numeric(20,2),
+  ADD COLUMN fx_rate numeric(20,8);
+
+UPDATE public.transactions SET currency = 'BRL';
+
+ALTER TABLE public.transactions ALTER COLUMN currency SET NOT NULL;
+
+-- +migrate Down
+ALTER TABLE public.transactions DROP COLUMN fx_rate;
+ALTER TABLE public.transactions DROP COLUMN original_amount;
+ALTER TABLE public.transactions DROP COLUMN original_currency;
+ALTER TABLE public.transactions DROP COLUMN currency;
+ALTER TABLE public.accounts DROP COLUMN currency;
+DROP TABLE public.fx_rates;
@@ -0,0 +1,45 @@
+-- +migrate Up
+CREATE SEQUENCE public.installment_plans_id_seq AS bigint;
+CREATE TABLE public.installment_plans (
+  id bigint DEFAULT nextval('public.installment_plans_id_seq') NOT NULL,
+  transaction_id bigint NOT NULL,
+  account_id bigint NOT NULL,
+  total_amount numeric(20,2) NOT NULL,
+  created_at timestamp with time zone NOT NULL
+);
+
+ALTER TABLE public.installment_plans
+  ADD CONSTRAINT installment_plans_pkey PRIMARY KEY (id);
+ALTER TABLE public.installment_plans
+  ADD CONSTRAINT installment_plans_transaction_id_key UNIQUE (transaction_id);
+ALTER TABLE public.installment_plans
+  ADD CONSTRAINT installment_plans_transaction_id_fkey FOREIGN KEY (transaction_id)
+  REFERENCES public.transactions(id) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE public.installment_plans
+  ADD CONSTRAINT installment_plans_account_id_fkey FOREIGN KEY (account_id)
+  REFERENCES public.accounts(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE SEQUENCE public.installments_id_seq AS bigint;
+CREATE TABLE public.installments (
+  id bigint DEFAULT nextval('public.installments_id_seq') NOT NULL,
+  plan_id bigint NOT NULL,
+  number int NOT NULL,
+  amount numeric(20,2) NOT NULL,
+  due_date date NOT NULL
+);
+
+ALTER TABLE public.installments
+  ADD CONSTRAINT installments_pkey PRIMARY KEY (id);
+ALTER TABLE public.installments
+  ADD CONSTRAINT installments_plan_id_number_key UNIQUE (plan_id, number);
+ALTER TABLE public.installments
+  ADD CONSTRAINT installments_plan_id_fkey FOREIGN KEY (plan_id)
+  REFERENCES public.installment_plans(id) ON UPDATE CASCADE ON DELETE CASCADE;
+CREATE INDEX installments_due_date_idx
+  ON public.installments USING btree (due_date);
+
+-- +migrate Down
+DROP TABLE public.installments;
+DROP SEQUENCE public.installments_id_seq;
+DROP TABLE public.installment_plans;
+DROP SEQUENCE public.installment_plans_id_seq;
@@ -0,0 +1,21 @@
+-- +migrate Up
+CREATE SEQUENCE public.outbox_events_id_seq AS bigint;
+CREATE TABLE public.outbox_events (
+  id bigint DEFAULT nextval('public.outbox_events_id_seq') NOT NULL,
+  event_type varchar(64) NOT NULL,
+  account_id bigint NOT NULL,
+  payload jsonb NOT NULL,
+  attempts int DEFAULT 0 NOT NULL,
+  last_error text,
+  created_at timestamp with time zone NOT NULL,
+  published_at timestamp with time zone
+);
+
+ALTER TABLE public.outbox_events
+  ADD CONSTRAINT outbox_events_pkey PRIMARY KEY (id);
+CREATE INDEX outbox_events_pending_idx
+  ON public.outbox_events USING btree (id) WHERE published_at IS NULL;
+
+-- +migrate Down
+DROP TABLE public.outbox_events;
+DROP SEQUENCE public.outbox_events_id_seq;
@@ -0,0 +1,71 @@
+-- +migrate Up
+CREATE SEQUENCE public.webhook_subscriptions_id_seq AS bigint;
+CREATE TABLE public.webhook_subscriptions (
+  id bigint DEFAULT nextval('public.webhook_subscriptions_id_seq') NOT NULL,
+  url text NOT NULL,
+  secret varchar(128) NOT NULL,
+  event_types text[] NOT NULL,
+  account_id bigint,
+  created_at timestamp with time zone NOT NULL,
+  updated_at timestamp with time zone NOT NULL
+);
+
+ALTER TABLE public.webhook_subscriptions
+  ADD CONSTRAINT webhook_subscriptions_pkey PRIMARY KEY (id);
+ALTER TABLE public.webhook_subscriptions
+  ADD CONSTRAINT webhook_subscriptions_account_id_fkey FOREIGN KEY (account_id)
+  REFERENCES public.accounts(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE SEQUENCE public.webhook_deliveries_id_seq AS bigint;
+CREATE TABLE public.webhook_deliveries (
+  id bigint DEFAULT nextval('public.webhook_deliveries_id_seq') NOT NULL,
+  subscription_id bigint NOT NULL,
+  event_id bigint NOT NULL,
+  event_type varchar(64) NOT NULL,
+  payload jsonb NOT NULL,
+  status varchar(16) NOT NULL,
+  attempts int DEFAULT 0 NOT NULL,
+  next_attempt_at timestamp with time zone NOT NULL,
+  last_status_code int,
+  last_error text,
+  delivered_at timestamp with time zone,
+  created_at timestamp with time zone NOT NULL,
+  updated_at timestamp with time zone NOT NULL
+);
+
+ALTER TABLE public.webhook_deliveries
+  ADD CONSTRAINT webhook_deliveries_pkey PRIMARY KEY (id);
+ALTER TABLE public.webhook_deliveries
+  ADD CONSTRAINT webhook_deliveries_subscription_id_event_id_key UNIQUE (subscription_id, event_id);
+ALTER TABLE public.webhook_deliveries
+  ADD CONSTRAINT webhook_deliveries_subscription_id_fkey FOREIGN KEY (subscription_id)
+  REFERENCES public.webhook_subscriptions(id) ON UPDATE CASCADE ON DELETE CASCADE;
+CREATE INDEX webhook_deliveries_due_idx
+  ON public.webhook_deliveries USING btree (next_attempt_at)
+  WHERE status IN ('pending', 'retrying');
+
+CREATE SEQUENCE public.webhook_delivery_attempts_id_seq AS bigint;
+CREATE TABLE public.webhook_delivery_attempts (
+  id bigint DEFAULT nextval('public.webhook_delivery_attempts_id_seq') NOT NULL,
+  delivery_id bigint NOT NULL,
+  status_code int,
+  error text,
+  duration_ms bigint NOT NULL,
+  attempted_at timestamp with time zone NOT NULL
+);
+
+ALTER TABLE public.webhook_delivery_attempts
+  ADD CONSTRAINT webhook_delivery_attempts_pkey PRIMARY KEY (id);
+ALTER TABLE public.webhook_delivery_attempts
+  ADD CONSTRAINT webhook_delivery_attempts_delivery_id_fkey FOREIGN KEY (delivery_id)
+  REFERENCES public.webhook_deliveries(id) ON UPDATE CASCADE ON DELETE CASCADE;
+CREATE INDEX webhook_delivery_attempts_delivery_id_idx
+  ON public.webhook_delivery_attempts USING btree (delivery_id);
+
+-- +migrate Down
+DROP TABLE public.webhook_delivery_attempts;
+DROP SEQUENCE public.webhook_delivery_attempts_id_seq;
+DROP TABLE public.webhook_deliveries;
+DROP SEQUENCE public.webhook_deliveries_id_seq;
+DROP TABLE public.webhook_subscriptions;
+DROP SEQUENCE public.webhook_subscriptions_id_seq;
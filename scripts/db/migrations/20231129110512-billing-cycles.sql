@@ -0,0 +1,42 @@
+-- +migrate Up
+ALTER TABLE public.accounts
+  ADD COLUMN closing_day smallint DEFAULT 25 NOT NULL,
+  ADD COLUMN due_day smallint DEFAULT 5 NOT NULL;
+ALTER TABLE public.accounts
+  ADD CONSTRAINT accounts_closing_day_check CHECK (closing_day BETWEEN 1 AND 28);
+ALTER TABLE public.accounts
+  ADD CONSTRAINT accounts_due_day_check CHECK (due_day BETWEEN 1 AND 28);
+
+CREATE SEQUENCE public.statements_id_seq AS bigint;
+CREATE TABLE public.statements (
+  id bigint DEFAULT nextval('public.statements_id_seq') NOT NULL,
+  account_id bigint NOT NULL,
+  currency char(3) NOT NULL,
+  period_start date NOT NULL,
+  closing_date date NOT NULL,
+  due_date date NOT NULL,
+  opening_balance numeric(20,2) NOT NULL,
+  purchases numeric(20,2) NOT NULL,
+  withdrawals numeric(20,2) NOT NULL,
+  payments numeric(20,2) NOT NULL,
+  other numeric(20,2) NOT NULL,
+  closing_balance numeric(20,2) NOT NULL,
+  minimum_payment numeric(20,2) NOT NULL,
+  transactions_count int NOT NULL,
+  created_at timestamp with time zone NOT NULL
+);
+
+ALTER TABLE public.statements
+  ADD CONSTRAINT statements_pkey PRIMARY KEY (id);
+ALTER TABLE public.statements
+  ADD CONSTRAINT statements_account_id_closing_date_key UNIQUE (account_id, closing_date);
+ALTER TABLE public.statements
+  ADD CONSTRAINT statements_account_id_fkey FOREIGN KEY (account_id)
+  REFERENCES public.accounts(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+-- +migrate Down
+DROP TABLE public.statements;
+DROP SEQUENCE public.statements_id_seq;
+ALTER TABLE public.accounts
+  DROP COLUMN closing_day,
+  DROP COLUMN due_day;
@@ -0,0 +1,40 @@
+-- +migrate Up
+ALTER TABLE public.operation_types
+  ADD COLUMN system_generated boolean NOT NULL DEFAULT false;
+
+INSERT INTO public.operation_types (id, description, sign, affects_credit_limit, system_generated) VALUES
+  (5, 'INTEREST', -1, false, true),
+  (6, 'LATE FEE', -1, false, true),
+  (7, 'LATE PAYMENT PENALTY', -1, false, true);
+
+CREATE SEQUENCE public.accruals_id_seq AS bigint;
+CREATE TABLE public.accruals (
+  id bigint DEFAULT nextval('public.accruals_id_seq') NOT NULL,
+  account_id bigint NOT NULL,
+  statement_id bigint NOT NULL,
+  accrual_date date NOT NULL,
+  overdue_balance numeric(20,2) NOT NULL,
+  interest numeric(20,2) NOT NULL,
+  late_fee numeric(20,2) NOT NULL,
+  penalty numeric(20,2) NOT NULL,
+  created_at timestamp with time zone NOT NULL
+);
+
+ALTER TABLE public.accruals
+  ADD CONSTRAINT accruals_pkey PRIMARY KEY (id);
+ALTER TABLE public.accruals
+  ADD CONSTRAINT accruals_account_id_accrual_date_key UNIQUE (account_id, accrual_date);
+ALTER TABLE public.accruals
+  ADD CONSTRAINT accruals_account_id_fkey FOREIGN KEY (account_id)
+  REFERENCES public.accounts(id) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE public.accruals
+  ADD CONSTRAINT accruals_statement_id_fkey FOREIGN KEY (statement_id)
+  REFERENCES public.statements(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE INDEX accruals_statement_id_idx ON public.accruals (statement_id);
+
+-- +migrate Down
+DROP TABLE public.accruals;
+DROP SEQUENCE public.accruals_id_seq;
+DELETE FROM public.operation_types WHERE id IN (5, 6, 7);
+ALTER TABLE public.operation_types DROP COLUMN system_generated;
@@ -0,0 +1,28 @@
+-- +migrate Up
+ALTER TABLE public.accounts
+  ADD COLUMN status character varying(16) NOT NULL DEFAULT 'active';
+ALTER TABLE public.accounts
+  ADD CONSTRAINT accounts_status_check CHECK (status IN ('active', 'blocked', 'suspended', 'closed'));
+
+CREATE SEQUENCE public.account_status_transitions_id_seq AS bigint;
+CREATE TABLE public.account_status_transitions (
+  id bigint DEFAULT nextval('public.account_status_transitions_id_seq') NOT NULL,
+  account_id bigint NOT NULL,
+  from_status character varying(16) NOT NULL,
+  to_status character varying(16) NOT NULL,
+  reason character varying(255) NOT NULL,
+  created_at timestamp with time zone NOT NULL
+);
+
+ALTER TABLE public.account_status_transitions
+  ADD CONSTRAINT account_status_transitions_pkey PRIMARY KEY (id);
+ALTER TABLE public.account_status_transitions
+  ADD CONSTRAINT account_status_transitions_account_id_fkey FOREIGN KEY (account_id)
+  REFERENCES public.accounts(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE INDEX account_status_transitions_account_id_idx ON public.account_status_transitions (account_id);
+
+-- +migrate Down
+DROP TABLE public.account_status_transitions;
+DROP SEQUENCE public.account_status_transitions_id_seq;
+ALTER TABLE public.accounts DROP COLUMN status;
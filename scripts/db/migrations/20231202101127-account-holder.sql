@@ -0,0 +1,25 @@
+-- +migrate Up
+ALTER TABLE public.accounts
+  ADD COLUMN document_type character varying(8),
+  ADD COLUMN holder_name character varying(255) NOT NULL DEFAULT '',
+  ADD COLUMN email character varying(255) NOT NULL DEFAULT '',
+  ADD COLUMN phone character varying(16) NOT NULL DEFAULT '',
+  ADD COLUMN birth_date date,
+  ADD COLUMN incorporation_date date;
+
+UPDATE public.accounts
+  SET document_type = CASE WHEN length(document_number) = 11 THEN 'cpf' ELSE 'cnpj' END;
+
+ALTER TABLE public.accounts
+  ALTER COLUMN document_type SET NOT NULL;
+ALTER TABLE public.accounts
+  ADD CONSTRAINT accounts_document_type_check CHECK (document_type IN ('cpf', 'cnpj'));
+
+-- +migrate Down
+ALTER TABLE public.accounts
+  DROP COLUMN document_type,
+  DROP COLUMN holder_name,
+  DROP COLUMN email,
+  DROP COLUMN phone,
+  DROP COLUMN birth_date,
+  DROP COLUMN incorporation_date;
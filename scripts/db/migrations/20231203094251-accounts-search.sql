@@ -0,0 +1,5 @@
+-- +migrate Up
+CREATE INDEX accounts_email_idx ON public.accounts (email);
+
+-- +migrate Down
+DROP INDEX public.accounts_email_idx;
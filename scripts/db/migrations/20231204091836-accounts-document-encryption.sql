@@ -0,0 +1,29 @@
+-- +migrate Up
+ALTER TABLE public.accounts
+  ADD COLUMN document_number_ciphertext bytea,
+  ADD COLUMN document_number_key_id character varying(64),
+  ADD COLUMN document_number_index character varying(64);
+ALTER TABLE public.accounts
+  ALTER COLUMN document_number DROP NOT NULL;
+ALTER TABLE public.accounts
+  ADD CONSTRAINT accounts_document_number_check CHECK (
+    document_number IS NOT NULL OR (
+      document_number_ciphertext IS NOT NULL AND
+      document_number_key_id IS NOT NULL AND
+      document_number_index IS NOT NULL
+    )
+  );
+
+CREATE UNIQUE INDEX accounts_document_number_index_key
+  ON public.accounts USING btree (document_number_index);
+
+-- +migrate Down
+DROP INDEX public.accounts_document_number_index_key;
+ALTER TABLE public.accounts
+  DROP CONSTRAINT accounts_document_number_check;
+ALTER TABLE public.accounts
+  DROP COLUMN document_number_ciphertext,
+  DROP COLUMN document_number_key_id,
+  DROP COLUMN document_number_index;
+ALTER TABLE public.accounts
+  ALTER COLUMN document_number SET NOT NULL;
@@ -0,0 +1,19 @@
+-- +migrate Up
+ALTER TABLE public.outbox_events
+  ADD COLUMN next_attempt_at timestamp with time zone,
+  ADD COLUMN dead_at timestamp with time zone;
+
+DROP INDEX public.outbox_events_pending_idx;
+CREATE INDEX outbox_events_pending_idx
+  ON public.outbox_events USING btree (id) WHERE published_at IS NULL AND dead_at IS NULL;
+CREATE INDEX outbox_events_pending_account_idx
+  ON public.outbox_events USING btree (account_id, id) WHERE published_at IS NULL AND dead_at IS NULL;
+
+-- +migrate Down
+DROP INDEX public.outbox_events_pending_account_idx;
+DROP INDEX public.outbox_events_pending_idx;
+CREATE INDEX outbox_events_pending_idx
+  ON public.outbox_events USING btree (id) WHERE published_at IS NULL;
+ALTER TABLE public.outbox_events
+  DROP COLUMN next_attempt_at,
+  DROP COLUMN dead_at;
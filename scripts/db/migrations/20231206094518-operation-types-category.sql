@@ -0,0 +1,13 @@
+-- +migrate Up
+ALTER TABLE public.operation_types
+  ADD COLUMN category character varying(16) NOT NULL DEFAULT 'other';
+ALTER TABLE public.operation_types
+  ADD CONSTRAINT operation_types_category_check CHECK (category IN ('purchase', 'withdrawal', 'payment', 'other'));
+
+UPDATE public.operation_types SET category = 'purchase' WHERE id IN (1, 2);
+UPDATE public.operation_types SET category = 'withdrawal' WHERE id = 3;
+UPDATE public.operation_types SET category = 'payment' WHERE id = 4;
+
+-- +migrate Down
+ALTER TABLE public.operation_types DROP CONSTRAINT operation_types_category_check;
+ALTER TABLE public.operation_types DROP COLUMN category;
@@ -2,9 +2,12 @@ package accounts_test
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"testing"
 
@@ -15,7 +18,10 @@ import (
 	"github.com/rudineirk/pismo-challenge/pkg/infra/database"
 	"github.com/rudineirk/pismo-challenge/pkg/infra/httprouter"
 	"github.com/rudineirk/pismo-challenge/pkg/infra/logger"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/outbox"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
 	"github.com/rudineirk/pismo-challenge/pkg/utils/testutils"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/validation"
 )
 
 const ContentTypeJSON = "application/json"
@@ -42,8 +48,8 @@ func TestAccountsAPIs(t *testing.T) {
 	err = database.RunMigrations(sqlDB)
 	assert.NoError(t, err)
 
-	repo := accounts.NewRepository(bunDB)
-	svc := accounts.NewService(repo)
+	repo := accounts.NewRepository(bunDB, testutils.NewTestKeyring())
+	svc := accounts.NewService(repo, outbox.NewStore(bunDB), money.New(cfg.AccountDefaultCreditLimit))
 
 	router := httprouter.NewRouter(logger, cfg.IsProduction)
 	accounts.SetupHTTPRoutes(router, svc)
@@ -54,7 +60,8 @@ func TestAccountsAPIs(t *testing.T) {
 	t.Run("POST /accounts", func(t *testing.T) {
 		t.Run("should create a new account", func(t *testing.T) {
 			jsonPayload, err := json.Marshal(map[string]any{
-				"document_number": "66895932070",
+				"document_number":        "66895932070",
+				"available_credit_limit": 1000,
 			})
 			assert.NoError(t, err)
 
@@ -68,6 +75,20 @@ func TestAccountsAPIs(t *testing.T) {
 
 			assert.NotEqual(t, int64(0), respData.AccountID)
 			assert.Equal(t, "66895932070", respData.DocumentNumber)
+			assert.Equal(t, "1000.00", respData.AvailableCreditLimit.String())
+			assert.Equal(t, "BRL", respData.Currency)
+		})
+
+		t.Run("should use the default credit limit if it's not present", func(t *testing.T) {
+			resp, err := client.Post(server.URL+"/accounts", ContentTypeJSON, strings.NewReader(`{"document_number":"48151623446"}`))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+			respData := accounts.AccountAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+
+			assert.Equal(t, money.New(cfg.AccountDefaultCreditLimit).String(), respData.AvailableCreditLimit.String())
 		})
 
 		t.Run("should return error if payload format is invalid", func(t *testing.T) {
@@ -80,14 +101,49 @@ func TestAccountsAPIs(t *testing.T) {
 			assert.NoError(t, err)
 			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
+			problem := httprouter.Problem{}
+			err = json.NewDecoder(resp.Body).Decode(&problem)
+			assert.NoError(t, err)
+			assert.Equal(t, []validation.FieldError{
+				{Field: "document_number", Rule: "required", Message: "document_number is required"},
+			}, problem.Errors)
+
 			resp, err = client.Post(server.URL+"/accounts", ContentTypeJSON, strings.NewReader(`{"invalid":"json"`))
 			assert.NoError(t, err)
 			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+			resp, err = client.Post(server.URL+"/accounts", ContentTypeJSON, strings.NewReader(`{"available_credit_limit":"abc"}`))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+			problem = httprouter.Problem{}
+			err = json.NewDecoder(resp.Body).Decode(&problem)
+			assert.NoError(t, err)
+			assert.Equal(t, []validation.FieldError{{
+				Field:   "available_credit_limit",
+				Rule:    "type",
+				Message: "available_credit_limit must be a decimal number",
+			}}, problem.Errors)
+		})
+
+		t.Run("should return error if credit limit is invalid", func(t *testing.T) {
+			for _, creditLimit := range []any{nil, -10, 10.005} {
+				jsonPayload, err := json.Marshal(map[string]any{
+					"document_number":        "66895932070",
+					"available_credit_limit": creditLimit,
+				})
+				assert.NoError(t, err)
+
+				resp, err := client.Post(server.URL+"/accounts", ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+				assert.NoError(t, err)
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			}
 		})
 
 		t.Run("should return error if document is duplicated", func(t *testing.T) {
 			jsonPayload, err := json.Marshal(map[string]any{
-				"document_number": "24.885.962/0001-33",
+				"document_number":        "24.885.962/0001-33",
+				"available_credit_limit": 1000,
 			})
 			assert.NoError(t, err)
 
@@ -111,7 +167,8 @@ func TestAccountsAPIs(t *testing.T) {
 	t.Run("GET /accounts/{id}", func(t *testing.T) {
 		t.Run("should get account by ID", func(t *testing.T) {
 			jsonPayload, err := json.Marshal(map[string]any{
-				"document_number": "52.987.490/0001-65",
+				"document_number":        "52.987.490/0001-65",
+				"available_credit_limit": 1000,
 			})
 			assert.NoError(t, err)
 
@@ -141,10 +198,381 @@ func TestAccountsAPIs(t *testing.T) {
 			resp, err := client.Get(server.URL + "/accounts/987")
 			assert.NoError(t, err)
 			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			assert.Equal(t, httprouter.ContentTypeProblemJSON, resp.Header.Get("Content-Type"))
+
+			problem := httprouter.Problem{}
+			err = json.NewDecoder(resp.Body).Decode(&problem)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, problem.Status)
+			assert.Equal(t, "not_found", problem.Code)
+			assert.NotEmpty(t, problem.RequestID)
 
 			resp, err = client.Get(server.URL + "/accounts/abc-123")
 			assert.NoError(t, err)
 			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 		})
 	})
+
+	t.Run("GET /accounts", func(t *testing.T) {
+		t.Run("should find the account by the formatted or raw document number", func(t *testing.T) {
+			for _, documentNumber := range []string{"52.987.490/0001-65", "52987490000165"} {
+				resp, err := client.Get(server.URL + "/accounts?status=active&document_number=" + url.QueryEscape(documentNumber))
+				assert.NoError(t, err)
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+				respData := accounts.AccountsPageAPIResponse{}
+				err = json.NewDecoder(resp.Body).Decode(&respData)
+				assert.NoError(t, err)
+
+				assert.Len(t, respData.Items, 1)
+				assert.Equal(t, "52987490000165", respData.Items[0].DocumentNumber)
+				assert.Nil(t, respData.NextCursor)
+			}
+		})
+
+		t.Run("should paginate the accounts", func(t *testing.T) {
+			resp, err := client.Get(server.URL + "/accounts?limit=1")
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			firstPage := accounts.AccountsPageAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&firstPage)
+			assert.NoError(t, err)
+			assert.Len(t, firstPage.Items, 1)
+			assert.NotNil(t, firstPage.NextCursor)
+
+			resp, err = client.Get(server.URL + "/accounts?limit=1&cursor=" + *firstPage.NextCursor)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			secondPage := accounts.AccountsPageAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&secondPage)
+			assert.NoError(t, err)
+			assert.Len(t, secondPage.Items, 1)
+			assert.Greater(t, secondPage.Items[0].AccountID, firstPage.Items[0].AccountID)
+		})
+
+		t.Run("should return an empty list if nothing matches", func(t *testing.T) {
+			resp, err := client.Get(server.URL + "/accounts?document_number=05677940000133")
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			respData := accounts.AccountsPageAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+			assert.Empty(t, respData.Items)
+		})
+
+		t.Run("should return error if filters are invalid", func(t *testing.T) {
+			for _, query := range []string{"document_number=abc", "status=deleted", "cursor=invalid!", "limit=500"} {
+				resp, err := client.Get(server.URL + "/accounts?" + query)
+				assert.NoError(t, err)
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			}
+		})
+	})
+
+	t.Run("PATCH /accounts/{id}", func(t *testing.T) {
+		jsonPayload, err := json.Marshal(map[string]any{
+			"document_number":        "394.817.265-09",
+			"available_credit_limit": 1000,
+			"holder_name":            "Maria Silva",
+		})
+		assert.NoError(t, err)
+
+		resp, err := client.Post(server.URL+"/accounts", ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		account := accounts.AccountAPIResponse{}
+		err = json.NewDecoder(resp.Body).Decode(&account)
+		assert.NoError(t, err)
+		assert.Equal(t, accounts.CPFDocumentType, account.DocumentType)
+
+		accountURL := fmt.Sprintf("%s/accounts/%d", server.URL, account.AccountID)
+
+		t.Run("should update the holder data", func(t *testing.T) {
+			resp, err := Patch(client, accountURL, `{"email":"maria@example.com","phone":"+5511987654321","birth_date":"1990-05-20"}`)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			resp, err = client.Get(accountURL)
+			assert.NoError(t, err)
+
+			respData := accounts.AccountAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+
+			assert.Equal(t, "Maria Silva", respData.HolderName)
+			assert.Equal(t, "maria@example.com", respData.Email)
+			assert.Equal(t, "+5511987654321", respData.Phone)
+			assert.Equal(t, "1990-05-20", respData.BirthDate)
+			assert.True(t, respData.UpdatedAt.After(account.UpdatedAt))
+		})
+
+		t.Run("should return error if payload is invalid", func(t *testing.T) {
+			for _, body := range []string{
+				`{"email":"maria"}`,
+				`{"holder_name":""}`,
+				`{"incorporation_date":"2010-01-01"}`,
+				`{"document_number":"05677940000133"}`,
+			} {
+				resp, err := Patch(client, accountURL, body)
+				assert.NoError(t, err)
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			}
+		})
+
+		t.Run("should return not found if can't find account", func(t *testing.T) {
+			resp, err := Patch(client, server.URL+"/accounts/987", `{"holder_name":"Maria"}`)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		})
+	})
+
+	t.Run("GET /accounts/{id}/balance", func(t *testing.T) {
+		t.Run("should return a zero balance if account has no transactions", func(t *testing.T) {
+			jsonPayload, err := json.Marshal(map[string]any{
+				"document_number":        "31.615.485/0001-51",
+				"available_credit_limit": 1000,
+			})
+			assert.NoError(t, err)
+
+			resp, err := client.Post(server.URL+"/accounts", ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+			account := accounts.AccountAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&account)
+			assert.NoError(t, err)
+
+			resp, err = client.Get(fmt.Sprintf("%s/accounts/%d/balance", server.URL, account.AccountID))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			respData := accounts.BalanceAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+
+			assert.Equal(t, account.AccountID, respData.AccountID)
+			assert.Equal(t, "0.00", respData.Balance.String())
+			assert.Empty(t, respData.OperationTypes)
+		})
+
+		t.Run("should return not found if can't find account", func(t *testing.T) {
+			resp, err := client.Get(server.URL + "/accounts/987/balance")
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		})
+	})
+
+	t.Run("PUT /accounts/{id}/credit-limit", func(t *testing.T) {
+		jsonPayload, err := json.Marshal(map[string]any{
+			"document_number":        "41.827.365/0001-71",
+			"available_credit_limit": 1000,
+		})
+		assert.NoError(t, err)
+
+		resp, err := client.Post(server.URL+"/accounts", ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		account := accounts.AccountAPIResponse{}
+		err = json.NewDecoder(resp.Body).Decode(&account)
+		assert.NoError(t, err)
+
+		creditLimitURL := fmt.Sprintf("%s/accounts/%d/credit-limit", server.URL, account.AccountID)
+
+		t.Run("should update the available credit limit", func(t *testing.T) {
+			resp, err := Put(client, creditLimitURL, `{"available_credit_limit":2500.5}`)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			respData := accounts.AccountAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+
+			assert.Equal(t, account.AccountID, respData.AccountID)
+			assert.Equal(t, "2500.50", respData.AvailableCreditLimit.String())
+
+			resp, err = client.Get(fmt.Sprintf("%s/accounts/%d", server.URL, account.AccountID))
+			assert.NoError(t, err)
+
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+			assert.Equal(t, "2500.50", respData.AvailableCreditLimit.String())
+		})
+
+		t.Run("should return error if credit limit is invalid", func(t *testing.T) {
+			for _, body := range []string{`{}`, `{"available_credit_limit":-1}`, `{"available_credit_limit":1.001}`} {
+				resp, err := Put(client, creditLimitURL, body)
+				assert.NoError(t, err)
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			}
+		})
+
+		t.Run("should return not found if can't find account", func(t *testing.T) {
+			resp, err := Put(client, server.URL+"/accounts/987/credit-limit", `{"available_credit_limit":10}`)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		})
+	})
+
+	t.Run("POST /accounts/{id}/block|unblock|close", func(t *testing.T) {
+		jsonPayload, err := json.Marshal(map[string]any{
+			"document_number":        "123.456.789-09",
+			"available_credit_limit": 1000,
+		})
+		assert.NoError(t, err)
+
+		resp, err := client.Post(server.URL+"/accounts", ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		account := accounts.AccountAPIResponse{}
+		err = json.NewDecoder(resp.Body).Decode(&account)
+		assert.NoError(t, err)
+		assert.Equal(t, accounts.ActiveStatus, account.Status)
+
+		accountURL := fmt.Sprintf("%s/accounts/%d", server.URL, account.AccountID)
+
+		t.Run("should block and unblock the account", func(t *testing.T) {
+			resp, err := client.Post(accountURL+"/block", ContentTypeJSON, strings.NewReader(`{"reason":"card lost"}`))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			respData := accounts.AccountAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+			assert.Equal(t, accounts.BlockedStatus, respData.Status)
+
+			resp, err = client.Post(accountURL+"/unblock", ContentTypeJSON, strings.NewReader(`{"reason":"card found"}`))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+			assert.Equal(t, accounts.ActiveStatus, respData.Status)
+		})
+
+		t.Run("should return error if reason is missing", func(t *testing.T) {
+			resp, err := client.Post(accountURL+"/block", ContentTypeJSON, strings.NewReader(`{}`))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("should not reopen a closed account", func(t *testing.T) {
+			resp, err := client.Post(accountURL+"/close", ContentTypeJSON, strings.NewReader(`{"reason":"customer request"}`))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			resp, err = client.Post(accountURL+"/unblock", ContentTypeJSON, strings.NewReader(`{"reason":"reopen"}`))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		})
+
+		t.Run("should list the recorded transitions", func(t *testing.T) {
+			resp, err := client.Get(accountURL + "/status-transitions")
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			respData := []*accounts.StatusTransitionAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+
+			assert.Len(t, respData, 3)
+			assert.Equal(t, accounts.ActiveStatus, respData[0].FromStatus)
+			assert.Equal(t, accounts.BlockedStatus, respData[0].ToStatus)
+			assert.Equal(t, "card lost", respData[0].Reason)
+			assert.Equal(t, accounts.ActiveStatus, respData[1].ToStatus)
+			assert.Equal(t, accounts.ClosedStatus, respData[2].ToStatus)
+		})
+
+		t.Run("should return not found if can't find account", func(t *testing.T) {
+			resp, err := client.Post(server.URL+"/accounts/987/block", ContentTypeJSON, strings.NewReader(`{"reason":"test"}`))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+			resp, err = client.Get(server.URL + "/accounts/987/status-transitions")
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		})
+	})
+
+	t.Run("document number encryption", func(t *testing.T) {
+		ctx := context.TODO()
+
+		t.Run("should not store the plaintext document number", func(t *testing.T) {
+			var (
+				documentNumber sql.NullString
+				keyID          string
+			)
+
+			err := bunDB.NewSelect().
+				Table("accounts").
+				Column("document_number", "document_number_key_id").
+				Where("document_number_ciphertext IS NOT NULL").
+				Limit(1).
+				Scan(ctx, &documentNumber, &keyID)
+			assert.NoError(t, err)
+
+			assert.False(t, documentNumber.Valid)
+			assert.Equal(t, "test", keyID)
+		})
+
+		t.Run("should re-encrypt the plaintext document numbers", func(t *testing.T) {
+			_, err := bunDB.ExecContext(ctx, `
+				INSERT INTO accounts (document_number, document_type, created_at, updated_at)
+				VALUES ('52836491728', 'cpf', now(), now())
+			`)
+			assert.NoError(t, err)
+
+			resp, err := client.Post(server.URL+"/accounts", ContentTypeJSON, strings.NewReader(
+				`{"document_number":"528.364.917-28","available_credit_limit":10}`,
+			))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+			count, err := repo.ReencryptDocumentNumbers(ctx, 1, false)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, count)
+
+			exists, err := bunDB.NewSelect().
+				Table("accounts").
+				Where("document_number IS NOT NULL").
+				Exists(ctx)
+			assert.NoError(t, err)
+			assert.False(t, exists)
+
+			resp, err = client.Get(server.URL + "/accounts?document_number=52836491728")
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			respData := accounts.AccountsPageAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+
+			assert.Len(t, respData.Items, 1)
+			assert.Equal(t, "52836491728", respData.Items[0].DocumentNumber)
+		})
+	})
+}
+
+func Put(client *http.Client, url string, body string) (*http.Response, error) {
+	return sendJSON(client, http.MethodPut, url, body)
+}
+
+func Patch(client *http.Client, url string, body string) (*http.Response, error) {
+	return sendJSON(client, http.MethodPatch, url, body)
+}
+
+func sendJSON(client *http.Client, method string, url string, body string) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", ContentTypeJSON)
+
+	return client.Do(req)
 }
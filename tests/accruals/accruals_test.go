@@ -0,0 +1,216 @@
+package accruals_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/accruals"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/fxrates"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/statements"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/transactions"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/config"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/database"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/httprouter"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/logger"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/outbox"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/testutils"
+	"github.com/shopspring/decimal"
+)
+
+const ContentTypeJSON = "application/json"
+
+func TestAccrualsAPIs(t *testing.T) {
+	err := testutils.SetRootCwd()
+	assert.NoError(t, err)
+
+	logger := logger.NewStubLogger()
+
+	cfg, err := config.LoadConfig()
+	assert.NoError(t, err)
+
+	cfg.IsProduction = true
+
+	testDB, err := testutils.NewTestDatabase(cfg.DatabaseURL)
+	assert.NoError(t, err)
+
+	defer testDB.Drop()
+
+	sqlDB, bunDB, err := database.NewDatabase(testDB.URL)
+	assert.NoError(t, err)
+
+	err = database.RunMigrations(sqlDB)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	router := httprouter.NewRouter(logger, cfg.IsProduction)
+
+	opTypesSvc := operationtypes.NewService(operationtypes.NewRepository(bunDB))
+	err = opTypesSvc.LoadOperationTypes(ctx)
+	assert.NoError(t, err)
+
+	outboxStore := outbox.NewStore(bunDB)
+	accountsSvc := accounts.NewService(
+		accounts.NewRepository(bunDB, testutils.NewTestKeyring()),
+		outboxStore,
+		money.New(cfg.AccountDefaultCreditLimit),
+	)
+	transactionsSvc := transactions.NewService(
+		transactions.NewRepository(bunDB),
+		accountsSvc,
+		opTypesSvc,
+		fxrates.NewService(fxrates.NewRepository(bunDB)),
+		outboxStore,
+	)
+	statementsRepo := statements.NewRepository(bunDB)
+	statementsSvc := statements.NewService(statementsRepo, accountsSvc)
+
+	accrualsSvc := accruals.NewService(
+		accruals.NewRepository(bunDB),
+		accountsSvc,
+		statementsSvc,
+		transactionsSvc,
+		accruals.Policy{
+			MonthlyInterestRate: decimal.RequireFromString("0.12"),
+			LateFee:             money.MustParse("10"),
+			PenaltyRate:         decimal.RequireFromString("0.02"),
+		},
+	)
+	accruals.SetupHTTPRoutes(router, accrualsSvc)
+
+	server, client := testutils.MakeTestHTTPServer(router)
+	defer server.Close()
+
+	creditLimit := money.NewFromInt(5000)
+	account, err := accountsSvc.CreateAccount(ctx, &accounts.CreateAccountRequest{
+		DocumentNumber:       "66895932070",
+		AvailableCreditLimit: &creditLimit,
+	})
+	assert.NoError(t, err)
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	// a statement already overdue, without any payments
+	err = statementsRepo.CreateStatement(ctx, &statements.Statement{
+		AccountID:      account.ID,
+		Currency:       account.Currency,
+		PeriodStart:    today.AddDate(0, -1, -20),
+		ClosingDate:    today.AddDate(0, 0, -20),
+		DueDate:        today.AddDate(0, 0, -10),
+		OpeningBalance: money.NewFromInt(0),
+		Purchases:      money.MustParse("-1000"),
+		Withdrawals:    money.NewFromInt(0),
+		Payments:       money.NewFromInt(0),
+		Other:          money.NewFromInt(0),
+		ClosingBalance: money.MustParse("-1000"),
+		MinimumPayment: money.MustParse("150"),
+		CreatedAt:      now,
+	})
+	assert.NoError(t, err)
+
+	accrue := func(t *testing.T, date time.Time, dryRun bool) *accruals.ReportAPIResponse {
+		jsonPayload, err := json.Marshal(map[string]any{
+			"date":    date.Format("2006-01-02"),
+			"dry_run": dryRun,
+		})
+		assert.NoError(t, err)
+
+		resp, err := client.Post(server.URL+"/admin/accruals", ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		report := &accruals.ReportAPIResponse{}
+		err = json.NewDecoder(resp.Body).Decode(report)
+		assert.NoError(t, err)
+
+		return report
+	}
+
+	listCharges := func(t *testing.T) []*transactions.Transaction {
+		charges := []*transactions.Transaction{}
+
+		for _, opType := range []operationtypes.Type{
+			operationtypes.InterestType,
+			operationtypes.LateFeeType,
+			operationtypes.PenaltyType,
+		} {
+			page, err := transactionsSvc.ListAccountTransactions(ctx, &transactions.ListTransactionsRequest{
+				AccountID:       account.ID,
+				OperationTypeID: opType,
+			})
+			assert.NoError(t, err)
+
+			charges = append(charges, page.Items...)
+		}
+
+		return charges
+	}
+
+	firstOverdueDay := today.AddDate(0, 0, -9)
+
+	t.Run("POST /admin/accruals", func(t *testing.T) {
+		t.Run("should report the charges without posting them in dry run mode", func(t *testing.T) {
+			report := accrue(t, firstOverdueDay, true)
+			assert.True(t, report.DryRun)
+			assert.Len(t, report.Accruals, 1)
+			assert.Equal(t, "33.78", report.Totals.Total.String())
+
+			assert.Empty(t, listCharges(t))
+		})
+
+		t.Run("should post the interest, late fee and penalty", func(t *testing.T) {
+			report := accrue(t, firstOverdueDay, false)
+			assert.Len(t, report.Accruals, 1)
+
+			accrual := report.Accruals[0]
+			assert.Equal(t, account.ID, accrual.AccountID)
+			assert.Equal(t, "1000.00", accrual.OverdueBalance.String())
+			assert.Equal(t, "3.78", accrual.Interest.String())
+			assert.Equal(t, "10.00", accrual.LateFee.String())
+			assert.Equal(t, "20.00", accrual.Penalty.String())
+
+			assert.Len(t, listCharges(t), 3)
+		})
+
+		t.Run("should be idempotent per account and day", func(t *testing.T) {
+			report := accrue(t, firstOverdueDay, false)
+			assert.Equal(t, 1, report.AlreadyAccrued)
+			assert.Empty(t, report.Accruals)
+
+			assert.Len(t, listCharges(t), 3)
+		})
+
+		t.Run("should compound the interest on the next days", func(t *testing.T) {
+			report := accrue(t, today, false)
+			assert.Len(t, report.Accruals, 1)
+
+			accrual := report.Accruals[0]
+			assert.Equal(t, "1033.78", accrual.OverdueBalance.String())
+			assert.Equal(t, "3.91", accrual.Interest.String())
+			assert.True(t, accrual.LateFee.IsZero())
+			assert.True(t, accrual.Penalty.IsZero())
+
+			assert.Len(t, listCharges(t), 4)
+		})
+
+		t.Run("should return error if the date is in the future", func(t *testing.T) {
+			resp, err := client.Post(
+				server.URL+"/admin/accruals",
+				ContentTypeJSON,
+				bytes.NewBufferString(fmt.Sprintf(`{"date":%q}`, today.AddDate(0, 0, 1).Format("2006-01-02"))),
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	})
+}
@@ -67,6 +67,22 @@ func TestStatusApis(t *testing.T) {
 		})
 	})
 
+	t.Run("GET /metrics", func(t *testing.T) {
+		t.Run("should return the http and database metrics", func(t *testing.T) {
+			_, err := client.Get(server.URL + "/status")
+			assert.NoError(t, err)
+
+			resp, err := client.Get(server.URL + "/metrics")
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			assert.Contains(t, string(body), `http_requests_total{method="GET",route="/status",status="200"}`)
+			assert.Contains(t, string(body), `go_sql_open_connections{db_name="main"}`)
+		})
+	})
+
 	t.Run("GET /healthcheck/readiness", func(t *testing.T) {
 		t.Run("should return OK status if database connection is active", func(t *testing.T) {
 			resp, err := client.Get(server.URL + "/healthcheck/readiness")
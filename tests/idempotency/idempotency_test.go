@@ -0,0 +1,203 @@
+package idempotency_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/fxrates"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/transactions"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/config"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/database"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/httprouter"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/logger"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/outbox"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/testutils"
+)
+
+const ContentTypeJSON = "application/json"
+
+func TestIdempotency(t *testing.T) {
+	err := testutils.SetRootCwd()
+	assert.NoError(t, err)
+
+	logger := logger.NewStubLogger()
+
+	cfg, err := config.LoadConfig()
+	assert.NoError(t, err)
+
+	cfg.IsProduction = true
+
+	testDB, err := testutils.NewTestDatabase(cfg.DatabaseURL)
+	assert.NoError(t, err)
+
+	defer testDB.Drop()
+
+	sqlDB, bunDB, err := database.NewDatabase(testDB.URL)
+	assert.NoError(t, err)
+
+	err = database.RunMigrations(sqlDB)
+	assert.NoError(t, err)
+
+	router := httprouter.NewRouter(logger, cfg.IsProduction)
+	idempotency := httprouter.Idempotency(bunDB)
+
+	opTypesRepo := operationtypes.NewRepository(bunDB)
+	opTypesSvc := operationtypes.NewService(opTypesRepo)
+	err = opTypesSvc.LoadOperationTypes(context.Background())
+	assert.NoError(t, err)
+	operationtypes.SetupHTTPRoutes(router, opTypesSvc, idempotency)
+
+	outboxStore := outbox.NewStore(bunDB)
+
+	accountsRepo := accounts.NewRepository(bunDB, testutils.NewTestKeyring())
+	accountsSvc := accounts.NewService(accountsRepo, outboxStore, money.New(cfg.AccountDefaultCreditLimit))
+	accounts.SetupHTTPRoutes(router, accountsSvc, idempotency)
+
+	fxRatesRepo := fxrates.NewRepository(bunDB)
+	fxRatesSvc := fxrates.NewService(fxRatesRepo)
+	fxrates.SetupHTTPRoutes(router, fxRatesSvc, idempotency)
+
+	transactionsRepo := transactions.NewRepository(bunDB)
+	transactionsSvc := transactions.NewService(transactionsRepo, accountsSvc, opTypesSvc, fxRatesSvc, outboxStore)
+	transactions.SetupHTTPRoutes(router, transactionsSvc, idempotency)
+
+	server, client := testutils.MakeTestHTTPServer(router)
+	defer server.Close()
+
+	post := func(path string, key string, payload map[string]any) (*http.Response, []byte) {
+		jsonPayload, err := json.Marshal(payload)
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, server.URL+path, bytes.NewBuffer(jsonPayload))
+		assert.NoError(t, err)
+
+		req.Header.Set("Content-Type", ContentTypeJSON)
+		req.Header.Set(httprouter.IdempotencyKeyHeader, key)
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		return resp, body
+	}
+
+	accountPayload := map[string]any{
+		"document_number":        "98765432100",
+		"available_credit_limit": 1000,
+	}
+
+	resp, accountBody := post("/accounts", "create-account-key", accountPayload)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	account := accounts.AccountAPIResponse{}
+	err = json.Unmarshal(accountBody, &account)
+	assert.NoError(t, err)
+
+	t.Run("should replay the original response", func(t *testing.T) {
+		resp, body := post("/accounts", "create-account-key", accountPayload)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "true", resp.Header.Get(httprouter.IdempotentReplayedHeader))
+		assert.JSONEq(t, string(accountBody), string(body))
+	})
+
+	t.Run("should replay error responses", func(t *testing.T) {
+		payload := map[string]any{"document_number": "abc", "available_credit_limit": 10}
+
+		resp, body := post("/accounts", "invalid-account-key", payload)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, replayedBody := post("/accounts", "invalid-account-key", payload)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, "true", resp.Header.Get(httprouter.IdempotentReplayedHeader))
+		assert.Equal(t, body, replayedBody)
+	})
+
+	t.Run("should return conflict if key is reused with a different payload", func(t *testing.T) {
+		resp, _ := post("/accounts", "create-account-key", map[string]any{
+			"document_number":        "11144477735",
+			"available_credit_limit": 1000,
+		})
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+		resp, _ = post("/transactions", "create-account-key", accountPayload)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("should return error if key is too long", func(t *testing.T) {
+		resp, _ := post("/accounts", strings.Repeat("a", 256), accountPayload)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("should serialize concurrent requests with the same key", func(t *testing.T) {
+		payload := map[string]any{
+			"account_id":        account.AccountID,
+			"operation_type_id": operationtypes.CashPurchaseType,
+			"amount":            -10.5,
+		}
+
+		jsonPayload, err := json.Marshal(payload)
+		assert.NoError(t, err)
+
+		wg := sync.WaitGroup{}
+		statusCodes := make([]int, 5)
+		bodies := make([][]byte, 5)
+
+		for i := range bodies {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				req, _ := http.NewRequest(http.MethodPost, server.URL+"/transactions", bytes.NewBuffer(jsonPayload))
+				req.Header.Set("Content-Type", ContentTypeJSON)
+				req.Header.Set(httprouter.IdempotencyKeyHeader, "create-transaction-key")
+
+				resp, err := client.Do(req)
+				if err != nil {
+					return
+				}
+
+				defer resp.Body.Close()
+
+				statusCodes[i] = resp.StatusCode
+				bodies[i], _ = io.ReadAll(resp.Body)
+			}(i)
+		}
+
+		wg.Wait()
+
+		for i, body := range bodies {
+			assert.Equal(t, http.StatusCreated, statusCodes[i])
+			assert.JSONEq(t, string(bodies[0]), string(body))
+		}
+
+		resp, err := client.Get(fmt.Sprintf("%s/accounts/%d/transactions", server.URL, account.AccountID))
+		assert.NoError(t, err)
+
+		page := transactions.TransactionsPageAPIResponse{}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		assert.NoError(t, err)
+
+		assert.Len(t, page.Items, 1)
+	})
+
+	t.Run("should not store requests without key", func(t *testing.T) {
+		resp, _ := post("/accounts", "", accountPayload)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get(httprouter.IdempotentReplayedHeader))
+	})
+}
@@ -0,0 +1,210 @@
+package operationtypes_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/config"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/database"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/httprouter"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/logger"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/testutils"
+)
+
+const ContentTypeJSON = "application/json"
+
+func TestOperationTypesAPIs(t *testing.T) {
+	err := testutils.SetRootCwd()
+	assert.NoError(t, err)
+
+	logger := logger.NewStubLogger()
+
+	cfg, err := config.LoadConfig()
+	assert.NoError(t, err)
+
+	cfg.IsProduction = true
+
+	testDB, err := testutils.NewTestDatabase(cfg.DatabaseURL)
+	assert.NoError(t, err)
+
+	defer testDB.Drop()
+
+	sqlDB, bunDB, err := database.NewDatabase(testDB.URL)
+	assert.NoError(t, err)
+
+	err = database.RunMigrations(sqlDB)
+	assert.NoError(t, err)
+
+	repo := operationtypes.NewRepository(bunDB)
+	svc := operationtypes.NewService(repo)
+
+	err = svc.LoadOperationTypes(context.Background())
+	assert.NoError(t, err)
+
+	router := httprouter.NewRouter(logger, cfg.IsProduction)
+	operationtypes.SetupHTTPRoutes(router, svc)
+
+	server, client := testutils.MakeTestHTTPServer(router)
+	defer server.Close()
+
+	listOperationTypes := func(t *testing.T) []*operationtypes.OperationTypeAPIResponse {
+		resp, err := client.Get(server.URL + "/operation-types")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		respData := []*operationtypes.OperationTypeAPIResponse{}
+		err = json.NewDecoder(resp.Body).Decode(&respData)
+		assert.NoError(t, err)
+
+		return respData
+	}
+
+	t.Run("GET /operation-types", func(t *testing.T) {
+		t.Run("should list the default operation types", func(t *testing.T) {
+			assert.Equal(t, []*operationtypes.OperationTypeAPIResponse{
+				{OperationTypeID: 1, Description: "CASH PURCHASE", Sign: -1, AffectsCreditLimit: true, Category: "purchase"},
+				{OperationTypeID: 2, Description: "INSTALLMENT PURCHASE", Sign: -1, AffectsCreditLimit: true, Category: "purchase"},
+				{OperationTypeID: 3, Description: "WITHDRAWAL", Sign: -1, AffectsCreditLimit: true, Category: "withdrawal"},
+				{OperationTypeID: 4, Description: "PAYMENT", Sign: 1, AffectsCreditLimit: true, Category: "payment"},
+				{OperationTypeID: 5, Description: "INTEREST", Sign: -1, Category: "other", SystemGenerated: true},
+				{OperationTypeID: 6, Description: "LATE FEE", Sign: -1, Category: "other", SystemGenerated: true},
+				{OperationTypeID: 7, Description: "LATE PAYMENT PENALTY", Sign: -1, Category: "other", SystemGenerated: true},
+			}, listOperationTypes(t))
+		})
+	})
+
+	t.Run("POST /admin/operation-types", func(t *testing.T) {
+		t.Run("should create a new operation type", func(t *testing.T) {
+			jsonPayload, err := json.Marshal(map[string]any{
+				"operation_type_id":    8,
+				"description":          "ANNUAL FEE",
+				"sign":                 -1,
+				"affects_credit_limit": false,
+			})
+			assert.NoError(t, err)
+
+			resp, err := client.Post(server.URL+"/admin/operation-types", ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+			opTypes := listOperationTypes(t)
+			assert.Len(t, opTypes, 8)
+			assert.Equal(t, &operationtypes.OperationTypeAPIResponse{
+				OperationTypeID:    8,
+				Description:        "ANNUAL FEE",
+				Sign:               -1,
+				AffectsCreditLimit: false,
+				Category:           operationtypes.OtherCategory,
+			}, opTypes[7])
+		})
+
+		t.Run("should return error if payload is invalid", func(t *testing.T) {
+			jsonPayload, err := json.Marshal(map[string]any{
+				"operation_type_id":    9,
+				"description":          "FEE",
+				"sign":                 0,
+				"affects_credit_limit": false,
+			})
+			assert.NoError(t, err)
+
+			resp, err := client.Post(server.URL+"/admin/operation-types", ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("should return error if operation type already exists", func(t *testing.T) {
+			jsonPayload, err := json.Marshal(map[string]any{
+				"operation_type_id":    1,
+				"description":          "CASH PURCHASE",
+				"sign":                 -1,
+				"affects_credit_limit": true,
+			})
+			assert.NoError(t, err)
+
+			resp, err := client.Post(server.URL+"/admin/operation-types", ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		})
+	})
+
+	t.Run("PUT /admin/operation-types/{id}", func(t *testing.T) {
+		t.Run("should update the operation type", func(t *testing.T) {
+			resp, err := Put(
+				client,
+				server.URL+"/admin/operation-types/8",
+				`{"description":"YEARLY FEE","sign":-1,"affects_credit_limit":true}`,
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			respData := operationtypes.OperationTypeAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+
+			assert.Equal(t, "YEARLY FEE", respData.Description)
+			assert.True(t, respData.AffectsCreditLimit)
+			assert.Equal(t, operationtypes.OtherCategory, respData.Category)
+		})
+
+		t.Run("should return not found if can't find operation type", func(t *testing.T) {
+			resp, err := Put(
+				client,
+				server.URL+"/admin/operation-types/99",
+				`{"description":"UNKNOWN","sign":1,"affects_credit_limit":true}`,
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+			resp, err = Put(client, server.URL+"/admin/operation-types/abc", `{}`)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		})
+
+		t.Run("should return error if changing the sign of a type with transactions", func(t *testing.T) {
+			_, err := bunDB.ExecContext(context.Background(), `
+				WITH account AS (
+					INSERT INTO accounts (document_number, document_type, created_at, updated_at)
+					VALUES ('27935572003', 'cpf', now(), now())
+					RETURNING id
+				)
+				INSERT INTO transactions (account_id, operation_type_id, amount, balance, currency, event_date)
+				SELECT id, 8, -10, -10, 'BRL', now() FROM account
+			`)
+			assert.NoError(t, err)
+
+			resp, err := Put(
+				client,
+				server.URL+"/admin/operation-types/8",
+				`{"description":"YEARLY FEE","sign":1,"affects_credit_limit":true}`,
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+			resp, err = Put(
+				client,
+				server.URL+"/admin/operation-types/8",
+				`{"description":"ANNUAL FEE","sign":-1,"affects_credit_limit":true}`,
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	})
+}
+
+func Put(client *http.Client, url string, body string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPut, url, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", ContentTypeJSON)
+
+	return client.Do(req)
+}
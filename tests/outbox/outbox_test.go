@@ -0,0 +1,109 @@
+package outbox_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/fxrates"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/transactions"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/config"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/database"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/logger"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/outbox"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/testutils"
+)
+
+func TestOutboxRelay(t *testing.T) {
+	err := testutils.SetRootCwd()
+	assert.NoError(t, err)
+
+	logger := logger.NewStubLogger()
+
+	cfg, err := config.LoadConfig()
+	assert.NoError(t, err)
+
+	testDB, err := testutils.NewTestDatabase(cfg.DatabaseURL)
+	assert.NoError(t, err)
+
+	defer testDB.Drop()
+
+	sqlDB, bunDB, err := database.NewDatabase(testDB.URL)
+	assert.NoError(t, err)
+
+	err = database.RunMigrations(sqlDB)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+
+	opTypesSvc := operationtypes.NewService(operationtypes.NewRepository(bunDB))
+	err = opTypesSvc.LoadOperationTypes(ctx)
+	assert.NoError(t, err)
+
+	outboxStore := outbox.NewStore(bunDB)
+	accountsSvc := accounts.NewService(
+		accounts.NewRepository(bunDB, testutils.NewTestKeyring()),
+		outboxStore,
+		money.New(cfg.AccountDefaultCreditLimit),
+	)
+	transactionsSvc := transactions.NewService(
+		transactions.NewRepository(bunDB),
+		accountsSvc,
+		opTypesSvc,
+		fxrates.NewService(fxrates.NewRepository(bunDB)),
+		outboxStore,
+	)
+
+	publisher := outbox.NewMemoryPublisher()
+	relay := outbox.NewRelay(outboxStore, publisher, logger, time.Second)
+
+	t.Run("should publish the events written with the changes", func(t *testing.T) {
+		creditLimit := money.NewFromInt(100)
+
+		account, err := accountsSvc.CreateAccount(ctx, &accounts.CreateAccountRequest{
+			DocumentNumber:       "66895932070",
+			AvailableCreditLimit: &creditLimit,
+		})
+		assert.NoError(t, err)
+
+		transaction, err := transactionsSvc.CreateTransaction(ctx, &transactions.CreateTransactionRequest{
+			AccountID:       account.ID,
+			OperationTypeID: operationtypes.CashPurchaseType,
+			Amount:          money.MustParse("-50"),
+		})
+		assert.NoError(t, err)
+
+		_, err = transactionsSvc.CreateTransaction(ctx, &transactions.CreateTransactionRequest{
+			AccountID:       account.ID,
+			OperationTypeID: operationtypes.CashPurchaseType,
+			Amount:          money.MustParse("-500"),
+		})
+		assert.ErrorIs(t, err, transactions.ErrInsufficientCreditLimit(nil))
+
+		published, err := relay.PublishPending(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, published)
+
+		events := publisher.Events()
+		assert.Len(t, events, 2)
+		assert.Equal(t, accounts.AccountCreatedEvent, events[0].Type)
+		assert.Equal(t, account.ID, events[0].AccountID)
+		assert.Equal(t, transactions.TransactionCreatedEvent, events[1].Type)
+		assert.Equal(t, account.ID, events[1].AccountID)
+		assert.Contains(t, string(events[1].Payload), `"transaction_id":`)
+		assert.Less(t, events[0].ID, events[1].ID)
+		assert.NotZero(t, transaction.ID)
+	})
+
+	t.Run("should not publish the events again", func(t *testing.T) {
+		published, err := relay.PublishPending(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, published)
+		assert.Len(t, publisher.Events(), 2)
+	})
+}
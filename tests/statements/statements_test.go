@@ -0,0 +1,193 @@
+package statements_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/fxrates"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/statements"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/transactions"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/config"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/database"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/httprouter"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/logger"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/outbox"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/testutils"
+)
+
+func TestStatementsAPIs(t *testing.T) {
+	err := testutils.SetRootCwd()
+	assert.NoError(t, err)
+
+	logger := logger.NewStubLogger()
+
+	cfg, err := config.LoadConfig()
+	assert.NoError(t, err)
+
+	cfg.IsProduction = true
+
+	testDB, err := testutils.NewTestDatabase(cfg.DatabaseURL)
+	assert.NoError(t, err)
+
+	defer testDB.Drop()
+
+	sqlDB, bunDB, err := database.NewDatabase(testDB.URL)
+	assert.NoError(t, err)
+
+	err = database.RunMigrations(sqlDB)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	router := httprouter.NewRouter(logger, cfg.IsProduction)
+
+	opTypesSvc := operationtypes.NewService(operationtypes.NewRepository(bunDB))
+	err = opTypesSvc.LoadOperationTypes(ctx)
+	assert.NoError(t, err)
+
+	outboxStore := outbox.NewStore(bunDB)
+	accountsSvc := accounts.NewService(
+		accounts.NewRepository(bunDB, testutils.NewTestKeyring()),
+		outboxStore,
+		money.New(cfg.AccountDefaultCreditLimit),
+	)
+	transactionsSvc := transactions.NewService(
+		transactions.NewRepository(bunDB),
+		accountsSvc,
+		opTypesSvc,
+		fxrates.NewService(fxrates.NewRepository(bunDB)),
+		outboxStore,
+	)
+
+	statementsSvc := statements.NewService(statements.NewRepository(bunDB), accountsSvc)
+	statements.SetupHTTPRoutes(router, statementsSvc)
+
+	server, client := testutils.MakeTestHTTPServer(router)
+	defer server.Close()
+
+	creditLimit := money.NewFromInt(1000)
+	account, err := accountsSvc.CreateAccount(ctx, &accounts.CreateAccountRequest{
+		DocumentNumber:       "66895932070",
+		AvailableCreditLimit: &creditLimit,
+		ClosingDay:           10,
+		DueDay:               20,
+	})
+	assert.NoError(t, err)
+
+	for _, req := range []*transactions.CreateTransactionRequest{
+		{AccountID: account.ID, OperationTypeID: operationtypes.CashPurchaseType, Amount: money.MustParse("-100")},
+		{AccountID: account.ID, OperationTypeID: operationtypes.WithdrawType, Amount: money.MustParse("-50")},
+		{AccountID: account.ID, OperationTypeID: operationtypes.PaymentType, Amount: money.MustParse("30")},
+	} {
+		_, err := transactionsSvc.CreateTransaction(ctx, req)
+		assert.NoError(t, err)
+	}
+
+	// two months ahead, so at least the first cycle is closed
+	created, err := statementsSvc.CloseCycles(ctx, time.Now().AddDate(0, 2, 0))
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, created, 1)
+
+	t.Run("should not close the same cycles twice", func(t *testing.T) {
+		created, err := statementsSvc.CloseCycles(ctx, time.Now().AddDate(0, 2, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, created)
+	})
+
+	var firstStatement statements.StatementAPIResponse
+
+	t.Run("GET /accounts/{id}/statements", func(t *testing.T) {
+		t.Run("should list the account statements", func(t *testing.T) {
+			resp, err := client.Get(fmt.Sprintf("%s/accounts/%d/statements", server.URL, account.ID))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			respData := []statements.StatementAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+			assert.Len(t, respData, created)
+
+			// ordered by the most recent cycle
+			firstStatement = respData[len(respData)-1]
+			assert.Equal(t, time.Now().UTC().Format("2006-01-02"), firstStatement.PeriodStart)
+			assert.Equal(t, "0.00", firstStatement.OpeningBalance.String())
+			assert.Equal(t, "-100.00", firstStatement.Purchases.String())
+			assert.Equal(t, "-50.00", firstStatement.Withdrawals.String())
+			assert.Equal(t, "30.00", firstStatement.Payments.String())
+			assert.Equal(t, "-120.00", firstStatement.ClosingBalance.String())
+			assert.Equal(t, "18.00", firstStatement.MinimumPayment.String())
+			assert.Equal(t, 3, firstStatement.TransactionsCount)
+			assert.Equal(t, "10", firstStatement.ClosingDate[8:])
+			assert.Equal(t, "20", firstStatement.DueDate[8:])
+		})
+
+		t.Run("should return not found if the account doesn't exist", func(t *testing.T) {
+			resp, err := client.Get(server.URL + "/accounts/12345/statements")
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		})
+	})
+
+	t.Run("GET /statements/{id}", func(t *testing.T) {
+		t.Run("should return the statement", func(t *testing.T) {
+			resp, err := client.Get(fmt.Sprintf("%s/statements/%d", server.URL, firstStatement.StatementID))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			respData := statements.StatementAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+			assert.Equal(t, firstStatement, respData)
+		})
+
+		t.Run("should return not found if the statement doesn't exist", func(t *testing.T) {
+			resp, err := client.Get(server.URL + "/statements/12345")
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		})
+	})
+	t.Run("should bill the installment purchases by their schedule", func(t *testing.T) {
+		account, err := accountsSvc.CreateAccount(ctx, &accounts.CreateAccountRequest{
+			DocumentNumber: "52998224725",
+			ClosingDay:     10,
+			DueDay:         20,
+		})
+		assert.NoError(t, err)
+
+		_, err = transactionsSvc.CreateTransaction(ctx, &transactions.CreateTransactionRequest{
+			AccountID:       account.ID,
+			OperationTypeID: operationtypes.InstallmentType,
+			Amount:          money.MustParse("-300"),
+			Installments:    3,
+		})
+		assert.NoError(t, err)
+
+		_, err = statementsSvc.CloseCycles(ctx, time.Now().AddDate(0, 5, 0))
+		assert.NoError(t, err)
+
+		accountStatements, err := statementsSvc.ListAccountStatements(ctx, account.ID)
+		assert.NoError(t, err)
+
+		purchases := money.NewFromInt(0)
+		transactionsCount := 0
+
+		for _, statement := range accountStatements {
+			assert.False(t, statement.Purchases.LessThan(money.MustParse("-100")))
+			assert.Equal(t, statement.MinimumPayment, statements.MinimumPayment(statement.ClosingBalance))
+
+			purchases = purchases.Add(statement.Purchases)
+			transactionsCount += statement.TransactionsCount
+		}
+
+		assert.Equal(t, "-300.00", purchases.String())
+		assert.Equal(t, 1, transactionsCount)
+	})
+}
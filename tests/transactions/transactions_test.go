@@ -2,9 +2,14 @@ package transactions_test
 
 import (
 	"bytes"
+	"context"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
@@ -12,12 +17,15 @@ import (
 	assert "github.com/stretchr/testify/require"
 
 	"github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/fxrates"
 	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
 	"github.com/rudineirk/pismo-challenge/pkg/domains/transactions"
 	"github.com/rudineirk/pismo-challenge/pkg/infra/config"
 	"github.com/rudineirk/pismo-challenge/pkg/infra/database"
 	"github.com/rudineirk/pismo-challenge/pkg/infra/httprouter"
 	"github.com/rudineirk/pismo-challenge/pkg/infra/logger"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/outbox"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
 	"github.com/rudineirk/pismo-challenge/pkg/utils/testutils"
 )
 
@@ -47,18 +55,30 @@ func TestTransactionsAPIs(t *testing.T) {
 
 	router := httprouter.NewRouter(logger, cfg.IsProduction)
 
-	accountsRepo := accounts.NewRepository(bunDB)
-	accountsSvc := accounts.NewService(accountsRepo)
+	opTypesRepo := operationtypes.NewRepository(bunDB)
+	opTypesSvc := operationtypes.NewService(opTypesRepo)
+	err = opTypesSvc.LoadOperationTypes(context.Background())
+	assert.NoError(t, err)
+	operationtypes.SetupHTTPRoutes(router, opTypesSvc)
+
+	outboxStore := outbox.NewStore(bunDB)
+
+	accountsRepo := accounts.NewRepository(bunDB, testutils.NewTestKeyring())
+	accountsSvc := accounts.NewService(accountsRepo, outboxStore, money.New(cfg.AccountDefaultCreditLimit))
 	accounts.SetupHTTPRoutes(router, accountsSvc)
 
+	fxRatesRepo := fxrates.NewRepository(bunDB)
+	fxRatesSvc := fxrates.NewService(fxRatesRepo)
+	fxrates.SetupHTTPRoutes(router, fxRatesSvc)
+
 	transactionsRepo := transactions.NewRepository(bunDB)
-	transactionsSvc := transactions.NewService(transactionsRepo, accountsSvc)
+	transactionsSvc := transactions.NewService(transactionsRepo, accountsSvc, opTypesSvc, fxRatesSvc, outboxStore)
 	transactions.SetupHTTPRoutes(router, transactionsSvc)
 
 	server, client := testutils.MakeTestHTTPServer(router)
 	defer server.Close()
 
-	accountID, err := CreateAccount(server, client)
+	accountID, err := CreateAccount(server, client, "27935572003")
 	assert.NoError(t, err)
 
 	t.Run("POST /transactions", func(t *testing.T) {
@@ -71,7 +91,7 @@ func TestTransactionsAPIs(t *testing.T) {
 			{
 				"account_id":        accountID,
 				"operation_type_id": operationtypes.InstallmentType,
-				"amount":            -0.01,
+				"amount":            "-0.01",
 			},
 			{
 				"account_id":        accountID,
@@ -101,7 +121,7 @@ func TestTransactionsAPIs(t *testing.T) {
 				assert.NotEqual(t, int64(0), respData.TransactionID)
 				assert.Equal(t, accountID, respData.AccountID)
 				assert.Equal(t, req["operation_type_id"], respData.OperationTypeID)
-				assert.Equal(t, req["amount"], respData.Amount)
+				assert.Equal(t, money.MustParse(fmt.Sprint(req["amount"])).String(), respData.Amount.String())
 				assert.WithinDuration(t, time.Now(), respData.EventDate, 20*time.Millisecond)
 			})
 		}
@@ -139,7 +159,7 @@ func TestTransactionsAPIs(t *testing.T) {
 		t.Run("should return error if operation type is invalid", func(t *testing.T) {
 			jsonPayload, err := json.Marshal(map[string]any{
 				"account_id":        accountID,
-				"operation_type_id": 5,
+				"operation_type_id": 99,
 				"amount":            1.1,
 			})
 			assert.NoError(t, err)
@@ -149,6 +169,41 @@ func TestTransactionsAPIs(t *testing.T) {
 			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 		})
 
+		t.Run("should return error if operation type is system generated", func(t *testing.T) {
+			jsonPayload, err := json.Marshal(map[string]any{
+				"account_id":        accountID,
+				"operation_type_id": operationtypes.InterestType,
+				"amount":            -1.1,
+			})
+			assert.NoError(t, err)
+
+			resp, err := client.Post(server.URL+"/transactions", ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("should return error if credit limit is insufficient", func(t *testing.T) {
+			jsonPayload, err := json.Marshal(map[string]any{
+				"account_id":        accountID,
+				"operation_type_id": operationtypes.CashPurchaseType,
+				"amount":            -1000.01,
+			})
+			assert.NoError(t, err)
+
+			resp, err := client.Post(server.URL+"/transactions", ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+			resp, err = client.Get(fmt.Sprintf("%s/accounts/%d", server.URL, accountID))
+			assert.NoError(t, err)
+
+			account := accounts.AccountAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&account)
+			assert.NoError(t, err)
+
+			assert.Equal(t, "999.75", account.AvailableCreditLimit.String())
+		})
+
 		t.Run("should return error if amount is invalid", func(t *testing.T) {
 			jsonPayload, err := json.Marshal(map[string]any{
 				"account_id":        accountID,
@@ -162,11 +217,588 @@ func TestTransactionsAPIs(t *testing.T) {
 			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 		})
 	})
+
+	t.Run("POST /transactions on blocked accounts", func(t *testing.T) {
+		blockedAccountID, err := CreateAccount(server, client, "86124573946")
+		assert.NoError(t, err)
+
+		_, err = accountsSvc.ChangeStatus(context.TODO(), &accounts.ChangeStatusRequest{
+			AccountID: blockedAccountID,
+			Status:    accounts.BlockedStatus,
+			Reason:    "card lost",
+		})
+		assert.NoError(t, err)
+
+		t.Run("should reject purchases", func(t *testing.T) {
+			jsonPayload, err := json.Marshal(map[string]any{
+				"account_id":        blockedAccountID,
+				"operation_type_id": operationtypes.CashPurchaseType,
+				"amount":            -10,
+			})
+			assert.NoError(t, err)
+
+			resp, err := client.Post(server.URL+"/transactions", ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		})
+
+		t.Run("should accept payments", func(t *testing.T) {
+			jsonPayload, err := json.Marshal(map[string]any{
+				"account_id":        blockedAccountID,
+				"operation_type_id": operationtypes.PaymentType,
+				"amount":            10,
+			})
+			assert.NoError(t, err)
+
+			resp, err := client.Post(server.URL+"/transactions", ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		})
+	})
+
+	t.Run("POST /transactions with currency", func(t *testing.T) {
+		fxAccountID, err := CreateAccount(server, client, "22255588846")
+		assert.NoError(t, err)
+
+		resp, err := client.Post(server.URL+"/admin/fx-rates", fxrates.ContentTypeCSV, strings.NewReader(
+			"from_currency,to_currency,rate,effective_at\n"+
+				"USD,BRL,4.5,2023-01-01T00:00:00Z\n"+
+				"USD,BRL,4.9137,2023-11-01T00:00:00Z\n",
+		))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		t.Run("should convert the amount with the latest rate", func(t *testing.T) {
+			jsonPayload, err := json.Marshal(map[string]any{
+				"account_id":        fxAccountID,
+				"operation_type_id": operationtypes.CashPurchaseType,
+				"amount":            "-10.5",
+				"currency":          "USD",
+			})
+			assert.NoError(t, err)
+
+			resp, err := client.Post(server.URL+"/transactions", ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+			respData := transactions.TransactionAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+
+			assert.Equal(t, "BRL", respData.Currency)
+			assert.Equal(t, "-51.59", respData.Amount.String())
+			assert.Equal(t, "USD", respData.FXConversion.OriginalCurrency)
+			assert.Equal(t, "-10.50", respData.FXConversion.OriginalAmount.String())
+			assert.Equal(t, "4.9137", respData.FXConversion.Rate.String())
+		})
+
+		t.Run("should return error if there is no rate for the currency", func(t *testing.T) {
+			jsonPayload, err := json.Marshal(map[string]any{
+				"account_id":        fxAccountID,
+				"operation_type_id": operationtypes.CashPurchaseType,
+				"amount":            "-10",
+				"currency":          "JPY",
+			})
+			assert.NoError(t, err)
+
+			resp, err := client.Post(server.URL+"/transactions", ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		})
+	})
+
+	t.Run("GET /transactions/{id}/installments", func(t *testing.T) {
+		installmentsAccountID, err := CreateAccount(server, client, "13579246828")
+		assert.NoError(t, err)
+
+		postTransaction := func(t *testing.T, payload map[string]any) *http.Response {
+			payload["account_id"] = installmentsAccountID
+
+			jsonPayload, err := json.Marshal(payload)
+			assert.NoError(t, err)
+
+			resp, err := client.Post(server.URL+"/transactions", ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+			assert.NoError(t, err)
+
+			return resp
+		}
+
+		resp := postTransaction(t, map[string]any{
+			"operation_type_id": operationtypes.InstallmentType,
+			"amount":            "-100",
+			"installments":      3,
+		})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		purchase := transactions.TransactionAPIResponse{}
+		err = json.NewDecoder(resp.Body).Decode(&purchase)
+		assert.NoError(t, err)
+
+		resp = postTransaction(t, map[string]any{
+			"operation_type_id": operationtypes.PaymentType,
+			"amount":            "50",
+		})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		t.Run("should return the installments schedule", func(t *testing.T) {
+			resp, err := client.Get(fmt.Sprintf("%s/transactions/%d/installments", server.URL, purchase.TransactionID))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			respData := transactions.InstallmentPlanAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+
+			assert.Equal(t, purchase.TransactionID, respData.TransactionID)
+			assert.Equal(t, 3, respData.Installments)
+			assert.Equal(t, "-100.00", respData.TotalAmount.String())
+			assert.Equal(t, "50.00", respData.PaidAmount.String())
+
+			statuses := []transactions.InstallmentStatus{}
+			amounts := []string{}
+			for _, installment := range respData.Schedule {
+				statuses = append(statuses, installment.Status)
+				amounts = append(amounts, installment.Amount.String())
+			}
+
+			assert.Equal(t, []string{"-33.34", "-33.33", "-33.33"}, amounts)
+			assert.Equal(t, []transactions.InstallmentStatus{
+				transactions.InstallmentPaid,
+				transactions.InstallmentPartiallyPaid,
+				transactions.InstallmentUnpaid,
+			}, statuses)
+		})
+
+		t.Run("should return error if installments are invalid", func(t *testing.T) {
+			resp := postTransaction(t, map[string]any{
+				"operation_type_id": operationtypes.CashPurchaseType,
+				"amount":            "-100",
+				"installments":      3,
+			})
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("should mark the installments cancelled by a reversal", func(t *testing.T) {
+			jsonPayload, err := json.Marshal(map[string]any{"amount": "40"})
+			assert.NoError(t, err)
+
+			reversalURL := fmt.Sprintf("%s/transactions/%d/reversal", server.URL, purchase.TransactionID)
+			resp, err := client.Post(reversalURL, ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+			resp, err = client.Get(fmt.Sprintf("%s/transactions/%d/installments", server.URL, purchase.TransactionID))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			respData := transactions.InstallmentPlanAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+
+			assert.Equal(t, "50.00", respData.PaidAmount.String())
+			assert.Equal(t, "40.00", respData.ReversedAmount.String())
+
+			statuses := []transactions.InstallmentStatus{}
+			for _, installment := range respData.Schedule {
+				statuses = append(statuses, installment.Status)
+			}
+
+			assert.Equal(t, []transactions.InstallmentStatus{
+				transactions.InstallmentPaid,
+				transactions.InstallmentPartiallyPaid,
+				transactions.InstallmentReversed,
+			}, statuses)
+		})
+
+		t.Run("should return not found if transaction has no installments", func(t *testing.T) {
+			resp := postTransaction(t, map[string]any{
+				"operation_type_id": operationtypes.CashPurchaseType,
+				"amount":            "-10",
+			})
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+			respData := transactions.TransactionAPIResponse{}
+			err := json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+
+			resp, err = client.Get(fmt.Sprintf("%s/transactions/%d/installments", server.URL, respData.TransactionID))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		})
+	})
+
+	t.Run("POST /transactions discharge", func(t *testing.T) {
+		dischargeAccountID, err := CreateAccount(server, client, "12345678909")
+		assert.NoError(t, err)
+
+		createTransaction := func(t *testing.T, opType operationtypes.Type, amount string) *transactions.TransactionAPIResponse {
+			jsonPayload, err := json.Marshal(map[string]any{
+				"account_id":        dischargeAccountID,
+				"operation_type_id": opType,
+				"amount":            amount,
+			})
+			assert.NoError(t, err)
+
+			resp, err := client.Post(server.URL+"/transactions", ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+			respData := transactions.TransactionAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+
+			return &respData
+		}
+
+		listBalances := func(t *testing.T) map[int64]string {
+			resp, err := client.Get(fmt.Sprintf("%s/accounts/%d/transactions", server.URL, dischargeAccountID))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			respData := transactions.TransactionsPageAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+
+			balances := map[int64]string{}
+			for _, item := range respData.Items {
+				balances[item.TransactionID] = item.Balance.String()
+			}
+
+			return balances
+		}
+
+		first := createTransaction(t, operationtypes.CashPurchaseType, "-50")
+		second := createTransaction(t, operationtypes.CashPurchaseType, "-23.5")
+		third := createTransaction(t, operationtypes.WithdrawType, "-18.7")
+
+		t.Run("should pay down the oldest purchases first", func(t *testing.T) {
+			payment := createTransaction(t, operationtypes.PaymentType, "60")
+			assert.Equal(t, "0.00", payment.Balance.String())
+
+			assert.Equal(t, map[int64]string{
+				first.TransactionID:   "0.00",
+				second.TransactionID:  "-13.50",
+				third.TransactionID:   "-18.70",
+				payment.TransactionID: "0.00",
+			}, listBalances(t))
+		})
+
+		t.Run("should keep the leftover payment as credit", func(t *testing.T) {
+			payment := createTransaction(t, operationtypes.PaymentType, "100")
+			assert.Equal(t, "67.80", payment.Balance.String())
+
+			purchase := createTransaction(t, operationtypes.CashPurchaseType, "-80.1")
+			assert.Equal(t, "-12.30", purchase.Balance.String())
+
+			balances := listBalances(t)
+			assert.Equal(t, "0.00", balances[third.TransactionID])
+			assert.Equal(t, "0.00", balances[payment.TransactionID])
+		})
+	})
+
+	t.Run("POST /transactions/{id}/reversal", func(t *testing.T) {
+		reversalAccountID, err := CreateAccount(server, client, "52938476000171")
+		assert.NoError(t, err)
+
+		post := func(t *testing.T, url string, payload map[string]any) *http.Response {
+			jsonPayload, err := json.Marshal(payload)
+			assert.NoError(t, err)
+
+			resp, err := client.Post(url, ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+			assert.NoError(t, err)
+
+			return resp
+		}
+
+		decodeTransaction := func(t *testing.T, resp *http.Response) *transactions.TransactionAPIResponse {
+			respData := transactions.TransactionAPIResponse{}
+			err := json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+
+			return &respData
+		}
+
+		resp := post(t, server.URL+"/transactions", map[string]any{
+			"account_id":        reversalAccountID,
+			"operation_type_id": operationtypes.CashPurchaseType,
+			"amount":            "-100",
+		})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		purchase := decodeTransaction(t, resp)
+		reversalURL := fmt.Sprintf("%s/transactions/%d/reversal", server.URL, purchase.TransactionID)
+
+		t.Run("should partially reverse a transaction", func(t *testing.T) {
+			resp := post(t, reversalURL, map[string]any{"amount": "40"})
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+			reversal := decodeTransaction(t, resp)
+			assert.Equal(t, reversalAccountID, reversal.AccountID)
+			assert.Equal(t, operationtypes.CashPurchaseType, reversal.OperationTypeID)
+			assert.Equal(t, "40.00", reversal.Amount.String())
+			assert.Equal(t, "0.00", reversal.Balance.String())
+			assert.Equal(t, purchase.TransactionID, *reversal.ReversesTransactionID)
+		})
+
+		t.Run("should return error if amount is higher than the amount not reversed", func(t *testing.T) {
+			resp := post(t, reversalURL, map[string]any{"amount": "60.01"})
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("should reverse the remaining amount by default", func(t *testing.T) {
+			resp, err := client.Post(reversalURL, ContentTypeJSON, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+			reversal := decodeTransaction(t, resp)
+			assert.Equal(t, "60.00", reversal.Amount.String())
+
+			resp, err = client.Get(fmt.Sprintf("%s/accounts/%d", server.URL, reversalAccountID))
+			assert.NoError(t, err)
+
+			account := accounts.AccountAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&account)
+			assert.NoError(t, err)
+			assert.Equal(t, "1000.00", account.AvailableCreditLimit.String())
+		})
+
+		t.Run("should return error if transaction is already reversed", func(t *testing.T) {
+			resp := post(t, reversalURL, map[string]any{})
+			assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		})
+
+		t.Run("should return not found if can't find transaction", func(t *testing.T) {
+			resp := post(t, server.URL+"/transactions/999999999/reversal", map[string]any{})
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		})
+	})
+
+	t.Run("GET /accounts/{id}/balance", func(t *testing.T) {
+		t.Run("should return the account balance", func(t *testing.T) {
+			resp, err := client.Get(fmt.Sprintf("%s/accounts/%d/balance", server.URL, accountID))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			respData := accounts.BalanceAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+
+			assert.Equal(t, accountID, respData.AccountID)
+			assert.Equal(t, "-0.25", respData.Balance.String())
+			assert.Equal(t, "-1.16", respData.TotalPurchases.String())
+			assert.Equal(t, "-0.09", respData.TotalWithdrawals.String())
+			assert.Equal(t, "1.00", respData.TotalPayments.String())
+			assert.Len(t, respData.OperationTypes, 4)
+			assert.Equal(t, operationtypes.CashPurchaseType, respData.OperationTypes[0].OperationTypeID)
+			assert.Equal(t, "-1.15", respData.OperationTypes[0].Total.String())
+		})
+	})
+
+	t.Run("GET /accounts/{id}/transactions", func(t *testing.T) {
+		listURL := fmt.Sprintf("%s/accounts/%d/transactions", server.URL, accountID)
+
+		t.Run("should list the account transactions", func(t *testing.T) {
+			resp, err := client.Get(listURL)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			respData := transactions.TransactionsPageAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+
+			assert.Len(t, respData.Items, 4)
+			assert.Nil(t, respData.NextCursor)
+			assert.Equal(t, operationtypes.PaymentType, respData.Items[0].OperationTypeID)
+		})
+
+		t.Run("should paginate the account transactions", func(t *testing.T) {
+			resp, err := client.Get(listURL + "?limit=3")
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			firstPage := transactions.TransactionsPageAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&firstPage)
+			assert.NoError(t, err)
+
+			assert.Len(t, firstPage.Items, 3)
+			assert.NotNil(t, firstPage.NextCursor)
+
+			resp, err = client.Get(listURL + "?limit=3&cursor=" + url.QueryEscape(*firstPage.NextCursor))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			secondPage := transactions.TransactionsPageAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&secondPage)
+			assert.NoError(t, err)
+
+			assert.Len(t, secondPage.Items, 1)
+			assert.Nil(t, secondPage.NextCursor)
+			assert.Equal(t, operationtypes.CashPurchaseType, secondPage.Items[0].OperationTypeID)
+		})
+
+		t.Run("should filter the account transactions", func(t *testing.T) {
+			query := url.Values{}
+			query.Set("operation_type_id", "3")
+			query.Set("from", time.Now().Add(-time.Hour).Format(time.RFC3339))
+			query.Set("to", time.Now().Add(time.Hour).Format(time.RFC3339))
+
+			resp, err := client.Get(listURL + "?" + query.Encode())
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			respData := transactions.TransactionsPageAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+
+			assert.Len(t, respData.Items, 1)
+			assert.Equal(t, operationtypes.WithdrawType, respData.Items[0].OperationTypeID)
+		})
+
+		t.Run("should return error if filters are invalid", func(t *testing.T) {
+			resp, err := client.Get(listURL + "?cursor=invalid-cursor")
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+			resp, err = client.Get(listURL + "?limit=abc")
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("should return not found if can't find account", func(t *testing.T) {
+			resp, err := client.Get(server.URL + "/accounts/987/transactions")
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		})
+	})
+
+	t.Run("GET /accounts/{id}/transactions/export", func(t *testing.T) {
+		exportURL := fmt.Sprintf("%s/accounts/%d/transactions/export", server.URL, accountID)
+
+		t.Run("should export the account transactions as csv", func(t *testing.T) {
+			resp, err := client.Get(exportURL + "?format=csv")
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, transactions.ContentTypeCSV, resp.Header.Get("Content-Type"))
+			assert.Contains(t, resp.Header.Get("Content-Disposition"), "attachment")
+
+			records, err := csv.NewReader(resp.Body).ReadAll()
+			assert.NoError(t, err)
+
+			assert.Len(t, records, 5)
+			assert.Equal(t, "transaction_id", records[0][0])
+			assert.Equal(t, "CASH PURCHASE", records[1][3])
+			assert.Equal(t, "-1.15", records[1][4])
+			assert.Equal(t, "PAYMENT", records[4][3])
+		})
+
+		t.Run("should export the account transactions as ofx", func(t *testing.T) {
+			query := url.Values{}
+			query.Set("format", "ofx")
+			query.Set("from", time.Now().Add(-time.Hour).Format(time.RFC3339))
+
+			resp, err := client.Get(exportURL + "?" + query.Encode())
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, transactions.ContentTypeOFX, resp.Header.Get("Content-Type"))
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+
+			assert.Equal(t, 4, strings.Count(string(body), "<STMTTRN>"))
+			assert.Contains(t, string(body), "<NAME>WITHDRAWAL</NAME>")
+		})
+
+		t.Run("should return error if the request is invalid", func(t *testing.T) {
+			resp, err := client.Get(exportURL + "?format=pdf")
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+			resp, err = client.Get(server.URL + "/accounts/987/transactions/export?format=csv")
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		})
+	})
+
+	t.Run("POST /transactions/batch", func(t *testing.T) {
+		batchAccountID, err := CreateAccount(server, client, "71520463871")
+		assert.NoError(t, err)
+
+		t.Run("should import the ndjson lines reporting the failed ones", func(t *testing.T) {
+			body := fmt.Sprintf(`{"account_id":%d,"operation_type_id":1,"amount":"-10.00"}
+{"account_id":%d,"operation_type_id":1,"amount":"10.00"}
+{"account_id":%d,"operation_type_id":4,"amount":"5.00"}
+`, batchAccountID, batchAccountID, batchAccountID)
+
+			resp, err := client.Post(
+				server.URL+"/transactions/batch",
+				transactions.ContentTypeNDJSON,
+				strings.NewReader(body),
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			respData := transactions.BatchAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+
+			assert.Equal(t, 2, respData.Created)
+			assert.Equal(t, 1, respData.Failed)
+			assert.Len(t, respData.Lines, 3)
+			assert.Equal(t, transactions.BatchLineCreated, respData.Lines[0].Status)
+			assert.NotNil(t, respData.Lines[0].TransactionID)
+			assert.Equal(t, transactions.BatchLineFailed, respData.Lines[1].Status)
+			assert.Equal(t, "invalid_amount", respData.Lines[1].Error.Code)
+			assert.Equal(t, transactions.BatchLineCreated, respData.Lines[2].Status)
+		})
+
+		t.Run("should roll back an atomic csv batch if a line fails", func(t *testing.T) {
+			body := fmt.Sprintf("account_id,operation_type_id,amount\n%d,4,5.00\n987,4,5.00\n", batchAccountID)
+
+			resp, err := client.Post(
+				server.URL+"/transactions/batch?atomic=true",
+				transactions.ContentTypeCSV,
+				strings.NewReader(body),
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			respData := transactions.BatchAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&respData)
+			assert.NoError(t, err)
+
+			assert.True(t, respData.RolledBack)
+			assert.Equal(t, 0, respData.Created)
+			assert.Equal(t, transactions.BatchLineRolledBack, respData.Lines[0].Status)
+			assert.Equal(t, "account_id_not_found", respData.Lines[1].Error.Code)
+
+			resp, err = client.Get(fmt.Sprintf("%s/accounts/%d/transactions", server.URL, batchAccountID))
+			assert.NoError(t, err)
+
+			page := transactions.TransactionsPageAPIResponse{}
+			err = json.NewDecoder(resp.Body).Decode(&page)
+			assert.NoError(t, err)
+			assert.Len(t, page.Items, 2)
+		})
+
+		t.Run("should return error if the batch is invalid", func(t *testing.T) {
+			resp, err := client.Post(
+				server.URL+"/transactions/batch",
+				transactions.ContentTypeCSV,
+				strings.NewReader("account_id,amount\n1,10.00\n"),
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+			resp, err = client.Post(server.URL+"/transactions/batch", ContentTypeJSON, strings.NewReader("[]"))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+		})
+	})
 }
 
-func CreateAccount(server *httptest.Server, client *http.Client) (int64, error) {
+func CreateAccount(server *httptest.Server, client *http.Client, documentNumber string) (int64, error) {
 	jsonPayload, err := json.Marshal(map[string]any{
-		"document_number": "27935572003",
+		"document_number":        documentNumber,
+		"available_credit_limit": 1000,
 	})
 	if err != nil {
 		return 0, err
@@ -0,0 +1,270 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	"github.com/rudineirk/pismo-challenge/pkg/domains/accounts"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/fxrates"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/operationtypes"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/transactions"
+	"github.com/rudineirk/pismo-challenge/pkg/domains/webhooks"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/config"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/database"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/httprouter"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/logger"
+	"github.com/rudineirk/pismo-challenge/pkg/infra/outbox"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/money"
+	"github.com/rudineirk/pismo-challenge/pkg/utils/testutils"
+)
+
+const ContentTypeJSON = "application/json"
+
+type receiver struct {
+	mutex      sync.Mutex
+	statusCode int
+	requests   []*http.Request
+	bodies     [][]byte
+}
+
+func (recv *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	recv.mutex.Lock()
+	defer recv.mutex.Unlock()
+
+	recv.requests = append(recv.requests, r)
+	recv.bodies = append(recv.bodies, body)
+	w.WriteHeader(recv.statusCode)
+}
+
+func (recv *receiver) setStatusCode(statusCode int) {
+	recv.mutex.Lock()
+	defer recv.mutex.Unlock()
+
+	recv.statusCode = statusCode
+}
+
+func TestWebhooksAPIs(t *testing.T) {
+	err := testutils.SetRootCwd()
+	assert.NoError(t, err)
+
+	logger := logger.NewStubLogger()
+
+	cfg, err := config.LoadConfig()
+	assert.NoError(t, err)
+
+	cfg.IsProduction = true
+
+	testDB, err := testutils.NewTestDatabase(cfg.DatabaseURL)
+	assert.NoError(t, err)
+
+	defer testDB.Drop()
+
+	sqlDB, bunDB, err := database.NewDatabase(testDB.URL)
+	assert.NoError(t, err)
+
+	err = database.RunMigrations(sqlDB)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	router := httprouter.NewRouter(logger, cfg.IsProduction)
+
+	opTypesSvc := operationtypes.NewService(operationtypes.NewRepository(bunDB))
+	err = opTypesSvc.LoadOperationTypes(ctx)
+	assert.NoError(t, err)
+
+	outboxStore := outbox.NewStore(bunDB)
+	accountsSvc := accounts.NewService(
+		accounts.NewRepository(bunDB, testutils.NewTestKeyring()),
+		outboxStore,
+		money.New(cfg.AccountDefaultCreditLimit),
+	)
+	transactionsSvc := transactions.NewService(
+		transactions.NewRepository(bunDB),
+		accountsSvc,
+		opTypesSvc,
+		fxrates.NewService(fxrates.NewRepository(bunDB)),
+		outboxStore,
+	)
+
+	// no backoff, so the failed deliveries can be retried right away
+	webhooksSvc := webhooks.NewService(
+		webhooks.NewRepository(bunDB),
+		accountsSvc,
+		&http.Client{Timeout: time.Second},
+		webhooks.RetryPolicy{MaxAttempts: 2},
+	)
+	webhooks.SetupHTTPRoutes(router, webhooksSvc)
+
+	relay := outbox.NewRelay(outboxStore, webhooks.NewEventPublisher(webhooksSvc), logger, time.Second)
+
+	server, client := testutils.MakeTestHTTPServer(router)
+	defer server.Close()
+
+	recv := &receiver{statusCode: http.StatusOK}
+	receiverServer := httptest.NewServer(recv)
+	defer receiverServer.Close()
+
+	creditLimit := money.NewFromInt(1000)
+	account, err := accountsSvc.CreateAccount(ctx, &accounts.CreateAccountRequest{
+		DocumentNumber:       "66895932070",
+		AvailableCreditLimit: &creditLimit,
+	})
+	assert.NoError(t, err)
+
+	// publishes the account created event before the subscription
+	_, err = relay.PublishPending(ctx)
+	assert.NoError(t, err)
+
+	var subscription webhooks.SubscriptionAPIResponse
+
+	t.Run("POST /webhooks", func(t *testing.T) {
+		t.Run("should create a subscription", func(t *testing.T) {
+			jsonPayload, err := json.Marshal(map[string]any{
+				"url":         receiverServer.URL,
+				"event_types": []string{transactions.TransactionCreatedEvent},
+				"account_id":  account.ID,
+			})
+			assert.NoError(t, err)
+
+			resp, err := client.Post(server.URL+"/webhooks", ContentTypeJSON, bytes.NewBuffer(jsonPayload))
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+			err = json.NewDecoder(resp.Body).Decode(&subscription)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, subscription.Secret)
+			assert.Equal(t, []string{transactions.TransactionCreatedEvent}, subscription.EventTypes)
+		})
+
+		t.Run("should return error if the event type is invalid", func(t *testing.T) {
+			resp, err := client.Post(
+				server.URL+"/webhooks",
+				ContentTypeJSON,
+				bytes.NewBufferString(fmt.Sprintf(`{"url":%q,"event_types":["AccountClosed"]}`, receiverServer.URL)),
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	})
+
+	t.Run("GET /webhooks/{id}", func(t *testing.T) {
+		resp, err := client.Get(fmt.Sprintf("%s/webhooks/%d", server.URL, subscription.WebhookID))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		respData := webhooks.SubscriptionAPIResponse{}
+		err = json.NewDecoder(resp.Body).Decode(&respData)
+		assert.NoError(t, err)
+		assert.Empty(t, respData.Secret)
+	})
+
+	listDeliveries := func(t *testing.T) *webhooks.DeliveriesPageAPIResponse {
+		resp, err := client.Get(fmt.Sprintf("%s/webhooks/%d/deliveries", server.URL, subscription.WebhookID))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		page := &webhooks.DeliveriesPageAPIResponse{}
+		err = json.NewDecoder(resp.Body).Decode(page)
+		assert.NoError(t, err)
+
+		return page
+	}
+
+	t.Run("should deliver the signed transaction events", func(t *testing.T) {
+		transaction, err := transactionsSvc.CreateTransaction(ctx, &transactions.CreateTransactionRequest{
+			AccountID:       account.ID,
+			OperationTypeID: operationtypes.CashPurchaseType,
+			Amount:          money.MustParse("-10"),
+		})
+		assert.NoError(t, err)
+
+		_, err = relay.PublishPending(ctx)
+		assert.NoError(t, err)
+
+		attempted, err := webhooksSvc.DeliverPending(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, attempted)
+
+		assert.Len(t, recv.requests, 1)
+		req, body := recv.requests[0], recv.bodies[0]
+		assert.True(t, webhooks.VerifySignature(
+			subscription.Secret,
+			req.Header.Get(webhooks.TimestampHeader),
+			body,
+			req.Header.Get(webhooks.SignatureHeader),
+		))
+
+		event := outbox.Event{}
+		err = json.Unmarshal(body, &event)
+		assert.NoError(t, err)
+		assert.Equal(t, transactions.TransactionCreatedEvent, event.Type)
+		assert.Contains(t, string(event.Payload), fmt.Sprintf(`"transaction_id":%d`, transaction.ID))
+
+		page := listDeliveries(t)
+		assert.Len(t, page.Items, 1)
+		assert.Equal(t, webhooks.DeliveredStatus, page.Items[0].Status)
+		assert.Len(t, page.Items[0].AttemptLog, 1)
+
+		// an event published again, when the relay failed to mark it, doesn't duplicate the deliveries
+		err = webhooksSvc.EnqueueDeliveries(ctx, &event)
+		assert.NoError(t, err)
+		assert.Len(t, listDeliveries(t).Items, 1)
+	})
+
+	t.Run("should retry and move the failed deliveries to dead", func(t *testing.T) {
+		recv.setStatusCode(http.StatusInternalServerError)
+
+		_, err := transactionsSvc.CreateTransaction(ctx, &transactions.CreateTransactionRequest{
+			AccountID:       account.ID,
+			OperationTypeID: operationtypes.PaymentType,
+			Amount:          money.MustParse("5"),
+		})
+		assert.NoError(t, err)
+
+		_, err = relay.PublishPending(ctx)
+		assert.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			_, err = webhooksSvc.DeliverPending(ctx)
+			assert.NoError(t, err)
+		}
+
+		page := listDeliveries(t)
+		assert.Len(t, page.Items, 2)
+		assert.Equal(t, webhooks.DeadStatus, page.Items[0].Status)
+		assert.Equal(t, 2, page.Items[0].Attempts)
+		assert.Equal(t, http.StatusInternalServerError, *page.Items[0].LastStatusCode)
+	})
+
+	t.Run("POST /webhooks/{id}/deliveries/{delivery_id}/redeliver", func(t *testing.T) {
+		recv.setStatusCode(http.StatusOK)
+		deadDelivery := listDeliveries(t).Items[0]
+
+		resp, err := client.Post(
+			fmt.Sprintf("%s/webhooks/%d/deliveries/%d/redeliver", server.URL, subscription.WebhookID, deadDelivery.DeliveryID),
+			ContentTypeJSON,
+			nil,
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		_, err = webhooksSvc.DeliverPending(ctx)
+		assert.NoError(t, err)
+
+		page := listDeliveries(t)
+		assert.Equal(t, webhooks.DeliveredStatus, page.Items[0].Status)
+		assert.Len(t, page.Items[0].AttemptLog, 3)
+	})
+}